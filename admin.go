@@ -0,0 +1,404 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/validate"
+)
+
+// adminUsage lists the admin commands accepted by [runAdmin].
+const adminUsage = `  user create -username NAME -email EMAIL -password PASSWORD
+  user reset-password -password PASSWORD USERNAME
+  user disable USERNAME
+  article delete SLUG
+  tag merge FROM INTO
+  db backup PATH
+  db check
+  db recount
+  db restore PATH
+`
+
+// runAdmin runs an operator command directly against the SQLite database at path, without starting the HTTP server.
+// Commands go through the same [sqlite.Queries] and validation rules as the handlers.
+func runAdmin(ctx context.Context, w io.Writer, path string, args []string) error {
+	if len(args) < 2 {
+		return fmt.Errorf("missing admin command, available commands:\n%s", adminUsage)
+	}
+
+	cmd, args := args[0]+" "+args[1], args[2:]
+	if cmd == "db restore" {
+		// Restoring replaces the database file, so it must not be open
+		return adminDBRestore(ctx, w, path, cmd, args)
+	}
+
+	db, err := openDB(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck
+
+	switch cmd {
+	case "user create":
+		return adminUserCreate(ctx, w, db, cmd, args)
+	case "user reset-password":
+		return adminUserResetPassword(ctx, w, db, cmd, args)
+	case "user disable":
+		return adminUserDisable(ctx, w, db, cmd, args)
+	case "article delete":
+		return adminArticleDelete(ctx, w, db, cmd, args)
+	case "tag merge":
+		return adminTagMerge(ctx, w, db, cmd, args)
+	case "db backup":
+		return adminDBBackup(ctx, w, db, cmd, args)
+	case "db check":
+		return adminDBCheck(ctx, w, db, cmd, args)
+	case "db recount":
+		return adminDBRecount(ctx, w, db, cmd, args)
+	default:
+		return fmt.Errorf("unknown admin command %q, available commands:\n%s", cmd, adminUsage)
+	}
+}
+
+func adminUserCreate(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	var request userPostRequestBody
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.StringVar(&request.User.Username, "username", "", "username")
+	fs.StringVar(&request.User.Email, "email", "", "email")
+	fs.StringVar(&request.User.Password, "password", "", "password")
+	if err := parseAdminArgs(fs, args); err != nil {
+		return err
+	}
+	if errs := request.Validate(); len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := sqlite.New(tx)
+	if _, err := queries.GetUserByEmail(ctx, request.User.Email); err == nil {
+		return fmt.Errorf("user with email %s already exists", request.User.Email)
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	user, err := queries.CreateUser(ctx, sqlite.CreateUserParams{
+		Username: request.User.Username,
+		Email:    request.User.Email,
+		Password: request.User.Password,
+	})
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "created user %s with id %d\n", user.Username, user.ID)
+	return err
+}
+
+func adminUserResetPassword(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	var password string
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.StringVar(&password, "password", "", "new password")
+	if err := parseAdminArgs(fs, args, "USERNAME"); err != nil {
+		return err
+	}
+	if err := validate.Field("password", password, passwordRules...); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := sqlite.New(tx)
+	user, err := getUserByUsername(ctx, queries, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if _, err := queries.UpdateUser(ctx, sqlite.UpdateUserParams{
+		ID:       user.ID,
+		Password: sql.NullString{String: password, Valid: true},
+	}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "reset password of user %s\n", user.Username)
+	return err
+}
+
+func adminUserDisable(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	if err := parseAdminArgs(fs, args, "USERNAME"); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := sqlite.New(tx)
+	user, err := getUserByUsername(ctx, queries, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	if err := queries.DisableUser(ctx, user.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "disabled user %s\n", user.Username)
+	return err
+}
+
+func adminArticleDelete(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	if err := parseAdminArgs(fs, args, "SLUG"); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := sqlite.New(tx)
+	article, err := queries.GetArticleBySlug(ctx, fs.Arg(0))
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("article %q not found", fs.Arg(0))
+	}
+	if err != nil {
+		return err
+	}
+	if err := queries.DeleteArticle(ctx, article.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "deleted article %s\n", article.Slug)
+	return err
+}
+
+func adminTagMerge(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	if err := parseAdminArgs(fs, args, "FROM", "INTO"); err != nil {
+		return err
+	}
+	fromName, intoName := fs.Arg(0), fs.Arg(1)
+	if fromName == intoName {
+		return errors.New("cannot merge a tag into itself")
+	}
+	if err := validate.Field("tag", intoName, tagRules...); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	queries := sqlite.New(tx)
+	from, err := queries.GetTagByName(ctx, fromName)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("tag %q not found", fromName)
+	}
+	if err != nil {
+		return err
+	}
+	into, err := queries.GetOrCreateTag(ctx, intoName)
+	if err != nil {
+		return err
+	}
+	if err := queries.MoveArticleTags(ctx, sqlite.MoveArticleTagsParams{ToTagID: into.ID, FromTagID: from.ID}); err != nil {
+		return err
+	}
+	// Deleting the tag cascades to its article_tags rows, which were just copied to the target tag.
+	if err := queries.DeleteTag(ctx, from.ID); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "merged tag %s into %s\n", from.Name, into.Name)
+	return err
+}
+
+func adminDBBackup(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	if err := parseAdminArgs(fs, args, "PATH"); err != nil {
+		return err
+	}
+
+	// VACUUM INTO writes a consistent, compacted copy while other connections keep reading and writing.
+	// It fails if the target file already exists, so a backup never overwrites another.
+	if _, err := db.ExecContext(ctx, "VACUUM INTO ?", fs.Arg(0)); err != nil {
+		return fmt.Errorf("backup to %s: %w", fs.Arg(0), err)
+	}
+
+	_, err := fmt.Fprintf(w, "backed up database to %s\n", fs.Arg(0))
+	return err
+}
+
+func adminDBCheck(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	if err := parseAdminArgs(fs, args); err != nil {
+		return err
+	}
+
+	problems, err := queryStrings(ctx, db, "PRAGMA integrity_check")
+	if err != nil {
+		return err
+	}
+	if len(problems) == 1 && problems[0] == "ok" {
+		problems = nil
+	}
+
+	rows, err := db.QueryContext(ctx, "PRAGMA foreign_key_check")
+	if err != nil {
+		return err
+	}
+	defer rows.Close() //nolint:errcheck
+	for rows.Next() {
+		var table, parent string
+		var rowID sql.NullInt64
+		var fkID int64
+		if err := rows.Scan(&table, &rowID, &parent, &fkID); err != nil {
+			return err
+		}
+		problems = append(problems, fmt.Sprintf("%s row %d references missing %s row", table, rowID.Int64, parent))
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("database check failed:\n%s", strings.Join(problems, "\n"))
+	}
+	_, err = fmt.Fprintln(w, "ok")
+	return err
+}
+
+func adminDBRecount(ctx context.Context, w io.Writer, db *sql.DB, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	if err := parseAdminArgs(fs, args); err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	favorites, comments, followers, err := recount(ctx, sqlite.New(tx))
+	if err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "repaired %d favorites counts, %d comments counts and %d followers counts\n", favorites, comments, followers)
+	return err
+}
+
+// recount sets every counter column to the number of rows it counts, and returns how many of each were off.
+// Triggers keep the counters in step, so this is for repairs and for counters added to existing databases.
+func recount(ctx context.Context, queries *sqlite.Queries) (favorites, comments, followers int64, err error) {
+	if favorites, err = queries.RecountFavorites(ctx); err != nil {
+		return 0, 0, 0, err
+	}
+	if comments, err = queries.RecountComments(ctx); err != nil {
+		return 0, 0, 0, err
+	}
+	if followers, err = queries.RecountFollowers(ctx); err != nil {
+		return 0, 0, 0, err
+	}
+	return favorites, comments, followers, nil
+}
+
+func adminDBRestore(ctx context.Context, w io.Writer, path string, cmd string, args []string) error {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(w)
+	if err := parseAdminArgs(fs, args, "PATH"); err != nil {
+		return err
+	}
+
+	if err := restoreDB(ctx, path, fs.Arg(0)); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "restored database from %s\n", fs.Arg(0))
+	return err
+}
+
+// parseAdminArgs parses the flags of an admin command and requires exactly one positional argument per name.
+func parseAdminArgs(fs *flag.FlagSet, args []string, names ...string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != len(names) {
+		return fmt.Errorf("usage: %s [flags] %s", fs.Name(), strings.Join(names, " "))
+	}
+	return nil
+}
+
+func getUserByUsername(ctx context.Context, queries *sqlite.Queries, username string) (sqlite.User, error) {
+	user, err := queries.GetUserByUsername(ctx, username)
+	if errors.Is(err, sql.ErrNoRows) {
+		return sqlite.User{}, fmt.Errorf("user %q not found", username)
+	}
+	return user, err
+}
+
+// queryStrings returns the first column of every row of query.
+func queryStrings(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var values []string
+	for rows.Next() {
+		var value string
+		if err := rows.Scan(&value); err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+	}
+	return values, rows.Err()
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
+	"github.com/raeperd/realworld.go/internal/sqlite"
+)
+
+// admin runs an admin command against the database at dbPath and returns its output.
+func admin(t *testing.T, dbPath string, args ...string) (string, error) {
+	t.Helper()
+
+	var out bytes.Buffer
+	err := run(t.Context(), &out, append([]string{"test", "-db", dbPath}, args...), "vtest")
+	return out.String(), err
+}
+
+// serveDB starts a server on the database at dbPath, as the server binary would after admin commands ran.
+func serveDB(t *testing.T, dbPath string) *client.Client {
+	t.Helper()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", dbPath)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+
+	server := httptest.NewServer(route(slog.New(slog.NewTextHandler(io.Discard, nil)), "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret"}))
+	t.Cleanup(server.Close)
+	return client.New(server.URL)
+}
+
+func TestAdmin_User(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "admin.db")
+
+	out, err := admin(t, dbPath, "user", "create", "-username", "operator", "-email", "operator@example.com", "-password", "initialpass")
+	test.Nil(t, err)
+	test.Contains(t, out, "created user operator")
+
+	// Same validation and conflict rules as POST /api/users
+	_, err = admin(t, dbPath, "user", "create", "-username", "operator", "-email", "operator@example.com", "-password", "initialpass")
+	test.NotNil(t, err)
+	_, err = admin(t, dbPath, "user", "create", "-username", "x", "-email", "not-an-email", "-password", "short")
+	test.NotNil(t, err)
+
+	_, err = admin(t, dbPath, "user", "reset-password", "-password", "short", "operator")
+	test.NotNil(t, err)
+	_, err = admin(t, dbPath, "user", "reset-password", "-password", "resetpass", "nobody")
+	test.NotNil(t, err)
+	_, err = admin(t, dbPath, "user", "reset-password", "-password", "resetpass", "operator")
+	test.Nil(t, err)
+
+	c := serveDB(t, dbPath)
+	_, err = c.Login(t.Context(), "operator@example.com", "initialpass")
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+	_, err = c.Login(t.Context(), "operator@example.com", "resetpass")
+	test.Nil(t, err)
+
+	_, err = c.CreateArticle(t.Context(), client.NewArticle{Title: "Before", Description: "d", Body: "b"})
+	test.Nil(t, err)
+
+	_, err = admin(t, dbPath, "user", "disable", "operator")
+	test.Nil(t, err)
+	_, err = c.Login(t.Context(), "operator@example.com", "resetpass")
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+
+	// The token issued before is refused too, and only gets anonymous reads
+	_, err = c.CreateArticle(t.Context(), client.NewArticle{Title: "After", Description: "d", Body: "b"})
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+	_, err = c.GetCurrentUser(t.Context())
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+	article, err := c.GetArticle(t.Context(), "before")
+	test.Nil(t, err)
+	test.Equal(t, false, article.Favorited)
+}
+
+func TestAdmin_ArticleDeleteAndTagMerge(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "admin.db")
+	c := serveDB(t, dbPath)
+	_, err := c.Register(t.Context(), client.NewUser{Username: "writer", Email: "writer@example.com", Password: "writerpass"})
+	test.Nil(t, err)
+	for title, tags := range map[string][]string{"Go One": {"golang"}, "Go Two": {"go"}, "Go Both": {"golang", "go"}} {
+		_, err := c.CreateArticle(t.Context(), client.NewArticle{Title: title, Description: "d", Body: "b", TagList: tags})
+		test.Nil(t, err)
+	}
+
+	out, err := admin(t, dbPath, "tag", "merge", "golang", "go")
+	test.Nil(t, err)
+	test.Contains(t, out, "merged tag golang into go")
+	_, err = admin(t, dbPath, "tag", "merge", "golang", "go")
+	test.NotNil(t, err)
+
+	tags, err := c.GetTags(t.Context())
+	test.Nil(t, err)
+	test.DeepEqual(t, []string{"go"}, tags)
+	articles, err := c.GetArticles(t.Context(), client.ArticlesOptions{Tag: "go"})
+	test.Nil(t, err)
+	test.Equal(t, int64(3), articles.ArticlesCount)
+
+	_, err = admin(t, dbPath, "article", "delete", "go-one")
+	test.Nil(t, err)
+	_, err = admin(t, dbPath, "article", "delete", "go-one")
+	test.NotNil(t, err)
+	_, err = c.GetArticle(t.Context(), "go-one")
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
+}
+
+func TestAdmin_DB(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "admin.db")
+	_, err := admin(t, dbPath, "user", "create", "-username", "backup", "-email", "backup@example.com", "-password", "backuppass")
+	test.Nil(t, err)
+
+	out, err := admin(t, dbPath, "db", "check")
+	test.Nil(t, err)
+	test.Equal(t, "ok\n", out)
+
+	backupPath := filepath.Join(dir, "backup.db")
+	_, err = admin(t, dbPath, "db", "backup", backupPath)
+	test.Nil(t, err)
+	_, err = admin(t, dbPath, "db", "backup", backupPath) // never overwrites an existing backup
+	test.NotNil(t, err)
+
+	// The backup is a complete database on its own
+	db, err := openDB(t.Context(), backupPath)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	user, err := sqlite.New(db).GetUserByUsername(t.Context(), "backup")
+	test.Nil(t, err)
+	test.Equal(t, "backup@example.com", user.Email)
+
+	// A dangling reference, as left by writes with foreign keys off, fails the check
+	raw, err := openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	_, err = raw.ExecContext(t.Context(), "PRAGMA foreign_keys=OFF")
+	test.Nil(t, err)
+	_, err = raw.ExecContext(t.Context(), "INSERT INTO follows (follower_id, followed_id) VALUES (1, 999)")
+	test.Nil(t, err)
+	test.Nil(t, raw.Close())
+	_, err = admin(t, dbPath, "db", "check")
+	test.NotNil(t, err)
+}
+
+func TestAdmin_DBRecount(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "admin.db")
+	c := serveDB(t, dbPath)
+	_, err := c.Register(t.Context(), client.NewUser{Username: "counted", Email: "counted@example.com", Password: "countedpass"})
+	test.Nil(t, err)
+	_, err = c.Follow(t.Context(), "counted") // rejected, but must not count
+	test.NotNil(t, err)
+	article, err := c.CreateArticle(t.Context(), client.NewArticle{Title: "Counted", Description: "counters", Body: "body"})
+	test.Nil(t, err)
+	_, err = c.Favorite(t.Context(), article.Slug)
+	test.Nil(t, err)
+	_, err = c.AddComment(t.Context(), article.Slug, "first")
+	test.Nil(t, err)
+
+	out, err := admin(t, dbPath, "db", "recount")
+	test.Nil(t, err)
+	test.Equal(t, "repaired 0 favorites counts, 0 comments counts and 0 followers counts\n", out)
+
+	// Counters that drifted, as after writes with the triggers dropped, are set back from the rows
+	raw, err := openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	_, err = raw.ExecContext(t.Context(), "UPDATE articles SET favorites_count = 7, comments_count = 0")
+	test.Nil(t, err)
+	_, err = raw.ExecContext(t.Context(), "UPDATE users SET followers_count = 3")
+	test.Nil(t, err)
+	test.Nil(t, raw.Close())
+
+	out, err = admin(t, dbPath, "db", "recount")
+	test.Nil(t, err)
+	test.Equal(t, "repaired 1 favorites counts, 1 comments counts and 1 followers counts\n", out)
+	article, err = c.GetArticle(t.Context(), article.Slug)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), article.FavoritesCount)
+	test.Equal(t, int64(1), article.CommentsCount)
+	profile, err := c.GetProfile(t.Context(), "counted")
+	test.Nil(t, err)
+	test.Equal(t, int64(0), profile.FollowersCount)
+}
+
+func TestAdmin_Usage(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "admin.db")
+	testcases := map[string][]string{
+		"missing subcommand": {"user"},
+		"unknown command":    {"user", "promote", "operator"},
+		"missing argument":   {"user", "disable"},
+		"extra argument":     {"db", "check", "now"},
+		"merge into itself":  {"tag", "merge", "go", "go"},
+	}
+	for name, args := range testcases {
+		t.Run(name, func(t *testing.T) {
+			_, err := admin(t, dbPath, args...)
+			test.NotNil(t, err)
+		})
+	}
+
+	// Admin commands need a database file, an in-memory database would be gone on exit
+	err := run(t.Context(), io.Discard, []string{"test", "db", "check"}, "vtest")
+	test.NotNil(t, err)
+}
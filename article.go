@@ -10,10 +10,12 @@ import (
 	"regexp"
 	"strings"
 
-	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
+	"github.com/raeperd/realworld.go/internal/tracing"
+	"github.com/raeperd/realworld.go/internal/validate"
 )
 
-func handlePostArticles(db *sql.DB) http.HandlerFunc {
+func handlePostArticles(db store.Store, jobs *jobRunner, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var request articlePostRequestBody
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -37,17 +39,15 @@ func handlePostArticles(db *sql.DB) http.HandlerFunc {
 		// Generate slug from title
 		slug := generateSlug(request.Article.Title)
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-
 		// Create article
-		article, err := queries.CreateArticle(r.Context(), sqlite.CreateArticleParams{
+		article, err := tx.CreateArticle(r.Context(), store.CreateArticleParams{
 			Slug:        slug,
 			Title:       request.Article.Title,
 			Description: request.Article.Description,
@@ -63,14 +63,14 @@ func handlePostArticles(db *sql.DB) http.HandlerFunc {
 		if len(request.Article.TagList) > 0 {
 			for _, tagName := range request.Article.TagList {
 				// Get or create tag (upsert)
-				tag, err := queries.GetOrCreateTag(r.Context(), tagName)
+				tag, err := tx.GetOrCreateTag(r.Context(), tagName)
 				if err != nil {
 					encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 					return
 				}
 
 				// Associate tag with article
-				err = queries.AssociateArticleTag(r.Context(), sqlite.AssociateArticleTagParams{
+				err = tx.AssociateArticleTag(r.Context(), store.AssociateArticleTagParams{
 					ArticleID: article.ID,
 					TagID:     tag.ID,
 				})
@@ -82,48 +82,65 @@ func handlePostArticles(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get author details
-		author, err := queries.GetUserByID(r.Context(), userID)
+		author, err := tx.GetUserByID(r.Context(), userID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
 		// Get tags for response
-		tags, err := queries.GetArticleTagsByArticleID(r.Context(), article.ID)
+		tags, err := tx.GetArticleTagsByArticleID(r.Context(), article.ID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
+		// Add the article to the feeds of the author's followers once it is committed
+		if err := enqueueJob(r.Context(), tx, jobFeedFanOut, feedJob{ArticleID: article.ID}); err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
 		if err := tx.Commit(); err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		articlesCreated.Inc()
+		jobs.notify()
+
+		// The article is new to the cached lists it matches, and its tags may be new to the tag list
+		invalidate := articlesMatchingTags(author.Username, tags)
+		if len(tags) > 0 {
+			invalidate = append(invalidate, tagsTag)
+		}
+		responses.Invalidate(invalidate...)
 
 		// Ensure tags is never null in JSON response
 		if tags == nil {
 			tags = []string{}
 		}
 
-		encodeResponse(r.Context(), http.StatusCreated, articleResponseBody{
-			Article: articleResponse{
-				Slug:           article.Slug,
-				Title:          article.Title,
-				Description:    article.Description,
-				Body:           article.Body,
-				TagList:        tags,
-				CreatedAt:      article.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
-				UpdatedAt:      article.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
-				Favorited:      false,
-				FavoritesCount: 0,
-				Author: authorProfile{
-					Username:  author.Username,
-					Bio:       author.Bio.String,
-					Image:     author.Image.String,
-					Following: false, // Author viewing their own article
-				},
+		res := articleResponse{
+			Slug:           article.Slug,
+			Title:          article.Title,
+			Description:    article.Description,
+			Body:           article.Body,
+			TagList:        tags,
+			CreatedAt:      article.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+			UpdatedAt:      article.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
+			Favorited:      false,
+			FavoritesCount: 0,
+			CommentsCount:  0,
+			Author: authorProfile{
+				Username:  author.Username,
+				Bio:       author.Bio.String,
+				Image:     author.Image.String,
+				Following: false, // Author viewing their own article
 			},
-		}, w)
+		}
+		w.Header().Set("ETag", etag(res))
+		w.Header().Set(articleETagHeader, articleETag(article.ID, res))
+		encodeResponse(r.Context(), http.StatusCreated, articleResponseBody{Article: res}, w)
 	}
 }
 
@@ -160,19 +177,30 @@ type articlePostRequest struct {
 }
 
 func (r articlePostRequestBody) Validate() []error {
-	var errs []error
-	if r.Article.Title == "" {
-		errs = append(errs, errors.New("title is required"))
-	}
-	if r.Article.Description == "" {
-		errs = append(errs, errors.New("description is required"))
-	}
-	if r.Article.Body == "" {
-		errs = append(errs, errors.New("body is required"))
-	}
-	return errs
+	return validate.Errors(
+		validate.Field("title", r.Article.Title, titleRules...),
+		validate.Field("description", r.Article.Description, descriptionRules...),
+		validate.Field("body", r.Article.Body, articleBodyRules...),
+		validate.List("tagList", r.Article.TagList, maxTags, tagRules...),
+	)
 }
 
+// Limits for article fields, also documented in api/openapi.yaml.
+const (
+	maxTitleLength       = 200
+	maxDescriptionLength = 500
+	maxArticleBodyLength = 65536
+	maxTags              = 10
+	maxTagLength         = 32
+)
+
+var (
+	titleRules       = []validate.Rule{validate.Required, validate.MaxLength(maxTitleLength)}
+	descriptionRules = []validate.Rule{validate.Required, validate.MaxLength(maxDescriptionLength)}
+	articleBodyRules = []validate.Rule{validate.Required, validate.MaxLength(maxArticleBodyLength)}
+	tagRules         = []validate.Rule{validate.Required, validate.MaxLength(maxTagLength)}
+)
+
 type articleResponseBody struct {
 	Article articleResponse `json:"article"`
 }
@@ -187,6 +215,7 @@ type articleResponse struct {
 	UpdatedAt      string        `json:"updatedAt"`
 	Favorited      bool          `json:"favorited"`
 	FavoritesCount int64         `json:"favoritesCount"`
+	CommentsCount  int64         `json:"commentsCount"`
 	Author         authorProfile `json:"author"`
 }
 
@@ -197,14 +226,12 @@ type authorProfile struct {
 	Following bool   `json:"following"`
 }
 
-func handleGetArticlesSlug(db *sql.DB) http.HandlerFunc {
+func handleGetArticlesSlug(db store.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		slug := r.PathValue("slug")
 
-		queries := sqlite.New(db)
-
 		// Get article by slug
-		article, err := queries.GetArticleBySlug(r.Context(), slug)
+		article, err := db.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -214,79 +241,85 @@ func handleGetArticlesSlug(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		// Get tags for the article
-		tags, err := queries.GetArticleTagsByArticleID(r.Context(), article.ID)
+		userID, authenticated := r.Context().Value(userIDKey).(int64)
+		res, err := newArticleResponse(r.Context(), db, article, userID, authenticated)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
-		// Ensure tags is never null in JSON response
-		if tags == nil {
-			tags = []string{}
-		}
-
-		// Get favorites count
-		favoritesCount, err := queries.GetFavoritesCount(r.Context(), article.ID)
-		if err != nil {
-			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+		w.Header().Set(articleETagHeader, articleETag(article.ID, res))
+		modified := lastModified(article.UpdatedAt, article.TouchedAt.Time, article.AuthorUpdatedAt, article.AuthorTouchedAt.Time)
+		if notModified(w, r, etag(res), modified) {
 			return
 		}
+		encodeResponse(r.Context(), http.StatusOK, articleResponseBody{Article: res}, w)
+	}
+}
 
-		// Check if user is authenticated
-		userID, authenticated := r.Context().Value(userIDKey).(int64)
+// newArticleResponse returns article as the user with userID sees it, or as anyone sees it unless authenticated.
+func newArticleResponse(ctx context.Context, q store.Querier, article store.GetArticleBySlugRow, userID int64, authenticated bool) (articleResponse, error) {
+	tags, err := q.GetArticleTagsByArticleID(ctx, article.ID)
+	if err != nil {
+		return articleResponse{}, err
+	}
+	// Ensure tags is never null in JSON response
+	if tags == nil {
+		tags = []string{}
+	}
 
-		// Check favorited status if authenticated
-		favorited := false
-		if authenticated {
-			favoritedInt, err := queries.IsFavorited(r.Context(), sqlite.IsFavoritedParams{
-				UserID:    userID,
-				ArticleID: article.ID,
-			})
-			if err != nil {
-				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-				return
-			}
-			favorited = favoritedInt > 0
+	favorited, following := false, false
+	if authenticated {
+		favorited, err = q.IsFavorited(ctx, store.IsFavoritedParams{UserID: userID, ArticleID: article.ID})
+		if err != nil {
+			return articleResponse{}, err
 		}
-
-		// Check following status if authenticated
-		following := false
-		if authenticated {
-			followingInt, err := queries.IsFollowing(r.Context(), sqlite.IsFollowingParams{
-				FollowerID: userID,
-				FollowedID: article.AuthorID,
-			})
-			if err != nil {
-				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-				return
-			}
-			following = followingInt > 0
+		following, err = q.IsFollowing(ctx, store.IsFollowingParams{FollowerID: userID, FollowedID: article.AuthorID})
+		if err != nil {
+			return articleResponse{}, err
 		}
-
-		encodeResponse(r.Context(), http.StatusOK, articleResponseBody{
-			Article: articleResponse{
-				Slug:           article.Slug,
-				Title:          article.Title,
-				Description:    article.Description,
-				Body:           article.Body,
-				TagList:        tags,
-				CreatedAt:      article.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
-				UpdatedAt:      article.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
-				Favorited:      favorited,
-				FavoritesCount: favoritesCount,
-				Author: authorProfile{
-					Username:  article.AuthorUsername,
-					Bio:       article.AuthorBio.String,
-					Image:     article.AuthorImage.String,
-					Following: following,
-				},
-			},
-		}, w)
 	}
+
+	return articleResponse{
+		Slug:           article.Slug,
+		Title:          article.Title,
+		Description:    article.Description,
+		Body:           article.Body,
+		TagList:        tags,
+		CreatedAt:      article.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
+		UpdatedAt:      article.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
+		Favorited:      favorited,
+		FavoritesCount: article.FavoritesCount,
+		CommentsCount:  article.CommentsCount,
+		Author: authorProfile{
+			Username:  article.AuthorUsername,
+			Bio:       article.AuthorBio.String,
+			Image:     article.AuthorImage.String,
+			Following: following,
+		},
+	}, nil
 }
 
-func handlePutArticlesSlug(db *sql.DB) http.HandlerFunc {
+// articleETagHeader carries the [articleETag] of an article in its responses, for If-Match of PUT and DELETE.
+// The ETag is of the whole response and changes with every favorite and comment, so it can't guard edits.
+const articleETagHeader = "Article-ETag"
+
+// articleETag is the strong entity tag of the article with id, as res shows it, that If-Match of PUT and DELETE
+// compares with. It covers only the article's own state, not its counters, whether the viewer favorites it or its
+// author, so favorites and comments by others don't fail the author's next edit.
+func articleETag(id int64, res articleResponse) string {
+	return strongETag(struct {
+		ID          int64
+		Slug        string
+		Title       string
+		Description string
+		Body        string
+		TagList     []string
+		UpdatedAt   string
+	}{id, res.Slug, res.Title, res.Description, res.Body, res.TagList, res.UpdatedAt})
+}
+
+func handlePutArticlesSlug(db store.Store, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		slug := r.PathValue("slug")
 
@@ -297,6 +330,11 @@ func handlePutArticlesSlug(db *sql.DB) http.HandlerFunc {
 		}
 		defer func() { _ = r.Body.Close() }()
 
+		if errs := request.Validate(); len(errs) > 0 {
+			encodeErrorResponse(r.Context(), http.StatusUnprocessableEntity, errs, w)
+			return
+		}
+
 		// Get authenticated user ID from context
 		userID, ok := r.Context().Value(userIDKey).(int64)
 		if !ok {
@@ -304,17 +342,15 @@ func handlePutArticlesSlug(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-
 		// Get existing article by slug
-		existingArticle, err := queries.GetArticleBySlug(r.Context(), slug)
+		existingArticle, err := tx.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -330,8 +366,16 @@ func handlePutArticlesSlug(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		// Refuse the update if the client's copy of the article is out of date
+		if !ifMatch(w, r, "article", func() (string, error) {
+			current, err := newArticleResponse(r.Context(), tx, existingArticle, userID, true)
+			return articleETag(existingArticle.ID, current), err
+		}) {
+			return
+		}
+
 		// Prepare update parameters
-		updateParams := sqlite.UpdateArticleParams{
+		updateParams := store.UpdateArticleParams{
 			ID: existingArticle.ID,
 		}
 
@@ -351,28 +395,19 @@ func handlePutArticlesSlug(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Update article
-		article, err := queries.UpdateArticle(r.Context(), updateParams)
+		article, err := tx.UpdateArticle(r.Context(), updateParams)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
-		// Get author details
-		author, err := queries.GetUserByID(r.Context(), userID)
+		// Read the article back as GET returns it, so the ETags of the response are those GET would send
+		updated, err := tx.GetArticleBySlug(r.Context(), article.Slug)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
-
-		// Get tags for response
-		tags, err := queries.GetArticleTagsByArticleID(r.Context(), article.ID)
-		if err != nil {
-			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-			return
-		}
-
-		// Get favorites count
-		favoritesCount, err := queries.GetFavoritesCount(r.Context(), article.ID)
+		res, err := newArticleResponse(r.Context(), tx, updated, userID, true)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
@@ -382,31 +417,11 @@ func handlePutArticlesSlug(db *sql.DB) http.HandlerFunc {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		responses.Invalidate(articleTag(slug)) // the slug before any change of title
 
-		// Ensure tags is never null in JSON response
-		if tags == nil {
-			tags = []string{}
-		}
-
-		encodeResponse(r.Context(), http.StatusOK, articleResponseBody{
-			Article: articleResponse{
-				Slug:           article.Slug,
-				Title:          article.Title,
-				Description:    article.Description,
-				Body:           article.Body,
-				TagList:        tags,
-				CreatedAt:      article.CreatedAt.Format("2006-01-02T15:04:05.000Z"),
-				UpdatedAt:      article.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
-				Favorited:      false,
-				FavoritesCount: favoritesCount,
-				Author: authorProfile{
-					Username:  author.Username,
-					Bio:       author.Bio.String,
-					Image:     author.Image.String,
-					Following: false, // Author viewing their own article
-				},
-			},
-		}, w)
+		w.Header().Set("ETag", etag(res))
+		w.Header().Set(articleETagHeader, articleETag(updated.ID, res))
+		encodeResponse(r.Context(), http.StatusOK, articleResponseBody{Article: res}, w)
 	}
 }
 
@@ -420,7 +435,22 @@ type articlePutRequest struct {
 	Body        *string `json:"body,omitempty"`
 }
 
-func handleDeleteArticlesSlug(db *sql.DB) http.HandlerFunc {
+// Validate checks only the fields that are present, but a present field must not be empty.
+func (r articlePutRequestBody) Validate() []error {
+	var errs []error
+	if r.Article.Title != nil {
+		errs = append(errs, validate.Field("title", *r.Article.Title, titleRules...))
+	}
+	if r.Article.Description != nil {
+		errs = append(errs, validate.Field("description", *r.Article.Description, descriptionRules...))
+	}
+	if r.Article.Body != nil {
+		errs = append(errs, validate.Field("body", *r.Article.Body, articleBodyRules...))
+	}
+	return validate.Errors(errs...)
+}
+
+func handleDeleteArticlesSlug(db store.Store, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		slug := r.PathValue("slug")
 
@@ -431,10 +461,17 @@ func handleDeleteArticlesSlug(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		queries := sqlite.New(db)
+		// The check of If-Match and the delete run in one transaction, as for updates,
+		// so that no update lands in between
+		tx, err := db.BeginTx(r.Context())
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+		defer func() { _ = tx.Rollback() }()
 
 		// Get existing article by slug
-		article, err := queries.GetArticleBySlug(r.Context(), slug)
+		article, err := tx.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -450,73 +487,90 @@ func handleDeleteArticlesSlug(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		// Delete the article
-		err = queries.DeleteArticle(r.Context(), article.ID)
+		// Refuse the delete if the client's copy of the article is out of date
+		if !ifMatch(w, r, "article", func() (string, error) {
+			current, err := newArticleResponse(r.Context(), tx, article, userID, true)
+			return articleETag(article.ID, current), err
+		}) {
+			return
+		}
+
+		// Get tags for the cached lists the article matches
+		tags, err := tx.GetArticleTagsByArticleID(r.Context(), article.ID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
+		// Delete the article
+		if err := tx.DeleteArticle(r.Context(), article.ID); err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+		if err := tx.Commit(); err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
+		// The article leaves the lists it matches, and the lists of favorites it was in
+		responses.Invalidate(append(articlesMatchingTags(article.AuthorUsername, tags), articleTag(article.Slug), articlesFavoritedTag)...)
+
 		// Return 200 OK with no content
 		w.WriteHeader(http.StatusOK)
 	}
 }
 
-func handleGetArticles(db *sql.DB) http.HandlerFunc {
+func handleGetArticles(db store.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Parse query parameters
 		queryParams := r.URL.Query()
-		limit := int64(20) // default
-		offset := int64(0) // default
 		tag := queryParams.Get("tag")
 		author := queryParams.Get("author")
 		favorited := queryParams.Get("favorited")
 
-		if limitStr := queryParams.Get("limit"); limitStr != "" {
-			if parsedLimit, err := parseInt64(limitStr); err == nil && parsedLimit > 0 {
-				limit = parsedLimit
-			}
+		page, err := parseArticlesPage(queryParams)
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusUnprocessableEntity, []error{err}, w)
+			return
 		}
 
-		if offsetStr := queryParams.Get("offset"); offsetStr != "" {
-			if parsedOffset, err := parseInt64(offsetStr); err == nil && parsedOffset >= 0 {
-				offset = parsedOffset
-			}
+		// Empty filters are passed as NULL, which the queries skip
+		filter := store.CountArticlesParams{
+			Tag:       sql.NullString{String: tag, Valid: tag != ""},
+			Author:    sql.NullString{String: author, Valid: author != ""},
+			Favorited: sql.NullString{String: favorited, Valid: favorited != ""},
+		}
+		articles, err := db.ListArticles(r.Context(), store.ListArticlesParams{
+			Tag:             filter.Tag,
+			Author:          filter.Author,
+			Favorited:       filter.Favorited,
+			BeforeCreatedAt: page.beforeCreatedAt(),
+			BeforeID:        page.beforeID(),
+			Limit:           page.limit + 1, // one more to tell whether there is a next page
+			Offset:          page.offset,
+		})
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
 		}
+		articles, nextCursor := next(page, articles, func(a store.ListArticlesRow) articlesCursor {
+			return articlesCursor{createdAt: a.CreatedAt, id: a.ID}
+		})
 
-		queries := sqlite.New(db)
-
-		// Build WHERE clause for filtering
-		var articles []sqlite.ListArticlesRow
-		var totalCount int64
-		var err error
-
-		// If filters are provided, use raw SQL (sqlc doesn't handle dynamic WHERE well)
-		if tag != "" || author != "" || favorited != "" {
-			articles, totalCount, err = listArticlesWithFilters(r.Context(), db, tag, author, favorited, limit, offset)
-			if err != nil {
-				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-				return
-			}
-		} else {
-			// Use sqlc-generated query for simple case
-			articles, err = queries.ListArticles(r.Context(), sqlite.ListArticlesParams{
-				Limit:  limit,
-				Offset: offset,
-			})
-			if err != nil {
-				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-				return
-			}
-
-			// Get total count
-			totalCount, err = queries.CountArticles(r.Context())
+		var totalCount *int64
+		if page.count {
+			count, err := db.CountArticles(r.Context(), filter)
 			if err != nil {
 				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 				return
 			}
+			totalCount = &count
 		}
 
+		// Favorites, follows and tags of the page are traced together, apart from the listing and the encoding
+		ctx, span := tracing.Start(r.Context(), "enrich articles", tracing.Int("articles", len(articles)))
+		defer span.End()
+
 		// Check if user is authenticated
 		userID, authenticated := r.Context().Value(userIDKey).(int64)
 
@@ -526,23 +580,10 @@ func handleGetArticles(db *sql.DB) http.HandlerFunc {
 			articleIDs[i] = articles[i].ID
 		}
 
-		// Get favorites counts for all articles
-		favoritesMap := make(map[int64]int64)
-		if len(articleIDs) > 0 {
-			favoritesCounts, err := queries.GetFavoritesByArticleIDs(r.Context(), articleIDs)
-			if err != nil {
-				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-				return
-			}
-			for _, fc := range favoritesCounts {
-				favoritesMap[fc.ArticleID] = fc.Count
-			}
-		}
-
 		// Get favorited status if authenticated
 		favoritedMap := make(map[int64]bool)
 		if authenticated && len(articleIDs) > 0 {
-			favoritedArticles, err := queries.CheckFavoritedByUser(r.Context(), sqlite.CheckFavoritedByUserParams{
+			favoritedArticles, err := db.CheckFavoritedByUser(ctx, store.CheckFavoritedByUserParams{
 				UserID:     userID,
 				ArticleIds: articleIDs,
 			})
@@ -564,7 +605,7 @@ func handleGetArticles(db *sql.DB) http.HandlerFunc {
 		// Get following status if authenticated
 		followingMap := make(map[int64]bool)
 		if authenticated && len(authorIDs) > 0 {
-			followedAuthors, err := queries.GetFollowingByIDs(r.Context(), sqlite.GetFollowingByIDsParams{
+			followedAuthors, err := db.GetFollowingByIDs(ctx, store.GetFollowingByIDsParams{
 				FollowerID:  userID,
 				FollowedIds: authorIDs,
 			})
@@ -580,7 +621,7 @@ func handleGetArticles(db *sql.DB) http.HandlerFunc {
 		// Batch fetch tags for all articles
 		tagsMap := make(map[int64][]string)
 		if len(articleIDs) > 0 {
-			articleTags, err := queries.GetArticleTagsByArticleIDs(r.Context(), articleIDs)
+			articleTags, err := db.GetArticleTagsByArticleIDs(ctx, articleIDs)
 			if err != nil {
 				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 				return
@@ -590,6 +631,8 @@ func handleGetArticles(db *sql.DB) http.HandlerFunc {
 			}
 		}
 
+		span.End()
+
 		// Build response
 		responseArticles := make([]articleListResponse, len(articles))
 		for i := range articles {
@@ -609,7 +652,8 @@ func handleGetArticles(db *sql.DB) http.HandlerFunc {
 				CreatedAt:      articles[i].CreatedAt.Format("2006-01-02T15:04:05.000Z"),
 				UpdatedAt:      articles[i].UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
 				Favorited:      favoritedMap[articles[i].ID],
-				FavoritesCount: favoritesMap[articles[i].ID],
+				FavoritesCount: articles[i].FavoritesCount,
+				CommentsCount:  articles[i].CommentsCount,
 				Author: authorProfile{
 					Username:  articles[i].AuthorUsername,
 					Bio:       articles[i].AuthorBio.String,
@@ -622,124 +666,18 @@ func handleGetArticles(db *sql.DB) http.HandlerFunc {
 		encodeResponse(r.Context(), http.StatusOK, articlesResponseBody{
 			Articles:      responseArticles,
 			ArticlesCount: totalCount,
+			NextCursor:    nextCursor,
 		}, w)
 	}
 }
 
-func listArticlesWithFilters(ctx context.Context, db *sql.DB, tag, author, favorited string, limit, offset int64) ([]sqlite.ListArticlesRow, int64, error) {
-	// Build base query
-	query := `
-		SELECT DISTINCT
-			a.id,
-			a.slug,
-			a.title,
-			a.description,
-			a.created_at,
-			a.updated_at,
-			a.author_id,
-			u.username as author_username,
-			u.bio as author_bio,
-			u.image as author_image
-		FROM articles a
-		JOIN users u ON a.author_id = u.id`
-
-	countQuery := `
-		SELECT COUNT(DISTINCT a.id)
-		FROM articles a
-		JOIN users u ON a.author_id = u.id`
-
-	// Build WHERE clauses
-	var whereClauses []string
-	var args []any
-
-	if tag != "" {
-		query += `
-		JOIN article_tags at ON a.id = at.article_id
-		JOIN tags t ON at.tag_id = t.id`
-		countQuery += `
-		JOIN article_tags at ON a.id = at.article_id
-		JOIN tags t ON at.tag_id = t.id`
-		whereClauses = append(whereClauses, "t.name = ?")
-		args = append(args, tag)
-	}
-
-	if author != "" {
-		whereClauses = append(whereClauses, "u.username = ?")
-		args = append(args, author)
-	}
-
-	if favorited != "" {
-		query += `
-		JOIN favorites f ON a.id = f.article_id
-		JOIN users fu ON f.user_id = fu.id`
-		countQuery += `
-		JOIN favorites f ON a.id = f.article_id
-		JOIN users fu ON f.user_id = fu.id`
-		whereClauses = append(whereClauses, "fu.username = ?")
-		args = append(args, favorited)
-	}
-
-	if len(whereClauses) > 0 {
-		whereClause := " WHERE " + strings.Join(whereClauses, " AND ")
-		query += whereClause
-		countQuery += whereClause
-	}
-
-	query += `
-		ORDER BY a.created_at DESC
-		LIMIT ? OFFSET ?`
-	args = append(args, limit, offset)
-
-	// Get total count first
-	var totalCount int64
-	countArgs := args[:len(args)-2] // Remove limit and offset for count
-	err := db.QueryRowContext(ctx, countQuery, countArgs...).Scan(&totalCount)
-	if err != nil {
-		return nil, 0, err
-	}
-
-	// Query articles
-	rows, err := db.QueryContext(ctx, query, args...)
-	if err != nil {
-		return nil, 0, err
-	}
-	defer func() { _ = rows.Close() }()
-
-	var articles []sqlite.ListArticlesRow
-	for rows.Next() {
-		var article sqlite.ListArticlesRow
-		err := rows.Scan(
-			&article.ID,
-			&article.Slug,
-			&article.Title,
-			&article.Description,
-			&article.CreatedAt,
-			&article.UpdatedAt,
-			&article.AuthorID,
-			&article.AuthorUsername,
-			&article.AuthorBio,
-			&article.AuthorImage,
-		)
-		if err != nil {
-			return nil, 0, err
-		}
-		articles = append(articles, article)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, 0, err
-	}
-
-	return articles, totalCount, nil
-}
-
 func parseInt64(s string) (int64, error) {
 	var result int64
 	_, err := fmt.Sscanf(s, "%d", &result)
 	return result, err
 }
 
-func handleGetArticlesFeed(db *sql.DB) http.HandlerFunc {
+func handleGetArticlesFeed(db store.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get authenticated user ID from context (required for feed)
 		userID, ok := r.Context().Value(userIDKey).(int64)
@@ -748,41 +686,37 @@ func handleGetArticlesFeed(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		// Parse query parameters
-		queryParams := r.URL.Query()
-		limit := int64(20) // default
-		offset := int64(0) // default
-
-		if limitStr := queryParams.Get("limit"); limitStr != "" {
-			if parsedLimit, err := parseInt64(limitStr); err == nil && parsedLimit > 0 {
-				limit = parsedLimit
-			}
-		}
-
-		if offsetStr := queryParams.Get("offset"); offsetStr != "" {
-			if parsedOffset, err := parseInt64(offsetStr); err == nil && parsedOffset >= 0 {
-				offset = parsedOffset
-			}
+		page, err := parseArticlesPage(r.URL.Query())
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusUnprocessableEntity, []error{err}, w)
+			return
 		}
 
-		queries := sqlite.New(db)
-
-		// Get articles from followed users
-		articles, err := queries.ListArticlesFeed(r.Context(), sqlite.ListArticlesFeedParams{
-			FollowerID: userID,
-			Limit:      limit,
-			Offset:     offset,
+		// Get articles from followed users, fanned out into the user's feed items when they were published
+		articles, err := db.ListFeedItems(r.Context(), store.ListFeedItemsParams{
+			UserID:          userID,
+			BeforeCreatedAt: page.beforeCreatedAt(),
+			BeforeID:        page.beforeID(),
+			Limit:           page.limit + 1, // one more to tell whether there is a next page
+			Offset:          page.offset,
 		})
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		articles, nextCursor := next(page, articles, func(a store.ListFeedItemsRow) articlesCursor {
+			return articlesCursor{createdAt: a.CreatedAt, id: a.ID}
+		})
 
 		// Get total count of articles in feed
-		totalCount, err := queries.CountArticlesFeed(r.Context(), userID)
-		if err != nil {
-			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-			return
+		var totalCount *int64
+		if page.count {
+			count, err := db.CountFeedItems(r.Context(), userID)
+			if err != nil {
+				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+				return
+			}
+			totalCount = &count
 		}
 
 		// Build article IDs for batch queries
@@ -791,23 +725,10 @@ func handleGetArticlesFeed(db *sql.DB) http.HandlerFunc {
 			articleIDs[i] = articles[i].ID
 		}
 
-		// Get favorites counts for all articles
-		favoritesMap := make(map[int64]int64)
-		if len(articleIDs) > 0 {
-			favoritesCounts, err := queries.GetFavoritesByArticleIDs(r.Context(), articleIDs)
-			if err != nil {
-				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
-				return
-			}
-			for _, fc := range favoritesCounts {
-				favoritesMap[fc.ArticleID] = fc.Count
-			}
-		}
-
 		// Get favorited status
 		favoritedMap := make(map[int64]bool)
 		if len(articleIDs) > 0 {
-			favoritedArticles, err := queries.CheckFavoritedByUser(r.Context(), sqlite.CheckFavoritedByUserParams{
+			favoritedArticles, err := db.CheckFavoritedByUser(r.Context(), store.CheckFavoritedByUserParams{
 				UserID:     userID,
 				ArticleIds: articleIDs,
 			})
@@ -823,7 +744,7 @@ func handleGetArticlesFeed(db *sql.DB) http.HandlerFunc {
 		// Batch fetch tags for all articles
 		tagsMap := make(map[int64][]string)
 		if len(articleIDs) > 0 {
-			articleTags, err := queries.GetArticleTagsByArticleIDs(r.Context(), articleIDs)
+			articleTags, err := db.GetArticleTagsByArticleIDs(r.Context(), articleIDs)
 			if err != nil {
 				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 				return
@@ -852,7 +773,8 @@ func handleGetArticlesFeed(db *sql.DB) http.HandlerFunc {
 				CreatedAt:      articles[i].CreatedAt.Format("2006-01-02T15:04:05.000Z"),
 				UpdatedAt:      articles[i].UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
 				Favorited:      favoritedMap[articles[i].ID],
-				FavoritesCount: favoritesMap[articles[i].ID],
+				FavoritesCount: articles[i].FavoritesCount,
+				CommentsCount:  articles[i].CommentsCount,
 				Author: authorProfile{
 					Username:  articles[i].AuthorUsername,
 					Bio:       articles[i].AuthorBio.String,
@@ -865,13 +787,17 @@ func handleGetArticlesFeed(db *sql.DB) http.HandlerFunc {
 		encodeResponse(r.Context(), http.StatusOK, articlesResponseBody{
 			Articles:      responseArticles,
 			ArticlesCount: totalCount,
+			NextCursor:    nextCursor,
 		}, w)
 	}
 }
 
+// articlesResponseBody is a page of articles.
+// ArticlesCount is left out with count=false, and NextCursor on the last page.
 type articlesResponseBody struct {
 	Articles      []articleListResponse `json:"articles"`
-	ArticlesCount int64                 `json:"articlesCount"`
+	ArticlesCount *int64                `json:"articlesCount,omitempty"`
+	NextCursor    string                `json:"nextCursor,omitempty"`
 }
 
 type articleListResponse struct {
@@ -883,11 +809,12 @@ type articleListResponse struct {
 	UpdatedAt      string        `json:"updatedAt"`
 	Favorited      bool          `json:"favorited"`
 	FavoritesCount int64         `json:"favoritesCount"`
+	CommentsCount  int64         `json:"commentsCount"`
 	Author         authorProfile `json:"author"`
 }
 
 //nolint:dupl // Favorite and unfavorite handlers have intentional structural similarity
-func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
+func handlePostArticlesSlugFavorite(db store.Store, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		slug := r.PathValue("slug")
 
@@ -898,17 +825,15 @@ func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-
 		// Get article by slug to verify it exists
-		article, err := queries.GetArticleBySlug(r.Context(), slug)
+		article, err := tx.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -919,7 +844,7 @@ func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Create favorite (idempotent - ON CONFLICT DO NOTHING)
-		err = queries.CreateFavorite(r.Context(), sqlite.CreateFavoriteParams{
+		err = tx.CreateFavorite(r.Context(), store.CreateFavoriteParams{
 			UserID:    userID,
 			ArticleID: article.ID,
 		})
@@ -929,7 +854,7 @@ func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get tags for the article
-		tags, err := queries.GetArticleTagsByArticleID(r.Context(), article.ID)
+		tags, err := tx.GetArticleTagsByArticleID(r.Context(), article.ID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
@@ -941,14 +866,14 @@ func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get updated favorites count
-		favoritesCount, err := queries.GetFavoritesCount(r.Context(), article.ID)
+		favoritesCount, err := tx.GetFavoritesCount(r.Context(), article.ID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
 		// Check following status
-		followingInt, err := queries.IsFollowing(r.Context(), sqlite.IsFollowingParams{
+		following, err := tx.IsFollowing(r.Context(), store.IsFollowingParams{
 			FollowerID: userID,
 			FollowedID: article.AuthorID,
 		})
@@ -956,12 +881,19 @@ func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
-		following := followingInt > 0
+
+		// Get the user for the cached list of their favorites
+		user, err := tx.GetUserByID(r.Context(), userID)
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
 
 		if err := tx.Commit(); err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		responses.Invalidate(articleTag(article.Slug), articlesFavoritedByTag(user.Username))
 
 		encodeResponse(r.Context(), http.StatusOK, articleResponseBody{
 			Article: articleResponse{
@@ -974,6 +906,7 @@ func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 				UpdatedAt:      article.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
 				Favorited:      true, // Always true since we just favorited it
 				FavoritesCount: favoritesCount,
+				CommentsCount:  article.CommentsCount,
 				Author: authorProfile{
 					Username:  article.AuthorUsername,
 					Bio:       article.AuthorBio.String,
@@ -986,7 +919,7 @@ func handlePostArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 }
 
 //nolint:dupl // Favorite and unfavorite handlers have intentional structural similarity
-func handleDeleteArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
+func handleDeleteArticlesSlugFavorite(db store.Store, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		slug := r.PathValue("slug")
 
@@ -997,17 +930,15 @@ func handleDeleteArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-
 		// Get article by slug to verify it exists
-		article, err := queries.GetArticleBySlug(r.Context(), slug)
+		article, err := tx.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -1018,7 +949,7 @@ func handleDeleteArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Delete favorite (idempotent - no error if not favorited)
-		err = queries.DeleteFavorite(r.Context(), sqlite.DeleteFavoriteParams{
+		err = tx.DeleteFavorite(r.Context(), store.DeleteFavoriteParams{
 			UserID:    userID,
 			ArticleID: article.ID,
 		})
@@ -1028,7 +959,7 @@ func handleDeleteArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get tags for the article
-		tags, err := queries.GetArticleTagsByArticleID(r.Context(), article.ID)
+		tags, err := tx.GetArticleTagsByArticleID(r.Context(), article.ID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
@@ -1040,14 +971,14 @@ func handleDeleteArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get updated favorites count
-		favoritesCount, err := queries.GetFavoritesCount(r.Context(), article.ID)
+		favoritesCount, err := tx.GetFavoritesCount(r.Context(), article.ID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
 		// Check following status
-		followingInt, err := queries.IsFollowing(r.Context(), sqlite.IsFollowingParams{
+		following, err := tx.IsFollowing(r.Context(), store.IsFollowingParams{
 			FollowerID: userID,
 			FollowedID: article.AuthorID,
 		})
@@ -1055,12 +986,19 @@ func handleDeleteArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
-		following := followingInt > 0
+
+		// Get the user for the cached list of their favorites
+		user, err := tx.GetUserByID(r.Context(), userID)
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
 
 		if err := tx.Commit(); err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		responses.Invalidate(articleTag(article.Slug), articlesFavoritedByTag(user.Username))
 
 		encodeResponse(r.Context(), http.StatusOK, articleResponseBody{
 			Article: articleResponse{
@@ -1073,6 +1011,7 @@ func handleDeleteArticlesSlugFavorite(db *sql.DB) http.HandlerFunc {
 				UpdatedAt:      article.UpdatedAt.Format("2006-01-02T15:04:05.000Z"),
 				Favorited:      false, // Always false since we just unfavorited it
 				FavoritesCount: favoritesCount,
+				CommentsCount:  article.CommentsCount,
 				Author: authorProfile{
 					Username:  article.AuthorUsername,
 					Bio:       article.AuthorBio.String,
@@ -1,150 +1,102 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
 )
 
 func TestPostArticles_Success(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user and get token
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "article_author_" + unique
-	email := fmt.Sprintf("article_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, user := registerUser(t, "article_author")
 
 	// Test: POST /api/articles with authentication
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "How to train your dragon",
-			Description: "Ever wonder how?",
-			Body:        "You have to believe",
-			TagList:     []string{"dragons", "training"},
-		},
-	}
-
-	res := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	article, err := c.CreateArticle(t.Context(), client.NewArticle{
+		Title:       "How to train your dragon",
+		Description: "Ever wonder how?",
+		Body:        "You have to believe",
+		TagList:     []string{"dragons", "training"},
+	})
+	test.Nil(t, err)
 
 	// Verify response structure and content
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.Equal(t, "how-to-train-your-dragon", response.Article.Slug)
-	test.Equal(t, "How to train your dragon", response.Article.Title)
-	test.Equal(t, "Ever wonder how?", response.Article.Description)
-	test.Equal(t, "You have to believe", response.Article.Body)
-	test.Equal(t, 2, len(response.Article.TagList))
-	test.Equal(t, false, response.Article.Favorited)
-	test.Equal(t, int64(0), response.Article.FavoritesCount)
-	test.Equal(t, username, response.Article.Author.Username)
-	test.Equal(t, false, response.Article.Author.Following)
-	test.NotNil(t, response.Article.CreatedAt)
-	test.NotNil(t, response.Article.UpdatedAt)
+	test.Equal(t, "how-to-train-your-dragon", article.Slug)
+	test.Equal(t, "How to train your dragon", article.Title)
+	test.Equal(t, "Ever wonder how?", article.Description)
+	test.Equal(t, "You have to believe", article.Body)
+	test.Equal(t, 2, len(article.TagList))
+	test.Equal(t, false, article.Favorited)
+	test.Equal(t, int64(0), article.FavoritesCount)
+	test.Equal(t, user.Username, article.Author.Username)
+	test.Equal(t, false, article.Author.Following)
+	test.NotZero(t, article.CreatedAt)
+	test.NotZero(t, article.UpdatedAt)
 }
 
 func TestPostArticles_WithoutTags(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user and get token
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "article_author_no_tags_" + unique
-	email := fmt.Sprintf("article_no_tags_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "article_author_no_tags")
 
 	// Test: Create article without tags
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Simple article without tags " + unique,
-			Description: "Description here",
-			Body:        "Body content",
-			TagList:     []string{},
-		},
-	}
-
-	res := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.Equal(t, 0, len(response.Article.TagList))
+	article, err := c.CreateArticle(t.Context(), client.NewArticle{
+		Title:       fmt.Sprintf("Simple article without tags %d", time.Now().UnixNano()),
+		Description: "Description here",
+		Body:        "Body content",
+		TagList:     []string{},
+	})
+	test.Nil(t, err)
+	test.Equal(t, 0, len(article.TagList))
 }
 
 func TestPostArticles_Validation(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user and get token
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "validation_user_" + unique
-	email := fmt.Sprintf("validation_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "validation_user")
 
-	testcases := map[string]ArticlePostRequestBody{
+	testcases := map[string]client.NewArticle{
 		"title required": {
-			Article: ArticlePostRequest{
-				Title:       "",
-				Description: "Description",
-				Body:        "Body content",
-			},
+			Title:       "",
+			Description: "Description",
+			Body:        "Body content",
 		},
 		"description required": {
-			Article: ArticlePostRequest{
-				Title:       "Title",
-				Description: "",
-				Body:        "Body content",
-			},
+			Title:       "Title",
+			Description: "",
+			Body:        "Body content",
 		},
 		"body required": {
-			Article: ArticlePostRequest{
-				Title:       "Title",
-				Description: "Description",
-				Body:        "",
-			},
+			Title:       "Title",
+			Description: "Description",
+			Body:        "",
+		},
+		"title too long": {
+			Title:       strings.Repeat("t", 201),
+			Description: "Description",
+			Body:        "Body content",
+		},
+		"too many tags": {
+			Title:       "Title",
+			Description: "Description",
+			Body:        "Body content",
+			TagList:     []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"},
+		},
+		"empty tag": {
+			Title:       "Title",
+			Description: "Description",
+			Body:        "Body content",
+			TagList:     []string{"go", ""},
 		},
 	}
 
@@ -152,9 +104,8 @@ func TestPostArticles_Validation(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			res := httpPostArticles(t, tc, token)
-			test.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
-			t.Cleanup(func() { _ = res.Body.Close() })
+			_, err := c.CreateArticle(t.Context(), tc)
+			test.Equal(t, http.StatusUnprocessableEntity, client.StatusCode(err))
 		})
 	}
 }
@@ -162,18 +113,27 @@ func TestPostArticles_Validation(t *testing.T) {
 func TestPostArticles_Unauthorized(t *testing.T) {
 	t.Parallel()
 
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Test Article",
-			Description: "Description",
-			Body:        "Body content",
-		},
-	}
-
 	// Test without token
-	res := httpPostArticles(t, articleReq, "")
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient().CreateArticle(t.Context(), client.NewArticle{
+		Title:       "Test Article",
+		Description: "Description",
+		Body:        "Body content",
+	})
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+}
+
+// createArticle creates an article titled title with c and returns it.
+func createArticle(t *testing.T, c *client.Client, title string, tags ...string) client.Article {
+	t.Helper()
+
+	article, err := c.CreateArticle(t.Context(), client.NewArticle{
+		Title:       title,
+		Description: "Test description",
+		Body:        "Test body content",
+		TagList:     tags,
+	})
+	test.Nil(t, err)
+	return article
 }
 
 func TestGetArticlesSlug_Success(t *testing.T) {
@@ -181,68 +141,33 @@ func TestGetArticlesSlug_Success(t *testing.T) {
 
 	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "article_reader_" + unique
-	email := fmt.Sprintf("reader_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Test Article " + unique,
-			Description: "Test description",
-			Body:        "Test body content",
-			TagList:     []string{"test", "golang"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	c, user := registerUser(t, "article_reader")
+	slug := createArticle(t, c, "Test Article "+unique, "test", "golang").Slug
 
 	// Test: GET /api/articles/:slug without authentication
-	res := httpGetArticlesSlug(t, slug, "")
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	article, err := newClient().GetArticle(t.Context(), slug)
+	test.Nil(t, err)
 
 	// Verify response
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.Equal(t, slug, response.Article.Slug)
-	test.Equal(t, "Test Article "+unique, response.Article.Title)
-	test.Equal(t, "Test description", response.Article.Description)
-	test.Equal(t, "Test body content", response.Article.Body)
-	test.Equal(t, 2, len(response.Article.TagList))
-	test.Equal(t, false, response.Article.Favorited)
-	test.Equal(t, int64(0), response.Article.FavoritesCount)
-	test.Equal(t, username, response.Article.Author.Username)
-	test.Equal(t, false, response.Article.Author.Following)
-	test.NotNil(t, response.Article.CreatedAt)
-	test.NotNil(t, response.Article.UpdatedAt)
+	test.Equal(t, slug, article.Slug)
+	test.Equal(t, "Test Article "+unique, article.Title)
+	test.Equal(t, "Test description", article.Description)
+	test.Equal(t, "Test body content", article.Body)
+	test.Equal(t, 2, len(article.TagList))
+	test.Equal(t, false, article.Favorited)
+	test.Equal(t, int64(0), article.FavoritesCount)
+	test.Equal(t, user.Username, article.Author.Username)
+	test.Equal(t, false, article.Author.Following)
+	test.NotZero(t, article.CreatedAt)
+	test.NotZero(t, article.UpdatedAt)
 }
 
 func TestGetArticlesSlug_NotFound(t *testing.T) {
 	t.Parallel()
 
 	// Test: GET /api/articles/:slug with non-existent slug
-	res := httpGetArticlesSlug(t, "nonexistent-article-slug", "")
-	test.Equal(t, http.StatusNotFound, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient().GetArticle(t.Context(), "nonexistent-article-slug")
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
 }
 
 func TestGetArticlesSlug_Authenticated(t *testing.T) {
@@ -250,139 +175,24 @@ func TestGetArticlesSlug_Authenticated(t *testing.T) {
 
 	// Setup: Create two users - one author and one reader
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	authorUsername := "author_" + unique
-	authorEmail := fmt.Sprintf("author_%s@example.com", unique)
-
-	authorReq := UserPostRequestBody{
-		Username: authorUsername,
-		Email:    authorEmail,
-		Password: "testpass123",
-	}
-	authorRes := httpPostUsers(t, authorReq)
-	test.Equal(t, http.StatusCreated, authorRes.StatusCode)
-	t.Cleanup(func() { _ = authorRes.Body.Close() })
-
-	var authorResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(authorRes.Body).Decode(&authorResponse))
-	authorToken := authorResponse.Token
+	author, authorUser := registerUser(t, "author")
 
 	// Create an article as the author
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Authenticated Test Article " + unique,
-			Description: "Test description",
-			Body:        "Test body content",
-			TagList:     []string{"auth", "test"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, authorToken)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	slug := createArticle(t, author, "Authenticated Test Article "+unique, "auth", "test").Slug
 
 	// Create a reader user
-	readerUsername := "reader_" + unique
-	readerEmail := fmt.Sprintf("reader_%s@example.com", unique)
-
-	readerReq := UserPostRequestBody{
-		Username: readerUsername,
-		Email:    readerEmail,
-		Password: "testpass123",
-	}
-	readerRes := httpPostUsers(t, readerReq)
-	test.Equal(t, http.StatusCreated, readerRes.StatusCode)
-	t.Cleanup(func() { _ = readerRes.Body.Close() })
-
-	var readerResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(readerRes.Body).Decode(&readerResponse))
-	readerToken := readerResponse.Token
+	reader, _ := registerUser(t, "reader")
 
 	// Test: GET /api/articles/:slug as authenticated reader
-	res := httpGetArticlesSlug(t, slug, readerToken)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	// Verify response - authenticated user should get correct favorited/following status
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.Equal(t, slug, response.Article.Slug)
-	test.Equal(t, "Authenticated Test Article "+unique, response.Article.Title)
-	test.Equal(t, false, response.Article.Favorited)        // Reader hasn't favorited
-	test.Equal(t, false, response.Article.Author.Following) // Reader doesn't follow author
-	test.Equal(t, authorUsername, response.Article.Author.Username)
-}
-
-func httpPostArticles(t *testing.T, reqBody ArticlePostRequestBody, token string) *http.Response {
-	t.Helper()
-
-	body, err := json.Marshal(reqBody)
-	test.Nil(t, err)
-
-	req, err := http.NewRequest(http.MethodPost, endpoint+"/api/articles", bytes.NewReader(body))
-	test.Nil(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	if token != "" {
-		req.Header.Set("Authorization", "Token "+token)
-	}
-
-	res, err := http.DefaultClient.Do(req)
-	test.Nil(t, err)
-
-	return res
-}
-
-func httpGetArticlesSlug(t *testing.T, slug string, token string) *http.Response {
-	t.Helper()
-
-	req, err := http.NewRequest(http.MethodGet, endpoint+"/api/articles/"+slug, nil)
-	test.Nil(t, err)
-	if token != "" {
-		req.Header.Set("Authorization", "Token "+token)
-	}
-
-	res, err := http.DefaultClient.Do(req)
+	article, err := reader.GetArticle(t.Context(), slug)
 	test.Nil(t, err)
 
-	return res
-}
-
-type ArticlePostRequestBody struct {
-	Article ArticlePostRequest `json:"article"`
-}
-
-type ArticlePostRequest struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Body        string   `json:"body"`
-	TagList     []string `json:"tagList"`
-}
-
-type ArticleResponseBody struct {
-	Article ArticleResponse `json:"article"`
-}
-
-type ArticleResponse struct {
-	Slug           string        `json:"slug"`
-	Title          string        `json:"title"`
-	Description    string        `json:"description"`
-	Body           string        `json:"body"`
-	TagList        []string      `json:"tagList"`
-	CreatedAt      string        `json:"createdAt"`
-	UpdatedAt      string        `json:"updatedAt"`
-	Favorited      bool          `json:"favorited"`
-	FavoritesCount int64         `json:"favoritesCount"`
-	Author         AuthorProfile `json:"author"`
-}
-
-type AuthorProfile struct {
-	Username  string `json:"username"`
-	Bio       string `json:"bio"`
-	Image     string `json:"image"`
-	Following bool   `json:"following"`
+	// Verify response - authenticated user should get correct favorited/following status
+	test.Equal(t, slug, article.Slug)
+	test.Equal(t, "Authenticated Test Article "+unique, article.Title)
+	test.Equal(t, false, article.Favorited)        // Reader hasn't favorited
+	test.Equal(t, false, article.Author.Following) // Reader doesn't follow author
+	test.Equal(t, authorUser.Username, article.Author.Username)
 }
 
 func TestPutArticlesSlug_Success(t *testing.T) {
@@ -390,91 +200,24 @@ func TestPutArticlesSlug_Success(t *testing.T) {
 
 	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "article_updater_" + unique
-	email := fmt.Sprintf("updater_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Original Title " + unique,
-			Description: "Original description",
-			Body:        "Original body content",
-			TagList:     []string{"original"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	originalSlug := createResponse.Article.Slug
+	c, user := registerUser(t, "article_updater")
+	originalSlug := createArticle(t, c, "Original Title "+unique, "original").Slug
 
 	// Test: Update article with new title (should regenerate slug)
-	updateReq := ArticlePutRequestBody{
-		Article: ArticlePutRequest{
-			Title:       stringPtr("Updated Title " + unique),
-			Description: stringPtr("Updated description"),
-			Body:        stringPtr("Updated body content"),
-		},
-	}
-
-	res := httpPutArticlesSlug(t, originalSlug, updateReq, token)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	// Verify response
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.NotEqual(t, originalSlug, response.Article.Slug) // Slug should change
-	test.Equal(t, "Updated Title "+unique, response.Article.Title)
-	test.Equal(t, "Updated description", response.Article.Description)
-	test.Equal(t, "Updated body content", response.Article.Body)
-	test.Equal(t, username, response.Article.Author.Username)
-	test.NotNil(t, response.Article.UpdatedAt)
-}
-
-func httpPutArticlesSlug(t *testing.T, slug string, reqBody ArticlePutRequestBody, token string) *http.Response {
-	t.Helper()
-
-	body, err := json.Marshal(reqBody)
+	article, err := c.UpdateArticle(t.Context(), originalSlug, client.UpdateArticle{
+		Title:       stringPtr("Updated Title " + unique),
+		Description: stringPtr("Updated description"),
+		Body:        stringPtr("Updated body content"),
+	})
 	test.Nil(t, err)
 
-	req, err := http.NewRequest(http.MethodPut, endpoint+"/api/articles/"+slug, bytes.NewReader(body))
-	test.Nil(t, err)
-	req.Header.Set("Content-Type", "application/json")
-	if token != "" {
-		req.Header.Set("Authorization", "Token "+token)
-	}
-
-	res, err := http.DefaultClient.Do(req)
-	test.Nil(t, err)
-
-	return res
-}
-
-type ArticlePutRequestBody struct {
-	Article ArticlePutRequest `json:"article"`
-}
-
-type ArticlePutRequest struct {
-	Title       *string `json:"title,omitempty"`
-	Description *string `json:"description,omitempty"`
-	Body        *string `json:"body,omitempty"`
+	// Verify response
+	test.NotEqual(t, originalSlug, article.Slug) // Slug should change
+	test.Equal(t, "Updated Title "+unique, article.Title)
+	test.Equal(t, "Updated description", article.Description)
+	test.Equal(t, "Updated body content", article.Body)
+	test.Equal(t, user.Username, article.Author.Username)
+	test.NotZero(t, article.UpdatedAt)
 }
 
 func stringPtr(s string) *string {
@@ -485,33 +228,13 @@ func TestPutArticlesSlug_NotFound(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "user_" + unique
-	email := fmt.Sprintf("user_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "user")
 
 	// Test: Try to update non-existent article
-	updateReq := ArticlePutRequestBody{
-		Article: ArticlePutRequest{
-			Title: stringPtr("New Title"),
-		},
-	}
-
-	res := httpPutArticlesSlug(t, "nonexistent-slug", updateReq, token)
-	test.Equal(t, http.StatusNotFound, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := c.UpdateArticle(t.Context(), "nonexistent-slug", client.UpdateArticle{
+		Title: stringPtr("New Title"),
+	})
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
 }
 
 func TestPutArticlesSlug_Forbidden(t *testing.T) {
@@ -521,67 +244,17 @@ func TestPutArticlesSlug_Forbidden(t *testing.T) {
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
 
 	// User 1 - article author
-	author := "author_" + unique
-	authorEmail := fmt.Sprintf("author_%s@example.com", unique)
-
-	authorReq := UserPostRequestBody{
-		Username: author,
-		Email:    authorEmail,
-		Password: "testpass123",
-	}
-	authorRes := httpPostUsers(t, authorReq)
-	test.Equal(t, http.StatusCreated, authorRes.StatusCode)
-	t.Cleanup(func() { _ = authorRes.Body.Close() })
-
-	var authorResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(authorRes.Body).Decode(&authorResponse))
-	authorToken := authorResponse.Token
-
-	// Create an article as author
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Author's Article " + unique,
-			Description: "Description",
-			Body:        "Body content",
-			TagList:     []string{},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, authorToken)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	author, _ := registerUser(t, "author")
+	slug := createArticle(t, author, "Author's Article "+unique).Slug
 
 	// User 2 - different user
-	otherUser := "other_" + unique
-	otherEmail := fmt.Sprintf("other_%s@example.com", unique)
-
-	otherReq := UserPostRequestBody{
-		Username: otherUser,
-		Email:    otherEmail,
-		Password: "testpass123",
-	}
-	otherRes := httpPostUsers(t, otherReq)
-	test.Equal(t, http.StatusCreated, otherRes.StatusCode)
-	t.Cleanup(func() { _ = otherRes.Body.Close() })
-
-	var otherResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(otherRes.Body).Decode(&otherResponse))
-	otherToken := otherResponse.Token
+	other, _ := registerUser(t, "other")
 
 	// Test: Try to update article as different user
-	updateReq := ArticlePutRequestBody{
-		Article: ArticlePutRequest{
-			Title: stringPtr("Hacked Title"),
-		},
-	}
-
-	res := httpPutArticlesSlug(t, slug, updateReq, otherToken)
-	test.Equal(t, http.StatusForbidden, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := other.UpdateArticle(t.Context(), slug, client.UpdateArticle{
+		Title: stringPtr("Hacked Title"),
+	})
+	test.Equal(t, http.StatusForbidden, client.StatusCode(err))
 }
 
 func TestPutArticlesSlug_PartialUpdate(t *testing.T) {
@@ -589,150 +262,71 @@ func TestPutArticlesSlug_PartialUpdate(t *testing.T) {
 
 	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "partial_user_" + unique
-	email := fmt.Sprintf("partial_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Original Title " + unique,
-			Description: "Original description",
-			Body:        "Original body content",
-			TagList:     []string{"original"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	c, _ := registerUser(t, "partial_user")
+	slug := createArticle(t, c, "Original Title "+unique, "original").Slug
 
 	// Test: Update only body (partial update)
-	updateReq := ArticlePutRequestBody{
-		Article: ArticlePutRequest{
-			Body: stringPtr("Updated body only"),
-		},
-	}
-
-	res := httpPutArticlesSlug(t, slug, updateReq, token)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	article, err := c.UpdateArticle(t.Context(), slug, client.UpdateArticle{
+		Body: stringPtr("Updated body only"),
+	})
+	test.Nil(t, err)
 
 	// Verify response - slug and title should remain unchanged
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.Equal(t, slug, response.Article.Slug)                          // Slug unchanged
-	test.Equal(t, "Original Title "+unique, response.Article.Title)     // Title unchanged
-	test.Equal(t, "Original description", response.Article.Description) // Description unchanged
-	test.Equal(t, "Updated body only", response.Article.Body)           // Body updated
+	test.Equal(t, slug, article.Slug)                      // Slug unchanged
+	test.Equal(t, "Original Title "+unique, article.Title) // Title unchanged
+	test.Equal(t, "Test description", article.Description) // Description unchanged
+	test.Equal(t, "Updated body only", article.Body)       // Body updated
 }
 
-func TestDeleteArticlesSlug_Success(t *testing.T) {
+func TestPutArticlesSlug_Validation(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "article_deleter_" + unique
-	email := fmt.Sprintf("deleter_%s@example.com", unique)
+	c, _ := registerUser(t, "put_validation_user")
+	slug := createArticle(t, c, "Put Validation "+unique).Slug
 
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
+	testcases := map[string]client.UpdateArticle{
+		"empty title":          {Title: stringPtr("")},
+		"empty body":           {Body: stringPtr("")},
+		"description too long": {Description: stringPtr(strings.Repeat("d", 501))},
 	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Article to Delete " + unique,
-			Description: "Will be deleted",
-			Body:        "Body content",
-			TagList:     []string{"delete", "test"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
 
-	// Test: Delete the article
-	res := httpDeleteArticlesSlug(t, slug, token)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-	// Verify article is deleted by trying to get it
-	getRes := httpGetArticlesSlug(t, slug, "")
-	test.Equal(t, http.StatusNotFound, getRes.StatusCode)
-	t.Cleanup(func() { _ = getRes.Body.Close() })
+			_, err := c.UpdateArticle(t.Context(), slug, tc)
+			test.Equal(t, http.StatusUnprocessableEntity, client.StatusCode(err))
+		})
+	}
 }
 
-func httpDeleteArticlesSlug(t *testing.T, slug string, token string) *http.Response {
-	t.Helper()
+func TestDeleteArticlesSlug_Success(t *testing.T) {
+	t.Parallel()
 
-	req, err := http.NewRequest(http.MethodDelete, endpoint+"/api/articles/"+slug, nil)
-	test.Nil(t, err)
-	if token != "" {
-		req.Header.Set("Authorization", "Token "+token)
-	}
+	// Setup: Create a user and article
+	unique := fmt.Sprintf("%d", time.Now().UnixNano())
+	c, _ := registerUser(t, "article_deleter")
+	slug := createArticle(t, c, "Article to Delete "+unique, "delete", "test").Slug
 
-	res, err := http.DefaultClient.Do(req)
-	test.Nil(t, err)
+	// Test: Delete the article
+	test.Nil(t, c.DeleteArticle(t.Context(), slug))
 
-	return res
+	// Verify article is deleted by trying to get it
+	_, err := newClient().GetArticle(t.Context(), slug)
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
 }
 
 func TestDeleteArticlesSlug_NotFound(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "user_" + unique
-	email := fmt.Sprintf("user_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "user")
 
 	// Test: Try to delete non-existent article
-	res := httpDeleteArticlesSlug(t, "nonexistent-slug", token)
-	test.Equal(t, http.StatusNotFound, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	err := c.DeleteArticle(t.Context(), "nonexistent-slug")
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
 }
 
 func TestDeleteArticlesSlug_Forbidden(t *testing.T) {
@@ -742,61 +336,15 @@ func TestDeleteArticlesSlug_Forbidden(t *testing.T) {
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
 
 	// User 1 - article author
-	author := "author_" + unique
-	authorEmail := fmt.Sprintf("author_%s@example.com", unique)
-
-	authorReq := UserPostRequestBody{
-		Username: author,
-		Email:    authorEmail,
-		Password: "testpass123",
-	}
-	authorRes := httpPostUsers(t, authorReq)
-	test.Equal(t, http.StatusCreated, authorRes.StatusCode)
-	t.Cleanup(func() { _ = authorRes.Body.Close() })
-
-	var authorResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(authorRes.Body).Decode(&authorResponse))
-	authorToken := authorResponse.Token
-
-	// Create an article as author
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Author's Article " + unique,
-			Description: "Description",
-			Body:        "Body content",
-			TagList:     []string{},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, authorToken)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	author, _ := registerUser(t, "author")
+	slug := createArticle(t, author, "Author's Article "+unique).Slug
 
 	// User 2 - different user
-	otherUser := "other_" + unique
-	otherEmail := fmt.Sprintf("other_%s@example.com", unique)
-
-	otherReq := UserPostRequestBody{
-		Username: otherUser,
-		Email:    otherEmail,
-		Password: "testpass123",
-	}
-	otherRes := httpPostUsers(t, otherReq)
-	test.Equal(t, http.StatusCreated, otherRes.StatusCode)
-	t.Cleanup(func() { _ = otherRes.Body.Close() })
-
-	var otherResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(otherRes.Body).Decode(&otherResponse))
-	otherToken := otherResponse.Token
+	other, _ := registerUser(t, "other")
 
 	// Test: Try to delete article as different user
-	res := httpDeleteArticlesSlug(t, slug, otherToken)
-	test.Equal(t, http.StatusForbidden, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	err := other.DeleteArticle(t.Context(), slug)
+	test.Equal(t, http.StatusForbidden, client.StatusCode(err))
 }
 
 func TestGetArticles_Success(t *testing.T) {
@@ -804,63 +352,36 @@ func TestGetArticles_Success(t *testing.T) {
 
 	// Setup: Create a user and multiple articles
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "article_lister_" + unique
-	email := fmt.Sprintf("lister_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, user := registerUser(t, "article_lister")
 
 	// Create first article
-	article1Req := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "First Article " + unique,
-			Description: "First description",
-			Body:        "First body",
-			TagList:     []string{"golang", "test"},
-		},
-	}
-	article1Res := httpPostArticles(t, article1Req, token)
-	test.Equal(t, http.StatusCreated, article1Res.StatusCode)
-	t.Cleanup(func() { _ = article1Res.Body.Close() })
+	_, err := c.CreateArticle(t.Context(), client.NewArticle{
+		Title:       "First Article " + unique,
+		Description: "First description",
+		Body:        "First body",
+		TagList:     []string{"golang", "test"},
+	})
+	test.Nil(t, err)
 
 	// Small delay to ensure different created_at timestamps
 	time.Sleep(10 * time.Millisecond)
 
 	// Create second article
-	article2Req := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Second Article " + unique,
-			Description: "Second description",
-			Body:        "Second body",
-			TagList:     []string{"rust", "tutorial"},
-		},
-	}
-	article2Res := httpPostArticles(t, article2Req, token)
-	test.Equal(t, http.StatusCreated, article2Res.StatusCode)
-	t.Cleanup(func() { _ = article2Res.Body.Close() })
+	_, err = c.CreateArticle(t.Context(), client.NewArticle{
+		Title:       "Second Article " + unique,
+		Description: "Second description",
+		Body:        "Second body",
+		TagList:     []string{"rust", "tutorial"},
+	})
+	test.Nil(t, err)
 
 	// Test: GET /api/articles without authentication
 	// Use a large limit to ensure we get our articles even with test pollution
-	res := httpGetArticles(t, "limit=100")
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	// Verify response structure
-	var response ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
+	response, err := newClient().GetArticles(t.Context(), client.ArticlesOptions{Limit: 100})
+	test.Nil(t, err)
 
 	// Find our articles in the response (they should be the most recent)
-	var firstArticle, secondArticle *ArticleListResponse
+	var firstArticle, secondArticle *client.Article
 	for i := range response.Articles {
 		if response.Articles[i].Title == "First Article "+unique {
 			firstArticle = &response.Articles[i]
@@ -877,12 +398,13 @@ func TestGetArticles_Success(t *testing.T) {
 	test.Equal(t, "first-article-"+unique, firstArticle.Slug)
 	test.Equal(t, "First Article "+unique, firstArticle.Title)
 	test.Equal(t, "First description", firstArticle.Description)
+	test.Equal(t, "", firstArticle.Body)
 	test.Equal(t, 2, len(firstArticle.TagList))
 	test.Equal(t, false, firstArticle.Favorited)
 	test.Equal(t, int64(0), firstArticle.FavoritesCount)
-	test.Equal(t, username, firstArticle.Author.Username)
-	test.NotNil(t, firstArticle.CreatedAt)
-	test.NotNil(t, firstArticle.UpdatedAt)
+	test.Equal(t, user.Username, firstArticle.Author.Username)
+	test.NotZero(t, firstArticle.CreatedAt)
+	test.NotZero(t, firstArticle.UpdatedAt)
 
 	// Verify second article
 	test.Equal(t, "second-article-"+unique, secondArticle.Slug)
@@ -896,94 +418,27 @@ func TestGetArticles_WithLimitAndOffset(t *testing.T) {
 
 	// Setup: Create a user and 5 articles
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "article_paginator_" + unique
-	email := fmt.Sprintf("paginator_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "article_paginator")
 
 	// Create 5 articles
 	for i := 1; i <= 5; i++ {
-		articleReq := ArticlePostRequestBody{
-			Article: ArticlePostRequest{
-				Title:       fmt.Sprintf("Article %d %s", i, unique),
-				Description: fmt.Sprintf("Description %d", i),
-				Body:        fmt.Sprintf("Body %d", i),
-				TagList:     []string{},
-			},
-		}
-		res := httpPostArticles(t, articleReq, token)
-		test.Equal(t, http.StatusCreated, res.StatusCode)
-		t.Cleanup(func() { _ = res.Body.Close() })
+		createArticle(t, c, fmt.Sprintf("Article %d %s", i, unique))
 
 		// Small delay to ensure different timestamps
 		time.Sleep(5 * time.Millisecond)
 	}
 
 	// Test: Get articles with limit=2
-	res := httpGetArticles(t, "limit=2")
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	var response ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
+	response, err := newClient().GetArticles(t.Context(), client.ArticlesOptions{Limit: 2})
+	test.Nil(t, err)
 
 	// Should return exactly 2 articles (most recent)
 	test.True(t, len(response.Articles) <= 2)
 
 	// Test: Get articles with offset=2 and limit=2
-	res2 := httpGetArticles(t, "limit=2&offset=2")
-	test.Equal(t, http.StatusOK, res2.StatusCode)
-	t.Cleanup(func() { _ = res2.Body.Close() })
-
-	var response2 ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res2.Body).Decode(&response2))
-	test.True(t, len(response2.Articles) <= 2)
-}
-
-func httpGetArticles(t *testing.T, queryParams string) *http.Response {
-	t.Helper()
-
-	url := endpoint + "/api/articles"
-	if queryParams != "" {
-		url += "?" + queryParams
-	}
-
-	req, err := http.NewRequest(http.MethodGet, url, nil)
-	test.Nil(t, err)
-
-	res, err := http.DefaultClient.Do(req)
+	response2, err := newClient().GetArticles(t.Context(), client.ArticlesOptions{Limit: 2, Offset: 2})
 	test.Nil(t, err)
-
-	return res
-}
-
-type ArticlesResponseBody struct {
-	Articles      []ArticleListResponse `json:"articles"`
-	ArticlesCount int64                 `json:"articlesCount"`
-}
-
-type ArticleListResponse struct {
-	Slug           string        `json:"slug"`
-	Title          string        `json:"title"`
-	Description    string        `json:"description"`
-	TagList        []string      `json:"tagList"`
-	CreatedAt      string        `json:"createdAt"`
-	UpdatedAt      string        `json:"updatedAt"`
-	Favorited      bool          `json:"favorited"`
-	FavoritesCount int64         `json:"favoritesCount"`
-	Author         AuthorProfile `json:"author"`
-	// Note: Body field is intentionally omitted (per spec update 2024/08/16)
+	test.True(t, len(response2.Articles) <= 2)
 }
 
 func TestGetArticles_FilterByTag(t *testing.T) {
@@ -991,72 +446,22 @@ func TestGetArticles_FilterByTag(t *testing.T) {
 
 	// Setup: Create a user and articles with different tags
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "tag_filter_user_" + unique
-	email := fmt.Sprintf("tag_filter_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "tag_filter_user")
 
 	// Create article with "golang" tag
-	article1Req := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Golang Article " + unique,
-			Description: "About Golang",
-			Body:        "Golang content",
-			TagList:     []string{"golang", "programming"},
-		},
-	}
-	res1 := httpPostArticles(t, article1Req, token)
-	test.Equal(t, http.StatusCreated, res1.StatusCode)
-	t.Cleanup(func() { _ = res1.Body.Close() })
-
+	createArticle(t, c, "Golang Article "+unique, "golang", "programming")
 	time.Sleep(10 * time.Millisecond)
 
 	// Create article with "rust" tag
-	article2Req := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Rust Article " + unique,
-			Description: "About Rust",
-			Body:        "Rust content",
-			TagList:     []string{"rust", "programming"},
-		},
-	}
-	res2 := httpPostArticles(t, article2Req, token)
-	test.Equal(t, http.StatusCreated, res2.StatusCode)
-	t.Cleanup(func() { _ = res2.Body.Close() })
-
+	createArticle(t, c, "Rust Article "+unique, "rust", "programming")
 	time.Sleep(10 * time.Millisecond)
 
 	// Create article with only "golang" tag
-	article3Req := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Pure Golang " + unique,
-			Description: "Only Golang",
-			Body:        "Only Golang content",
-			TagList:     []string{"golang"},
-		},
-	}
-	res3 := httpPostArticles(t, article3Req, token)
-	test.Equal(t, http.StatusCreated, res3.StatusCode)
-	t.Cleanup(func() { _ = res3.Body.Close() })
+	createArticle(t, c, "Pure Golang "+unique, "golang")
 
 	// Test: Filter by "golang" tag - should return 2 articles
-	res := httpGetArticles(t, "tag=golang")
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	var response ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
+	response, err := newClient().GetArticles(t.Context(), client.ArticlesOptions{Tag: "golang"})
+	test.Nil(t, err)
 
 	// Should have exactly 2 articles with "golang" tag
 	golangCount := 0
@@ -1070,12 +475,8 @@ func TestGetArticles_FilterByTag(t *testing.T) {
 	test.Equal(t, 2, golangCount)
 
 	// Test: Filter by "rust" tag - should return 1 article
-	res4 := httpGetArticles(t, "tag=rust")
-	test.Equal(t, http.StatusOK, res4.StatusCode)
-	t.Cleanup(func() { _ = res4.Body.Close() })
-
-	var response4 ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res4.Body).Decode(&response4))
+	response4, err := newClient().GetArticles(t.Context(), client.ArticlesOptions{Tag: "rust"})
+	test.Nil(t, err)
 
 	rustCount := 0
 	for _, article := range response4.Articles {
@@ -1091,94 +492,38 @@ func TestGetArticles_FilterByAuthor(t *testing.T) {
 
 	// Setup: Create two users with articles
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-
-	// User 1
-	user1 := "author1_" + unique
-	user1Req := UserPostRequestBody{
-		Username: user1,
-		Email:    fmt.Sprintf("author1_%s@example.com", unique),
-		Password: "testpass123",
-	}
-	user1Res := httpPostUsers(t, user1Req)
-	test.Equal(t, http.StatusCreated, user1Res.StatusCode)
-	t.Cleanup(func() { _ = user1Res.Body.Close() })
-
-	var user1Response UserResponseBody
-	test.Nil(t, json.NewDecoder(user1Res.Body).Decode(&user1Response))
-	token1 := user1Response.Token
-
-	// User 2
-	user2 := "author2_" + unique
-	user2Req := UserPostRequestBody{
-		Username: user2,
-		Email:    fmt.Sprintf("author2_%s@example.com", unique),
-		Password: "testpass123",
-	}
-	user2Res := httpPostUsers(t, user2Req)
-	test.Equal(t, http.StatusCreated, user2Res.StatusCode)
-	t.Cleanup(func() { _ = user2Res.Body.Close() })
-
-	var user2Response UserResponseBody
-	test.Nil(t, json.NewDecoder(user2Res.Body).Decode(&user2Response))
-	token2 := user2Response.Token
+	c1, user1 := registerUser(t, "author1")
+	c2, user2 := registerUser(t, "author2")
 
 	// Create articles by user1
 	for i := 1; i <= 2; i++ {
-		articleReq := ArticlePostRequestBody{
-			Article: ArticlePostRequest{
-				Title:       fmt.Sprintf("User1 Article %d %s", i, unique),
-				Description: "By User1",
-				Body:        "Content by User1",
-				TagList:     []string{},
-			},
-		}
-		res := httpPostArticles(t, articleReq, token1)
-		test.Equal(t, http.StatusCreated, res.StatusCode)
-		t.Cleanup(func() { _ = res.Body.Close() })
+		createArticle(t, c1, fmt.Sprintf("User1 Article %d %s", i, unique))
 		time.Sleep(5 * time.Millisecond)
 	}
 
 	// Create article by user2
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "User2 Article " + unique,
-			Description: "By User2",
-			Body:        "Content by User2",
-			TagList:     []string{},
-		},
-	}
-	res := httpPostArticles(t, articleReq, token2)
-	test.Equal(t, http.StatusCreated, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	createArticle(t, c2, "User2 Article "+unique)
 
 	// Test: Filter by author=user1
-	res2 := httpGetArticles(t, "author="+user1)
-	test.Equal(t, http.StatusOK, res2.StatusCode)
-	t.Cleanup(func() { _ = res2.Body.Close() })
-
-	var response ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res2.Body).Decode(&response))
+	response, err := newClient().GetArticles(t.Context(), client.ArticlesOptions{Author: user1.Username})
+	test.Nil(t, err)
 
 	// Count articles by user1
 	user1Count := 0
 	for _, article := range response.Articles {
-		if article.Author.Username == user1 {
+		if article.Author.Username == user1.Username {
 			user1Count++
 		}
 	}
 	test.Equal(t, 2, user1Count)
 
 	// Test: Filter by author=user2
-	res3 := httpGetArticles(t, "author="+user2)
-	test.Equal(t, http.StatusOK, res3.StatusCode)
-	t.Cleanup(func() { _ = res3.Body.Close() })
-
-	var response3 ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res3.Body).Decode(&response3))
+	response3, err := newClient().GetArticles(t.Context(), client.ArticlesOptions{Author: user2.Username})
+	test.Nil(t, err)
 
 	user2Count := 0
 	for _, article := range response3.Articles {
-		if article.Author.Username == user2 {
+		if article.Author.Username == user2.Username {
 			user2Count++
 		}
 	}
@@ -1190,80 +535,37 @@ func TestGetArticlesFeed_Success(t *testing.T) {
 
 	// Setup: Create two users - follower and followed
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-
-	// Create follower user
-	followerUsername := "follower_" + unique
-	followerEmail := fmt.Sprintf("follower_%s@example.com", unique)
-	followerReq := UserPostRequestBody{
-		Username: followerUsername,
-		Email:    followerEmail,
-		Password: "testpass123",
-	}
-	followerRes := httpPostUsers(t, followerReq)
-	test.Equal(t, http.StatusCreated, followerRes.StatusCode)
-	t.Cleanup(func() { _ = followerRes.Body.Close() })
-
-	var followerResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(followerRes.Body).Decode(&followerResponse))
-	followerToken := followerResponse.Token
-
-	// Create followed user
-	followedUsername := "followed_" + unique
-	followedEmail := fmt.Sprintf("followed_%s@example.com", unique)
-	followedReq := UserPostRequestBody{
-		Username: followedUsername,
-		Email:    followedEmail,
-		Password: "testpass123",
-	}
-	followedRes := httpPostUsers(t, followedReq)
-	test.Equal(t, http.StatusCreated, followedRes.StatusCode)
-	t.Cleanup(func() { _ = followedRes.Body.Close() })
-
-	var followedResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(followedRes.Body).Decode(&followedResponse))
-	followedToken := followedResponse.Token
+	follower, _ := registerUser(t, "follower")
+	followed, followedUser := registerUser(t, "followed")
 
 	// Follower follows the followed user
-	followRes := httpPostProfileFollow(t, followedUsername, followerToken)
-	test.Equal(t, http.StatusOK, followRes.StatusCode)
-	t.Cleanup(func() { _ = followRes.Body.Close() })
+	_, err := follower.Follow(t.Context(), followedUser.Username)
+	test.Nil(t, err)
 
 	// Followed user creates an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Article in Feed " + unique,
-			Description: "This should appear in follower's feed",
-			Body:        "Content here",
-			TagList:     []string{"feed", "test"},
-		},
-	}
-	articleRes := httpPostArticles(t, articleReq, followedToken)
-	test.Equal(t, http.StatusCreated, articleRes.StatusCode)
-	t.Cleanup(func() { _ = articleRes.Body.Close() })
-
-	var articleResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(articleRes.Body).Decode(&articleResponse))
-	expectedSlug := articleResponse.Article.Slug
+	created, err := followed.CreateArticle(t.Context(), client.NewArticle{
+		Title:       "Article in Feed " + unique,
+		Description: "This should appear in follower's feed",
+		Body:        "Content here",
+		TagList:     []string{"feed", "test"},
+	})
+	test.Nil(t, err)
 
 	// Test: GET /api/articles/feed with follower's token
-	res := httpGetArticlesFeed(t, "", followerToken)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	response := waitForFeed(t, follower, 1)
 
 	// Verify response structure and content
-	var response ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
 	test.True(t, response.ArticlesCount > 0)
 	test.True(t, len(response.Articles) > 0)
 
 	// Verify the article from followed user is in the feed
 	found := false
 	for _, article := range response.Articles {
-		if article.Slug == expectedSlug {
+		if article.Slug == created.Slug {
 			found = true
 			test.Equal(t, "Article in Feed "+unique, article.Title)
 			test.Equal(t, "This should appear in follower's feed", article.Description)
-			test.Equal(t, followedUsername, article.Author.Username)
+			test.Equal(t, followedUser.Username, article.Author.Username)
 			test.Equal(t, true, article.Author.Following) // Should always be true in feed
 			break
 		}
@@ -1275,30 +577,11 @@ func TestGetArticlesFeed_EmptyFeed(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user who doesn't follow anyone
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "lonely_user_" + unique
-	email := fmt.Sprintf("lonely_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "lonely_user")
 
 	// Test: GET /api/articles/feed should return empty
-	res := httpGetArticlesFeed(t, "", token)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	var response ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
+	response, err := c.GetFeed(t.Context(), client.FeedOptions{})
+	test.Nil(t, err)
 	test.Equal(t, int64(0), response.ArticlesCount)
 	test.Equal(t, 0, len(response.Articles))
 }
@@ -1307,9 +590,8 @@ func TestGetArticlesFeed_Unauthorized(t *testing.T) {
 	t.Parallel()
 
 	// Test: GET /api/articles/feed without token should return 401
-	res := httpGetArticlesFeed(t, "", "")
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient().GetFeed(t.Context(), client.FeedOptions{})
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestGetArticlesFeed_Pagination(t *testing.T) {
@@ -1317,200 +599,174 @@ func TestGetArticlesFeed_Pagination(t *testing.T) {
 
 	// Setup: Create follower and followed users
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-
-	followerUsername := "follower_" + unique
-	followerEmail := fmt.Sprintf("follower_%s@example.com", unique)
-	followerReq := UserPostRequestBody{
-		Username: followerUsername,
-		Email:    followerEmail,
-		Password: "testpass123",
-	}
-	followerRes := httpPostUsers(t, followerReq)
-	test.Equal(t, http.StatusCreated, followerRes.StatusCode)
-	t.Cleanup(func() { _ = followerRes.Body.Close() })
-
-	var followerResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(followerRes.Body).Decode(&followerResponse))
-	followerToken := followerResponse.Token
-
-	followedUsername := "followed_" + unique
-	followedEmail := fmt.Sprintf("followed_%s@example.com", unique)
-	followedReq := UserPostRequestBody{
-		Username: followedUsername,
-		Email:    followedEmail,
-		Password: "testpass123",
-	}
-	followedRes := httpPostUsers(t, followedReq)
-	test.Equal(t, http.StatusCreated, followedRes.StatusCode)
-	t.Cleanup(func() { _ = followedRes.Body.Close() })
-
-	var followedResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(followedRes.Body).Decode(&followedResponse))
-	followedToken := followedResponse.Token
+	follower, _ := registerUser(t, "follower")
+	followed, followedUser := registerUser(t, "followed")
 
 	// Follower follows the followed user
-	followRes := httpPostProfileFollow(t, followedUsername, followerToken)
-	test.Equal(t, http.StatusOK, followRes.StatusCode)
-	t.Cleanup(func() { _ = followRes.Body.Close() })
+	_, err := follower.Follow(t.Context(), followedUser.Username)
+	test.Nil(t, err)
 
 	// Create 3 articles
 	for i := range 3 {
-		articleReq := ArticlePostRequestBody{
-			Article: ArticlePostRequest{
-				Title:       fmt.Sprintf("Article %d %s", i, unique),
-				Description: "Test article",
-				Body:        "Content",
-				TagList:     []string{},
-			},
-		}
-		articleRes := httpPostArticles(t, articleReq, followedToken)
-		test.Equal(t, http.StatusCreated, articleRes.StatusCode)
-		t.Cleanup(func() { _ = articleRes.Body.Close() })
+		createArticle(t, followed, fmt.Sprintf("Article %d %s", i, unique))
 	}
 
-	// Test with limit parameter set to 2
-	res1 := httpGetArticlesFeed(t, "limit=2", followerToken)
-	test.Equal(t, http.StatusOK, res1.StatusCode)
-	t.Cleanup(func() { _ = res1.Body.Close() })
+	waitForFeed(t, follower, 3)
 
-	var response1 ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res1.Body).Decode(&response1))
+	// Test with limit parameter set to 2
+	response1, err := follower.GetFeed(t.Context(), client.FeedOptions{Limit: 2})
+	test.Nil(t, err)
 	test.Equal(t, int64(3), response1.ArticlesCount)
 	test.Equal(t, 2, len(response1.Articles))
 
 	// Test with offset parameter set to 2
-	res2 := httpGetArticlesFeed(t, "offset=2", followerToken)
-	test.Equal(t, http.StatusOK, res2.StatusCode)
-	t.Cleanup(func() { _ = res2.Body.Close() })
-
-	var response2 ArticlesResponseBody
-	test.Nil(t, json.NewDecoder(res2.Body).Decode(&response2))
+	response2, err := follower.GetFeed(t.Context(), client.FeedOptions{Offset: 2})
+	test.Nil(t, err)
 	test.Equal(t, int64(3), response2.ArticlesCount)
 	test.Equal(t, 1, len(response2.Articles))
 }
 
-func httpGetArticlesFeed(t *testing.T, queryParams string, token string) *http.Response {
-	t.Helper()
-
-	url := endpoint + "/api/articles/feed"
-	if queryParams != "" {
-		url += "?" + queryParams
-	}
+func TestGetArticlesFeed_Cursor(t *testing.T) {
+	t.Parallel()
 
-	req, err := http.NewRequest(http.MethodGet, url, nil)
+	unique := fmt.Sprintf("%d", time.Now().UnixNano())
+	follower, _ := registerUser(t, "cursor_follower")
+	followed, followedUser := registerUser(t, "cursor_followed")
+	_, err := follower.Follow(t.Context(), followedUser.Username)
 	test.Nil(t, err)
-	if token != "" {
-		req.Header.Set("Authorization", "Token "+token)
+	for i := range 3 {
+		createArticle(t, followed, fmt.Sprintf("Cursor Article %d %s", i, unique))
 	}
+	waitForFeed(t, follower, 3)
 
-	res, err := http.DefaultClient.Do(req)
+	first, err := follower.GetFeed(t.Context(), client.FeedOptions{Limit: 2, NoCount: true})
 	test.Nil(t, err)
+	test.Equal(t, 2, len(first.Articles))
+	test.Equal(t, int64(0), first.ArticlesCount)
+	test.NotEqual(t, "", first.NextCursor)
+
+	// An article created between pages would shift an offset page, but not the page after the cursor
+	createArticle(t, followed, "Cursor Article late "+unique)
+	waitForFeed(t, follower, 4)
+	second, err := follower.GetFeed(t.Context(), client.FeedOptions{Limit: 2, Cursor: first.NextCursor})
+	test.Nil(t, err)
+	test.Equal(t, int64(4), second.ArticlesCount)
+	test.Equal(t, 1, len(second.Articles))
+	test.Equal(t, "", second.NextCursor)
+	for _, article := range first.Articles {
+		test.NotEqual(t, article.Slug, second.Articles[0].Slug)
+	}
 
-	return res
+	_, err = follower.GetFeed(t.Context(), client.FeedOptions{Cursor: "not-a-cursor"})
+	test.Equal(t, http.StatusUnprocessableEntity, client.StatusCode(err))
+	_, err = follower.GetFeed(t.Context(), client.FeedOptions{Cursor: first.NextCursor, Offset: 1})
+	test.Equal(t, http.StatusUnprocessableEntity, client.StatusCode(err))
 }
 
-func TestPostArticlesSlugFavorite_Success(t *testing.T) {
+func TestGetArticlesFeed_FollowAndUnfollow(t *testing.T) {
 	t.Parallel()
 
-	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "favorite_user_" + unique
-	email := fmt.Sprintf("favorite_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
+	follower, _ := registerUser(t, "backfill_follower")
+	followed, followedUser := registerUser(t, "backfill_followed")
+	for i := range 2 {
+		createArticle(t, followed, fmt.Sprintf("Backfill Article %d %s", i, unique))
 	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Article to Favorite " + unique,
-			Description: "Test description",
-			Body:        "Test body content",
-			TagList:     []string{"test"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
 
-	// Test: Favorite the article
-	res := httpPostArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	// Articles published before the follow are backfilled into the feed
+	_, err := follower.Follow(t.Context(), followedUser.Username)
+	test.Nil(t, err)
+	response := waitForFeed(t, follower, 2)
+	test.Equal(t, 2, len(response.Articles))
 
-	// Verify response
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.Equal(t, slug, response.Article.Slug)
-	test.Equal(t, true, response.Article.Favorited)
-	test.Equal(t, int64(1), response.Article.FavoritesCount)
-	test.Equal(t, username, response.Article.Author.Username)
+	// and pruned from it after the unfollow
+	_, err = follower.Unfollow(t.Context(), followedUser.Username)
+	test.Nil(t, err)
+	response = waitForFeed(t, follower, 0)
+	test.Equal(t, 0, len(response.Articles))
 }
 
-func httpPostArticlesSlugFavorite(t *testing.T, slug string, token string) *http.Response {
+// waitForFeed polls the feed of c until it counts want articles.
+// The feed is written by the job runner after the follow, unfollow or article that changes it, not by the request itself.
+func waitForFeed(t *testing.T, c *client.Client, want int64) client.Articles {
 	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		response, err := c.GetFeed(t.Context(), client.FeedOptions{})
+		test.Nil(t, err)
+		if response.ArticlesCount == want || time.Now().After(deadline) {
+			test.Equal(t, want, response.ArticlesCount)
+			return response
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
 
-	req, err := http.NewRequest(http.MethodPost, endpoint+"/api/articles/"+slug+"/favorite", nil)
-	test.Nil(t, err)
-	if token != "" {
-		req.Header.Set("Authorization", "Token "+token)
+func TestGetArticles_Cursor(t *testing.T) {
+	t.Parallel()
+
+	unique := fmt.Sprintf("%d", time.Now().UnixNano())
+	c, user := registerUser(t, "cursor_author")
+	for i := range 5 {
+		createArticle(t, c, fmt.Sprintf("Cursor List %d %s", i, unique))
+	}
+
+	// Walking the cursors visits every article once, newest first
+	var slugs []string
+	opts := client.ArticlesOptions{Author: user.Username, Limit: 2}
+	for {
+		page, err := newClient().GetArticles(t.Context(), opts)
+		test.Nil(t, err)
+		test.Equal(t, int64(5), page.ArticlesCount)
+		for _, article := range page.Articles {
+			slugs = append(slugs, article.Slug)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		opts.Cursor = page.NextCursor
 	}
+	test.Equal(t, 5, len(slugs))
+	test.Contains(t, slugs[0], "cursor-list-4")
+	test.Contains(t, slugs[4], "cursor-list-0")
+}
+
+func TestPostArticlesSlugFavorite_Success(t *testing.T) {
+	t.Parallel()
 
-	res, err := http.DefaultClient.Do(req)
+	// Setup: Create a user and article
+	unique := fmt.Sprintf("%d", time.Now().UnixNano())
+	c, user := registerUser(t, "favorite_user")
+	slug := createArticle(t, c, "Article to Favorite "+unique, "test").Slug
+
+	// Test: Favorite the article
+	article, err := c.Favorite(t.Context(), slug)
 	test.Nil(t, err)
 
-	return res
+	// Verify response
+	test.Equal(t, slug, article.Slug)
+	test.Equal(t, true, article.Favorited)
+	test.Equal(t, int64(1), article.FavoritesCount)
+	test.Equal(t, user.Username, article.Author.Username)
 }
 
 func TestPostArticlesSlugFavorite_NotFound(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "user_" + unique
-	email := fmt.Sprintf("user_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "user")
 
 	// Test: Try to favorite non-existent article
-	res := httpPostArticlesSlugFavorite(t, "nonexistent-slug", token)
-	test.Equal(t, http.StatusNotFound, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := c.Favorite(t.Context(), "nonexistent-slug")
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
 }
 
 func TestPostArticlesSlugFavorite_Unauthorized(t *testing.T) {
 	t.Parallel()
 
 	// Test: Try to favorite without token
-	res := httpPostArticlesSlugFavorite(t, "some-slug", "")
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient().Favorite(t.Context(), "some-slug")
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestPostArticlesSlugFavorite_Idempotent(t *testing.T) {
@@ -1518,59 +774,20 @@ func TestPostArticlesSlugFavorite_Idempotent(t *testing.T) {
 
 	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "idempotent_user_" + unique
-	email := fmt.Sprintf("idempotent_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Article for Idempotent Test " + unique,
-			Description: "Test description",
-			Body:        "Test body content",
-			TagList:     []string{"test"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	c, _ := registerUser(t, "idempotent_user")
+	slug := createArticle(t, c, "Article for Idempotent Test "+unique, "test").Slug
 
 	// Test: Favorite the article first time
-	res1 := httpPostArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, res1.StatusCode)
-	t.Cleanup(func() { _ = res1.Body.Close() })
-
-	var response1 ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res1.Body).Decode(&response1))
-	test.Equal(t, true, response1.Article.Favorited)
-	test.Equal(t, int64(1), response1.Article.FavoritesCount)
+	response1, err := c.Favorite(t.Context(), slug)
+	test.Nil(t, err)
+	test.Equal(t, true, response1.Favorited)
+	test.Equal(t, int64(1), response1.FavoritesCount)
 
 	// Test: Favorite the same article again (should be idempotent)
-	res2 := httpPostArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, res2.StatusCode)
-	t.Cleanup(func() { _ = res2.Body.Close() })
-
-	var response2 ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res2.Body).Decode(&response2))
-	test.Equal(t, true, response2.Article.Favorited)
-	test.Equal(t, int64(1), response2.Article.FavoritesCount) // Count should still be 1
+	response2, err := c.Favorite(t.Context(), slug)
+	test.Nil(t, err)
+	test.Equal(t, true, response2.Favorited)
+	test.Equal(t, int64(1), response2.FavoritesCount) // Count should still be 1
 }
 
 func TestDeleteArticlesSlugFavorite_Success(t *testing.T) {
@@ -1578,108 +795,41 @@ func TestDeleteArticlesSlugFavorite_Success(t *testing.T) {
 
 	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "unfavorite_user_" + unique
-	email := fmt.Sprintf("unfavorite_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Article to Unfavorite " + unique,
-			Description: "Test description",
-			Body:        "Test body content",
-			TagList:     []string{"test"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	c, user := registerUser(t, "unfavorite_user")
+	slug := createArticle(t, c, "Article to Unfavorite "+unique, "test").Slug
 
 	// First, favorite the article
-	favRes := httpPostArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, favRes.StatusCode)
-	t.Cleanup(func() { _ = favRes.Body.Close() })
-
-	// Test: Unfavorite the article
-	res := httpDeleteArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	// Verify response
-	var response ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
-	test.Equal(t, slug, response.Article.Slug)
-	test.Equal(t, false, response.Article.Favorited)
-	test.Equal(t, int64(0), response.Article.FavoritesCount)
-	test.Equal(t, username, response.Article.Author.Username)
-}
-
-func httpDeleteArticlesSlugFavorite(t *testing.T, slug string, token string) *http.Response {
-	t.Helper()
-
-	req, err := http.NewRequest(http.MethodDelete, endpoint+"/api/articles/"+slug+"/favorite", nil)
+	_, err := c.Favorite(t.Context(), slug)
 	test.Nil(t, err)
-	if token != "" {
-		req.Header.Set("Authorization", "Token "+token)
-	}
 
-	res, err := http.DefaultClient.Do(req)
+	// Test: Unfavorite the article
+	article, err := c.Unfavorite(t.Context(), slug)
 	test.Nil(t, err)
 
-	return res
+	// Verify response
+	test.Equal(t, slug, article.Slug)
+	test.Equal(t, false, article.Favorited)
+	test.Equal(t, int64(0), article.FavoritesCount)
+	test.Equal(t, user.Username, article.Author.Username)
 }
 
 func TestDeleteArticlesSlugFavorite_NotFound(t *testing.T) {
 	t.Parallel()
 
 	// Setup: Create a user
-	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "user_" + unique
-	email := fmt.Sprintf("user_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
+	c, _ := registerUser(t, "user")
 
 	// Test: Try to unfavorite non-existent article
-	res := httpDeleteArticlesSlugFavorite(t, "nonexistent-slug", token)
-	test.Equal(t, http.StatusNotFound, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := c.Unfavorite(t.Context(), "nonexistent-slug")
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
 }
 
 func TestDeleteArticlesSlugFavorite_Unauthorized(t *testing.T) {
 	t.Parallel()
 
 	// Test: Try to unfavorite without token
-	res := httpDeleteArticlesSlugFavorite(t, "some-slug", "")
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient().Unfavorite(t.Context(), "some-slug")
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestDeleteArticlesSlugFavorite_Idempotent(t *testing.T) {
@@ -1687,62 +837,22 @@ func TestDeleteArticlesSlugFavorite_Idempotent(t *testing.T) {
 
 	// Setup: Create a user and article
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	username := "idempotent_unfav_user_" + unique
-	email := fmt.Sprintf("idempotent_unfav_%s@example.com", unique)
-
-	userReq := UserPostRequestBody{
-		Username: username,
-		Email:    email,
-		Password: "testpass123",
-	}
-	userRes := httpPostUsers(t, userReq)
-	test.Equal(t, http.StatusCreated, userRes.StatusCode)
-	t.Cleanup(func() { _ = userRes.Body.Close() })
-
-	var userResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(userRes.Body).Decode(&userResponse))
-	token := userResponse.Token
-
-	// Create an article
-	articleReq := ArticlePostRequestBody{
-		Article: ArticlePostRequest{
-			Title:       "Article for Idempotent Unfavorite Test " + unique,
-			Description: "Test description",
-			Body:        "Test body content",
-			TagList:     []string{"test"},
-		},
-	}
-
-	createRes := httpPostArticles(t, articleReq, token)
-	test.Equal(t, http.StatusCreated, createRes.StatusCode)
-	t.Cleanup(func() { _ = createRes.Body.Close() })
-
-	var createResponse ArticleResponseBody
-	test.Nil(t, json.NewDecoder(createRes.Body).Decode(&createResponse))
-	slug := createResponse.Article.Slug
+	c, _ := registerUser(t, "idempotent_unfav_user")
+	slug := createArticle(t, c, "Article for Idempotent Unfavorite Test "+unique, "test").Slug
 
 	// Favorite the article first
-	favRes := httpPostArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, favRes.StatusCode)
-	t.Cleanup(func() { _ = favRes.Body.Close() })
+	_, err := c.Favorite(t.Context(), slug)
+	test.Nil(t, err)
 
 	// Test: Unfavorite the article first time
-	res1 := httpDeleteArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, res1.StatusCode)
-	t.Cleanup(func() { _ = res1.Body.Close() })
-
-	var response1 ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res1.Body).Decode(&response1))
-	test.Equal(t, false, response1.Article.Favorited)
-	test.Equal(t, int64(0), response1.Article.FavoritesCount)
+	response1, err := c.Unfavorite(t.Context(), slug)
+	test.Nil(t, err)
+	test.Equal(t, false, response1.Favorited)
+	test.Equal(t, int64(0), response1.FavoritesCount)
 
 	// Test: Unfavorite the same article again (should be idempotent)
-	res2 := httpDeleteArticlesSlugFavorite(t, slug, token)
-	test.Equal(t, http.StatusOK, res2.StatusCode)
-	t.Cleanup(func() { _ = res2.Body.Close() })
-
-	var response2 ArticleResponseBody
-	test.Nil(t, json.NewDecoder(res2.Body).Decode(&response2))
-	test.Equal(t, false, response2.Article.Favorited)
-	test.Equal(t, int64(0), response2.Article.FavoritesCount) // Count should still be 0
+	response2, err := c.Unfavorite(t.Context(), slug)
+	test.Nil(t, err)
+	test.Equal(t, false, response2.Favorited)
+	test.Equal(t, int64(0), response2.FavoritesCount) // Count should still be 0
 }
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	backupPrefix     = "realworld-"
+	backupSuffix     = ".db"
+	backupTimeFormat = "20060102T150405.000Z"
+)
+
+// backupper writes online snapshots of a SQLite database file into dir and prunes all but the newest keep of them.
+// Snapshots use VACUUM INTO on a connection of their own: in WAL mode it reads a consistent snapshot
+// while the server keeps reading and writing, and it cannot run on the query_only connections of [openReadDB].
+type backupper struct {
+	db   *sql.DB
+	dir  string
+	keep int
+
+	mu sync.Mutex // one backup at a time, whether scheduled or requested
+}
+
+// newBackupper opens a connection to the SQLite database at path for backups into dir.
+func newBackupper(path, dir string, keep int) (*backupper, error) {
+	if keep < 1 {
+		return nil, fmt.Errorf("backups to keep must be at least 1, got %d", keep)
+	}
+	db, err := sql.Open("sqlite", withPragmas(path))
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(1)
+	return &backupper{db: db, dir: dir, keep: keep}, nil
+}
+
+// backup writes a new snapshot, prunes the old ones and returns the path of the new one.
+func (b *backupper) backup(ctx context.Context) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := os.MkdirAll(b.dir, 0o700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(b.dir, backupPrefix+time.Now().UTC().Format(backupTimeFormat)+backupSuffix)
+	if _, err := b.db.ExecContext(ctx, "VACUUM INTO ?", path); err != nil {
+		return "", fmt.Errorf("backup to %s: %w", path, err)
+	}
+	return path, b.prune()
+}
+
+// prune removes all but the newest keep backups in dir.
+// Backup names sort by the time they were taken, and other files in dir are left alone.
+func (b *backupper) prune() error {
+	entries, err := os.ReadDir(b.dir)
+	if err != nil {
+		return err
+	}
+
+	var backups []string
+	for _, entry := range entries {
+		if name := entry.Name(); strings.HasPrefix(name, backupPrefix) && strings.HasSuffix(name, backupSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	slices.Sort(backups)
+
+	var errs []error
+	for _, name := range backups[:max(0, len(backups)-b.keep)] {
+		errs = append(errs, os.Remove(filepath.Join(b.dir, name)))
+	}
+	return errors.Join(errs...)
+}
+
+// schedule takes a backup every interval until ctx is canceled.
+// Failures are logged and retried on the next tick, so a full disk does not stop the server.
+func (b *backupper) schedule(ctx context.Context, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if path, err := b.backup(ctx); err != nil {
+				log.ErrorContext(ctx, "scheduled backup failed", slog.String("error", err.Error()))
+			} else {
+				log.InfoContext(ctx, "scheduled backup", slog.String("path", path))
+			}
+		}
+	}
+}
+
+// Close closes the backup connection.
+func (b *backupper) Close() error {
+	return b.db.Close()
+}
+
+// restoreDB replaces the SQLite database file at path with the backup at from, after checking the backup's integrity.
+// The backup is copied next to path and renamed over it, so path is either the old or the restored database, never a mix.
+// It refuses to run while the database is open elsewhere, such as by a running server, see [checkDBUnused].
+func restoreDB(ctx context.Context, path, from string) error {
+	// Opened before the check, since SQLite would create a missing file and find the empty database fine
+	src, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer src.Close() //nolint:errcheck
+
+	backup, err := sql.Open("sqlite", withPragmas(from, "query_only(1)"))
+	if err != nil {
+		return err
+	}
+	problems, err := queryStrings(ctx, backup, "PRAGMA integrity_check")
+	_ = backup.Close()
+	if err != nil {
+		return fmt.Errorf("check %s: %w", from, err)
+	}
+	if len(problems) != 1 || problems[0] != "ok" {
+		return fmt.Errorf("backup %s failed the integrity check:\n%s", from, strings.Join(problems, "\n"))
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".restore-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // fails once renamed, which is the point
+	if _, err := io.Copy(tmp, src); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	// The -wal and -shm files of the old database go first: SQLite would replay that WAL onto the restored file
+	// if the process died between the two steps, corrupting it
+	if err := checkDBUnused(ctx, path); err != nil {
+		return err
+	}
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(path + suffix); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// checkDBUnused returns an error if another connection has the SQLite database at path open, as a running server does.
+// In WAL mode every open connection holds a shared lock on the file, so an exclusive lock is only granted
+// when there is no other. Taking it also checkpoints a WAL left behind by a crash into the file.
+func checkDBUnused(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		return nil // restoring to a new file
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close() //nolint:errcheck
+	db.SetMaxOpenConns(1)
+
+	for _, stmt := range []string{"PRAGMA busy_timeout = 0", "PRAGMA locking_mode = EXCLUSIVE", "BEGIN EXCLUSIVE", "ROLLBACK"} {
+		if _, err := db.ExecContext(ctx, stmt); err != nil {
+			return fmt.Errorf("database %s is in use, stop the server first: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// handlePostAdminBackups returns an [http.HandlerFunc] that takes a backup right away and responds with its path.
+func handlePostAdminBackups(backups *backupper) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		path, err := backups.backup(r.Context())
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+		encodeResponse(r.Context(), http.StatusCreated, backupResponseBody{
+			Backup: backupResponse{Path: path, Size: info.Size(), CreatedAt: info.ModTime().UTC().Format("2006-01-02T15:04:05.000Z")},
+		}, w)
+	}
+}
+
+type backupResponseBody struct {
+	Backup backupResponse `json:"backup"`
+}
+
+type backupResponse struct {
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	CreatedAt string `json:"createdAt"`
+}
+
+// authenticateAdmin is a middleware that only lets requests with the "Authorization: Bearer <token>" header through.
+// Admin routes are not tied to a user account, so the token is a shared secret configured with -admin-token.
+func authenticateAdmin(next http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			encodeErrorResponse(r.Context(), http.StatusUnauthorized, []error{errors.New("invalid admin token")}, w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
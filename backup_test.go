@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/internal/sqlite"
+)
+
+func TestHandlePostAdminBackups(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "realworld.db")
+	_, err := admin(t, dbPath, "user", "create", "-username", "snapshot", "-email", "snapshot@example.com", "-password", "snapshotpass")
+	test.Nil(t, err)
+
+	db, readDB, err := openStore(t.Context(), "sqlite", dbPath)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	backups, err := newBackupper(dbPath, filepath.Join(dir, "backups"), 2)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = backups.Close() })
+
+	server := httptest.NewServer(route(slog.New(slog.NewTextHandler(io.Discard, nil)), "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret", adminToken: "admin-secret", backups: backups}))
+	t.Cleanup(server.Close)
+
+	post := func(token string) *http.Response {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+"/admin/backups", nil)
+		test.Nil(t, err)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		res, err := http.DefaultClient.Do(req)
+		test.Nil(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	test.Equal(t, http.StatusUnauthorized, post("").StatusCode)
+	test.Equal(t, http.StatusUnauthorized, post("wrong-secret").StatusCode)
+
+	res := post("admin-secret")
+	test.Equal(t, http.StatusCreated, res.StatusCode)
+	var body backupResponseBody
+	test.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	test.NotZero(t, body.Backup.Size)
+
+	// The snapshot is a complete database taken while the server holds the file open
+	snapshot, err := openDB(t.Context(), body.Backup.Path)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = snapshot.Close() })
+	user, err := sqlite.New(snapshot).GetUserByUsername(t.Context(), "snapshot")
+	test.Nil(t, err)
+	test.Equal(t, "snapshot@example.com", user.Email)
+}
+
+func TestHandlePostAdminBackups_Disabled(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "realworld.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	server := httptest.NewServer(route(slog.New(slog.NewTextHandler(io.Discard, nil)), "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret"}))
+	t.Cleanup(server.Close)
+
+	// Without -admin-token there are no /admin routes at all
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+"/admin/backups", nil)
+	test.Nil(t, err)
+	res, err := http.DefaultClient.Do(req)
+	test.Nil(t, err)
+	defer res.Body.Close() //nolint:errcheck
+	test.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestBackupper_Prune(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "realworld.db")
+	db, err := openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	test.Nil(t, db.Close())
+
+	backupDir := filepath.Join(dir, "backups")
+	test.Nil(t, os.MkdirAll(backupDir, 0o700))
+	for _, name := range []string{"realworld-20200101T000000.000Z.db", "realworld-20210101T000000.000Z.db", "notes.txt"} {
+		test.Nil(t, os.WriteFile(filepath.Join(backupDir, name), nil, 0o600))
+	}
+
+	backups, err := newBackupper(dbPath, backupDir, 2)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = backups.Close() })
+	path, err := backups.backup(t.Context())
+	test.Nil(t, err)
+
+	entries, err := os.ReadDir(backupDir)
+	test.Nil(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	test.DeepEqual(t, []string{"notes.txt", "realworld-20210101T000000.000Z.db", filepath.Base(path)}, names)
+
+	_, err = newBackupper(dbPath, backupDir, 0)
+	test.NotNil(t, err)
+}
+
+func TestAdmin_DBRestore(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "realworld.db")
+	_, err := admin(t, dbPath, "user", "create", "-username", "before", "-email", "before@example.com", "-password", "beforepass")
+	test.Nil(t, err)
+	backupPath := filepath.Join(dir, "backup.db")
+	_, err = admin(t, dbPath, "db", "backup", backupPath)
+	test.Nil(t, err)
+	_, err = admin(t, dbPath, "user", "create", "-username", "after", "-email", "after@example.com", "-password", "afterpass")
+	test.Nil(t, err)
+
+	// A file that is not a database fails the check and leaves the database alone
+	garbage := filepath.Join(dir, "garbage.db")
+	test.Nil(t, os.WriteFile(garbage, []byte("not a database, just some bytes"), 0o600))
+	_, err = admin(t, dbPath, "db", "restore", garbage)
+	test.NotNil(t, err)
+	_, err = admin(t, dbPath, "db", "restore", filepath.Join(dir, "missing.db"))
+	test.NotNil(t, err)
+
+	// A server with the database open keeps it
+	server, err := openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	_, err = admin(t, dbPath, "db", "restore", backupPath)
+	test.NotNil(t, err)
+	test.Contains(t, err.Error(), "in use")
+	test.Nil(t, server.Close())
+
+	out, err := admin(t, dbPath, "db", "restore", backupPath)
+	test.Nil(t, err)
+	test.Contains(t, out, "restored")
+	for _, suffix := range []string{"-wal", "-shm"} {
+		_, err = os.Stat(dbPath + suffix)
+		test.True(t, os.IsNotExist(err))
+	}
+
+	db, err := openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	_, err = sqlite.New(db).GetUserByUsername(t.Context(), "before")
+	test.Nil(t, err)
+	_, err = sqlite.New(db).GetUserByUsername(t.Context(), "after")
+	test.NotNil(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "missing.db"))
+	test.True(t, os.IsNotExist(err))
+}
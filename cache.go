@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/cache"
+)
+
+// responseCache holds the responses of anonymous reads, which are the same for every anonymous client.
+// Each response is tagged with what it shows, see the tag functions below,
+// and the handlers that change those invalidate the tags after they commit.
+type responseCache = cache.Cache[cachedResponse]
+
+// publishedResponses is the response cache of the server, whose [cache.Stats] are published as the responseCache expvar.
+var publishedResponses atomic.Pointer[responseCache]
+
+func init() {
+	expvar.Publish("responseCache", expvar.Func(func() any { return publishedResponses.Load().Stats() }))
+}
+
+// cachedResponse is a response recorded for the response cache.
+type cachedResponse struct {
+	status int
+	header http.Header
+	body   []byte
+}
+
+// newResponseCache returns a cache of at most maxBytes of response bodies, each kept for at most ttl.
+func newResponseCache(maxBytes int64, ttl time.Duration) *responseCache {
+	return cache.New(maxBytes, ttl, func(res cachedResponse) int64 { return int64(len(res.body)) })
+}
+
+// cacheAnonymous is a middleware that serves GET requests without an authenticated user from responses,
+// keyed by path and query, and passes any other request through. It must run after [authenticateOptional].
+// Only 200 responses are stored, tagged with tags of the request and the response body,
+// but concurrent requests for the same key share whatever response the first of them gets.
+// A nil responses disables caching.
+func cacheAnonymous(next http.Handler, responses *responseCache, tags func(r *http.Request, body []byte) []string) http.Handler {
+	if responses == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(userIDKey).(int64); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		key := r.URL.Path + "?" + r.URL.Query().Encode()
+		res := responses.Do(key, func() (cachedResponse, []string, bool) {
+			// Other requests wait for this response, so it is not canceled with the request that started it
+			// and it is the full response, not a 304 for the validators of one client
+			load := r.Clone(context.WithoutCancel(r.Context()))
+			load.Header.Del("If-None-Match")
+			load.Header.Del("If-Modified-Since")
+			rec := bufferedResponse{header: make(http.Header)}
+			next.ServeHTTP(&rec, load)
+			res := cachedResponse{status: rec.status, header: rec.header, body: rec.body.Bytes()}
+			if res.status != http.StatusOK {
+				return res, nil, false
+			}
+			// A response without tags could never be invalidated, so it is not stored either
+			tags := tags(r, res.body)
+			return res, tags, len(tags) > 0
+		})
+
+		for name, values := range res.header {
+			w.Header()[name] = append([]string(nil), values...)
+		}
+		if res.status == http.StatusOK && res.header.Get("ETag") != "" {
+			lastModified, _ := http.ParseTime(res.header.Get("Last-Modified"))
+			if isNotModified(r.Header, res.header.Get("ETag"), lastModified) {
+				w.Header().Del("Content-Type")
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+		}
+		w.WriteHeader(res.status)
+		_, _ = w.Write(res.body)
+	})
+}
+
+// bufferedResponse is an [http.ResponseWriter] that keeps the response in memory.
+type bufferedResponse struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) Header() http.Header {
+	return b.header
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	b.WriteHeader(http.StatusOK)
+	return b.body.Write(p)
+}
+
+// Cache tags. An article shows its author, and a list shows its articles and authors;
+// a list also changes with the articles that match its filters, which is what the articles tags are for.
+const (
+	tagsTag              = "tags"               // GET /api/tags
+	articlesTag          = "articles"           // lists without filters
+	articlesFavoritedTag = "articles:favorited" // lists filtered by favorited, whoever the user
+)
+
+func articleTag(slug string) string                 { return "article:" + slug }
+func userTag(username string) string                { return "user:" + username }
+func articlesByAuthorTag(username string) string    { return "articles:author:" + username }
+func articlesByTagTag(tag string) string            { return "articles:tag:" + tag }
+func articlesFavoritedByTag(username string) string { return "articles:favorited:" + username }
+
+// articlesMatchingTags returns the tags of the lists an article of author with tags matches,
+// which change when the article is created or deleted.
+func articlesMatchingTags(author string, tags []string) []string {
+	matching := []string{articlesTag, articlesByAuthorTag(author)}
+	for _, tag := range tags {
+		matching = append(matching, articlesByTagTag(tag))
+	}
+	return matching
+}
+
+// tagsCacheTags tags the response of GET /api/tags.
+func tagsCacheTags(*http.Request, []byte) []string {
+	return []string{tagsTag}
+}
+
+// articleCacheTags tags the response of GET /api/articles/{slug} with the article and its author.
+func articleCacheTags(_ *http.Request, body []byte) []string {
+	var res articleResponseBody
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil
+	}
+	return []string{articleTag(res.Article.Slug), userTag(res.Article.Author.Username)}
+}
+
+// articlesCacheTags tags the response of GET /api/articles with its filters, and the articles and authors it lists.
+func articlesCacheTags(r *http.Request, body []byte) []string {
+	var res articlesResponseBody
+	if err := json.Unmarshal(body, &res); err != nil {
+		return nil
+	}
+
+	query := r.URL.Query()
+	var tags []string
+	if author := query.Get("author"); author != "" {
+		tags = append(tags, articlesByAuthorTag(author), userTag(author))
+	}
+	if tag := query.Get("tag"); tag != "" {
+		tags = append(tags, articlesByTagTag(tag))
+	}
+	if favorited := query.Get("favorited"); favorited != "" {
+		tags = append(tags, articlesFavoritedTag, articlesFavoritedByTag(favorited), userTag(favorited))
+	}
+	if len(tags) == 0 {
+		tags = append(tags, articlesTag)
+	}
+	for _, article := range res.Articles {
+		tags = append(tags, articleTag(article.Slug), userTag(article.Author.Username))
+	}
+	return tags
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
+	"github.com/raeperd/realworld.go/internal/cache"
+)
+
+// TestCacheAnonymous_Invalidation reads through the response cache of the server started by [TestMain]
+// after each write that changes what anonymous clients see.
+func TestCacheAnonymous_Invalidation(t *testing.T) {
+	t.Parallel()
+
+	unique := fmt.Sprintf("%d", time.Now().UnixNano())
+	author, authorUser := registerUser(t, "cache_author")
+	reader, readerUser := registerUser(t, "cache_reader")
+	anonymous := newClient()
+	tag := "cache" + unique
+
+	created := createArticle(t, author, "Cached "+unique, tag)
+	article, err := anonymous.GetArticle(t.Context(), created.Slug)
+	test.Nil(t, err)
+	test.Equal(t, int64(0), article.FavoritesCount)
+	byTag, err := anonymous.GetArticles(t.Context(), client.ArticlesOptions{Tag: tag})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(byTag.Articles))
+	favorites, err := anonymous.GetArticles(t.Context(), client.ArticlesOptions{Favorited: readerUser.Username})
+	test.Nil(t, err)
+	test.Equal(t, 0, len(favorites.Articles))
+
+	// A new article shows up in the lists it matches, and its tags in the tag list
+	createArticle(t, author, "Cached second "+unique, tag)
+	byTag, err = anonymous.GetArticles(t.Context(), client.ArticlesOptions{Tag: tag})
+	test.Nil(t, err)
+	test.Equal(t, 2, len(byTag.Articles))
+	tags, err := anonymous.GetTags(t.Context())
+	test.Nil(t, err)
+	test.True(t, slices.Contains(tags, tag))
+
+	_, err = reader.Favorite(t.Context(), created.Slug)
+	test.Nil(t, err)
+	article, err = anonymous.GetArticle(t.Context(), created.Slug)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), article.FavoritesCount)
+	favorites, err = anonymous.GetArticles(t.Context(), client.ArticlesOptions{Favorited: readerUser.Username})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(favorites.Articles))
+
+	_, err = reader.AddComment(t.Context(), created.Slug, "Cached comment")
+	test.Nil(t, err)
+	article, err = anonymous.GetArticle(t.Context(), created.Slug)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), article.CommentsCount)
+
+	description := "Updated " + unique
+	_, err = author.UpdateArticle(t.Context(), created.Slug, client.UpdateArticle{Description: &description})
+	test.Nil(t, err)
+	article, err = anonymous.GetArticle(t.Context(), created.Slug)
+	test.Nil(t, err)
+	test.Equal(t, description, article.Description)
+
+	renamed := "cache_renamed_" + unique
+	_, err = author.UpdateUser(t.Context(), client.UpdateUser{Username: renamed})
+	test.Nil(t, err)
+	article, err = anonymous.GetArticle(t.Context(), created.Slug)
+	test.Nil(t, err)
+	test.Equal(t, renamed, article.Author.Username)
+	byAuthor, err := anonymous.GetArticles(t.Context(), client.ArticlesOptions{Author: authorUser.Username})
+	test.Nil(t, err)
+	test.Equal(t, 0, len(byAuthor.Articles))
+
+	test.Nil(t, author.DeleteArticle(t.Context(), created.Slug))
+	_, err = anonymous.GetArticle(t.Context(), created.Slug)
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
+	byTag, err = anonymous.GetArticles(t.Context(), client.ArticlesOptions{Tag: tag})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(byTag.Articles))
+	favorites, err = anonymous.GetArticles(t.Context(), client.ArticlesOptions{Favorited: readerUser.Username})
+	test.Nil(t, err)
+	test.Equal(t, 0, len(favorites.Articles))
+}
+
+func TestCacheAnonymous_Stats(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "cache.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	responses := newResponseCache(1<<20, time.Minute)
+	server := httptest.NewServer(route(slog.New(slog.NewTextHandler(io.Discard, nil)), "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret", responses: responses}))
+	t.Cleanup(server.Close)
+
+	anonymous := client.New(server.URL, client.WithHTTPClient(server.Client()))
+	for range 3 {
+		_, err := anonymous.GetTags(t.Context())
+		test.Nil(t, err)
+	}
+	_, err = anonymous.GetArticle(t.Context(), "missing")
+	test.Equal(t, http.StatusNotFound, client.StatusCode(err))
+	test.Equal(t, cache.Stats{Hits: 2, Misses: 2, Entries: 1, Bytes: responses.Stats().Bytes}, responses.Stats())
+
+	// Authenticated clients bypass the cache, since responses differ by user
+	user, err := anonymous.Register(t.Context(), client.NewUser{Username: "cached", Email: "cached@example.com", Password: "cachedpass"})
+	test.Nil(t, err)
+	authenticated := client.New(server.URL, client.WithHTTPClient(server.Client()), client.WithToken(user.Token))
+	_, err = authenticated.GetArticles(t.Context(), client.ArticlesOptions{})
+	test.Nil(t, err)
+	test.Equal(t, int64(2), responses.Stats().Misses)
+}
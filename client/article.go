@@ -0,0 +1,162 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Article is an article with its author.
+// Body is empty when the article comes from [Client.GetArticles] or [Client.GetFeed].
+type Article struct {
+	Slug           string    `json:"slug"`
+	Title          string    `json:"title"`
+	Description    string    `json:"description"`
+	Body           string    `json:"body"`
+	TagList        []string  `json:"tagList"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+	Favorited      bool      `json:"favorited"`
+	FavoritesCount int64     `json:"favoritesCount"`
+	CommentsCount  int64     `json:"commentsCount"`
+	Author         Profile   `json:"author"`
+}
+
+// Articles is a page of articles.
+// ArticlesCount is the number of articles matching the query, not the length of Articles, and zero with NoCount.
+// NextCursor continues after this page, and is empty on the last page.
+type Articles struct {
+	Articles      []Article `json:"articles"`
+	ArticlesCount int64     `json:"articlesCount"`
+	NextCursor    string    `json:"nextCursor"`
+}
+
+// NewArticle holds the fields to create an article.
+type NewArticle struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Body        string   `json:"body"`
+	TagList     []string `json:"tagList"`
+}
+
+// UpdateArticle holds the fields to update on an article. Nil fields are left unchanged.
+type UpdateArticle struct {
+	Title       *string `json:"title,omitempty"`
+	Description *string `json:"description,omitempty"`
+	Body        *string `json:"body,omitempty"`
+}
+
+// ArticlesOptions filters and paginates [Client.GetArticles]. Zero values are not sent.
+type ArticlesOptions struct {
+	Tag       string
+	Author    string
+	Favorited string
+	Limit     int
+	Offset    int
+	Cursor    string // NextCursor of the previous page, instead of Offset
+	NoCount   bool   // skips counting ArticlesCount
+}
+
+func (o ArticlesOptions) query() url.Values {
+	q := FeedOptions{Limit: o.Limit, Offset: o.Offset, Cursor: o.Cursor, NoCount: o.NoCount}.query()
+	if o.Tag != "" {
+		q.Set("tag", o.Tag)
+	}
+	if o.Author != "" {
+		q.Set("author", o.Author)
+	}
+	if o.Favorited != "" {
+		q.Set("favorited", o.Favorited)
+	}
+	return q
+}
+
+// FeedOptions paginates [Client.GetFeed]. Zero values are not sent.
+type FeedOptions struct {
+	Limit   int
+	Offset  int
+	Cursor  string // NextCursor of the previous page, instead of Offset
+	NoCount bool   // skips counting ArticlesCount
+}
+
+func (o FeedOptions) query() url.Values {
+	q := url.Values{}
+	if o.Limit != 0 {
+		q.Set("limit", strconv.Itoa(o.Limit))
+	}
+	if o.Offset != 0 {
+		q.Set("offset", strconv.Itoa(o.Offset))
+	}
+	if o.Cursor != "" {
+		q.Set("cursor", o.Cursor)
+	}
+	if o.NoCount {
+		q.Set("count", "false")
+	}
+	return q
+}
+
+type articleWrapper[T Article | NewArticle | UpdateArticle] struct {
+	Article T `json:"article"`
+}
+
+// GetArticles returns the most recent articles with GET /api/articles.
+func (c *Client) GetArticles(ctx context.Context, opts ArticlesOptions) (Articles, error) {
+	var res Articles
+	err := c.do(ctx, http.MethodGet, "/api/articles", opts.query(), nil, &res)
+	return res, err
+}
+
+// GetFeed returns the most recent articles by followed users with GET /api/articles/feed.
+func (c *Client) GetFeed(ctx context.Context, opts FeedOptions) (Articles, error) {
+	var res Articles
+	err := c.do(ctx, http.MethodGet, "/api/articles/feed", opts.query(), nil, &res)
+	return res, err
+}
+
+// CreateArticle creates an article with POST /api/articles.
+func (c *Client) CreateArticle(ctx context.Context, article NewArticle) (Article, error) {
+	var res articleWrapper[Article]
+	err := c.do(ctx, http.MethodPost, "/api/articles", nil, articleWrapper[NewArticle]{Article: article}, &res)
+	return res.Article, err
+}
+
+// GetArticle returns the article with slug with GET /api/articles/{slug}.
+func (c *Client) GetArticle(ctx context.Context, slug string) (Article, error) {
+	return c.article(ctx, http.MethodGet, slug, "")
+}
+
+// UpdateArticle updates the article with slug with PUT /api/articles/{slug}.
+// The slug of the returned article changes when the title does.
+func (c *Client) UpdateArticle(ctx context.Context, slug string, article UpdateArticle) (Article, error) {
+	var res articleWrapper[Article]
+	err := c.do(ctx, http.MethodPut, articlePath(slug), nil, articleWrapper[UpdateArticle]{Article: article}, &res)
+	return res.Article, err
+}
+
+// DeleteArticle deletes the article with slug with DELETE /api/articles/{slug}.
+func (c *Client) DeleteArticle(ctx context.Context, slug string) error {
+	return c.do(ctx, http.MethodDelete, articlePath(slug), nil, nil, nil)
+}
+
+// Favorite favorites the article with slug with POST /api/articles/{slug}/favorite.
+func (c *Client) Favorite(ctx context.Context, slug string) (Article, error) {
+	return c.article(ctx, http.MethodPost, slug, "/favorite")
+}
+
+// Unfavorite unfavorites the article with slug with DELETE /api/articles/{slug}/favorite.
+func (c *Client) Unfavorite(ctx context.Context, slug string) (Article, error) {
+	return c.article(ctx, http.MethodDelete, slug, "/favorite")
+}
+
+func (c *Client) article(ctx context.Context, method, slug, suffix string) (Article, error) {
+	var res articleWrapper[Article]
+	err := c.do(ctx, method, articlePath(slug)+suffix, nil, nil, &res)
+	return res.Article, err
+}
+
+func articlePath(slug string) string {
+	return "/api/articles/" + url.PathEscape(slug)
+}
@@ -0,0 +1,164 @@
+// Package client is a typed Go client for the RealWorld API served by this repository.
+//
+// A [Client] remembers the token returned by [Client.Register], [Client.Login] and [Client.UpdateUser],
+// and sends it as "Authorization: Token <jwt>" on every following request.
+// Non-2xx responses are returned as [*Error].
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// Client calls the RealWorld API at a base URL such as "http://localhost:8080".
+// It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu    sync.RWMutex
+	token string
+}
+
+// Option configures a [Client] created by [New].
+type Option func(*Client)
+
+// WithHTTPClient sets the [http.Client] used to send requests. It defaults to [http.DefaultClient].
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithToken sets the initial token, for example one stored from a previous login.
+func WithToken(token string) Option {
+	return func(c *Client) { c.token = token }
+}
+
+// New returns a [Client] for the API at baseURL.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Token returns the token sent with requests, or an empty string if the client is anonymous.
+func (c *Client) Token() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.token
+}
+
+// SetToken replaces the token sent with requests. An empty token makes the client anonymous.
+func (c *Client) SetToken(token string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.token = token
+}
+
+// Error is returned when the API responds with a non-2xx status.
+// Messages holds the "errors.body" entries of the response, if any.
+type Error struct {
+	StatusCode int
+	Messages   []string
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("realworld: %d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	if len(e.Messages) > 0 {
+		msg += ": " + strings.Join(e.Messages, "; ")
+	}
+	return msg
+}
+
+// StatusCode returns the HTTP status of an [*Error] in err's chain, or 0 if there is none.
+func StatusCode(err error) int {
+	var apiErr *Error
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// do sends a request with in encoded as JSON body, and decodes a successful response into out.
+// Both in and out may be nil.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token := c.Token(); token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = res.Body.Close() }()
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return decodeError(res)
+	}
+	if out == nil || res.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// decodeError builds an [*Error] from a response body in the form {"errors":{"body":["..."]}}.
+// Bodies in any other form, such as plain text from [http.Error], become a single message.
+func decodeError(res *http.Response) error {
+	apiErr := &Error{StatusCode: res.StatusCode}
+	b, err := io.ReadAll(res.Body)
+	if err != nil || len(b) == 0 {
+		return apiErr
+	}
+
+	var body struct {
+		Errors struct {
+			Body []string `json:"body"`
+		} `json:"errors"`
+	}
+	if err := json.Unmarshal(b, &body); err == nil && len(body.Errors.Body) > 0 {
+		apiErr.Messages = body.Errors.Body
+		return apiErr
+	}
+	if msg := strings.TrimSpace(string(b)); msg != "" {
+		apiErr.Messages = []string{msg}
+	}
+	return apiErr
+}
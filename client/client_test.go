@@ -0,0 +1,95 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
+)
+
+func TestClient_Login_StoresToken(t *testing.T) {
+	t.Parallel()
+
+	var gotAuthorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthorization = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/users/login":
+			_, _ = w.Write([]byte(`{"user":{"email":"jake@jake.jake","token":"jwt","username":"jake","bio":"","image":""}}`))
+		case "/api/user":
+			_, _ = w.Write([]byte(`{"user":{"email":"jake@jake.jake","token":"jwt2","username":"jake","bio":"","image":""}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	c := client.New(server.URL + "/")
+	user, err := c.Login(context.Background(), "jake@jake.jake", "jakejake")
+	test.Nil(t, err)
+	test.Equal(t, "", gotAuthorization)
+	test.Equal(t, "jake", user.Username)
+	test.Equal(t, "jwt", c.Token())
+
+	_, err = c.GetCurrentUser(context.Background())
+	test.Nil(t, err)
+	test.Equal(t, "Token jwt", gotAuthorization)
+}
+
+func TestClient_Error(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		status       int
+		body         string
+		wantMessages []string
+	}{
+		"json error body": {
+			status:       http.StatusUnprocessableEntity,
+			body:         `{"errors":{"body":["email is required","password is required"]}}`,
+			wantMessages: []string{"email is required", "password is required"},
+		},
+		"plain text body": {
+			status:       http.StatusBadRequest,
+			body:         "unexpected EOF\n",
+			wantMessages: []string{"unexpected EOF"},
+		},
+		"empty body": {
+			status: http.StatusUnauthorized,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			t.Cleanup(server.Close)
+
+			_, err := client.New(server.URL).GetTags(context.Background())
+			test.NotNil(t, err)
+			test.Equal(t, tc.status, client.StatusCode(err))
+
+			var apiErr *client.Error
+			test.True(t, errors.As(err, &apiErr))
+			test.Equal(t, len(tc.wantMessages), len(apiErr.Messages))
+			for i, msg := range tc.wantMessages {
+				test.Equal(t, msg, apiErr.Messages[i])
+			}
+		})
+	}
+}
+
+func TestStatusCode_NonAPIError(t *testing.T) {
+	t.Parallel()
+
+	test.Equal(t, 0, client.StatusCode(errors.New("network down")))
+	test.Equal(t, 0, client.StatusCode(nil))
+}
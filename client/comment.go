@@ -0,0 +1,47 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Comment is a comment on an article with its author.
+type Comment struct {
+	ID        int64     `json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Body      string    `json:"body"`
+	Author    Profile   `json:"author"`
+}
+
+type newComment struct {
+	Body string `json:"body"`
+}
+
+// GetComments returns the comments on the article with slug with GET /api/articles/{slug}/comments.
+func (c *Client) GetComments(ctx context.Context, slug string) ([]Comment, error) {
+	var res struct {
+		Comments []Comment `json:"comments"`
+	}
+	err := c.do(ctx, http.MethodGet, articlePath(slug)+"/comments", nil, nil, &res)
+	return res.Comments, err
+}
+
+// AddComment comments on the article with slug with POST /api/articles/{slug}/comments.
+func (c *Client) AddComment(ctx context.Context, slug, body string) (Comment, error) {
+	var res struct {
+		Comment Comment `json:"comment"`
+	}
+	req := struct {
+		Comment newComment `json:"comment"`
+	}{Comment: newComment{Body: body}}
+	err := c.do(ctx, http.MethodPost, articlePath(slug)+"/comments", nil, req, &res)
+	return res.Comment, err
+}
+
+// DeleteComment deletes the comment with id on the article with slug with DELETE /api/articles/{slug}/comments/{id}.
+func (c *Client) DeleteComment(ctx context.Context, slug string, id int64) error {
+	return c.do(ctx, http.MethodDelete, articlePath(slug)+"/comments/"+strconv.FormatInt(id, 10), nil, nil, nil)
+}
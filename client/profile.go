@@ -0,0 +1,43 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// Profile is the public view of a user.
+// Following reports whether the current user follows them, and is always false for anonymous clients.
+// FollowersCount is only set by the profile methods, not on the authors of articles and comments.
+type Profile struct {
+	Username       string `json:"username"`
+	Bio            string `json:"bio"`
+	Image          string `json:"image"`
+	Following      bool   `json:"following"`
+	FollowersCount int64  `json:"followersCount"`
+}
+
+type profileWrapper struct {
+	Profile Profile `json:"profile"`
+}
+
+// GetProfile returns the profile of username with GET /api/profiles/{username}.
+func (c *Client) GetProfile(ctx context.Context, username string) (Profile, error) {
+	return c.profile(ctx, http.MethodGet, username, "")
+}
+
+// Follow follows username with POST /api/profiles/{username}/follow.
+func (c *Client) Follow(ctx context.Context, username string) (Profile, error) {
+	return c.profile(ctx, http.MethodPost, username, "/follow")
+}
+
+// Unfollow unfollows username with DELETE /api/profiles/{username}/follow.
+func (c *Client) Unfollow(ctx context.Context, username string) (Profile, error) {
+	return c.profile(ctx, http.MethodDelete, username, "/follow")
+}
+
+func (c *Client) profile(ctx context.Context, method, username, suffix string) (Profile, error) {
+	var res profileWrapper
+	err := c.do(ctx, method, "/api/profiles/"+url.PathEscape(username)+suffix, nil, nil, &res)
+	return res.Profile, err
+}
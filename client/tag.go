@@ -0,0 +1,15 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// GetTags returns all tags with GET /api/tags.
+func (c *Client) GetTags(ctx context.Context) ([]string, error) {
+	var res struct {
+		Tags []string `json:"tags"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/tags", nil, nil, &res)
+	return res.Tags, err
+}
@@ -0,0 +1,89 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// User is the authenticated user returned by the user endpoints.
+type User struct {
+	Email    string `json:"email"`
+	Token    string `json:"token"`
+	Username string `json:"username"`
+	Bio      string `json:"bio"`
+	Image    string `json:"image"`
+}
+
+// NewUser holds the fields to register a user.
+type NewUser struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// UpdateUser holds the fields to update on the current user. Empty fields are left unchanged.
+type UpdateUser struct {
+	Email    string `json:"email,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	Bio      string `json:"bio,omitempty"`
+	Image    string `json:"image,omitempty"`
+}
+
+// LoginEvent is an attempt to log in to the current user, listed by [Client.GetLogins].
+type LoginEvent struct {
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Success   bool      `json:"success"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type userWrapper[T User | NewUser | UpdateUser | loginUser] struct {
+	User T `json:"user"`
+}
+
+type loginUser struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+// Register creates a user with POST /api/users and uses the returned token for following requests.
+func (c *Client) Register(ctx context.Context, user NewUser) (User, error) {
+	return c.authenticate(ctx, http.MethodPost, "/api/users", userWrapper[NewUser]{User: user})
+}
+
+// Login authenticates with POST /api/users/login and uses the returned token for following requests.
+func (c *Client) Login(ctx context.Context, email, password string) (User, error) {
+	return c.authenticate(ctx, http.MethodPost, "/api/users/login", userWrapper[loginUser]{User: loginUser{Email: email, Password: password}})
+}
+
+// GetCurrentUser returns the user of the current token with GET /api/user.
+func (c *Client) GetCurrentUser(ctx context.Context) (User, error) {
+	var res userWrapper[User]
+	err := c.do(ctx, http.MethodGet, "/api/user", nil, nil, &res)
+	return res.User, err
+}
+
+// GetLogins returns the latest logins to the current user, latest first, with GET /api/user/security/logins.
+func (c *Client) GetLogins(ctx context.Context) ([]LoginEvent, error) {
+	var res struct {
+		Logins []LoginEvent `json:"logins"`
+	}
+	err := c.do(ctx, http.MethodGet, "/api/user/security/logins", nil, nil, &res)
+	return res.Logins, err
+}
+
+// UpdateUser updates the current user with PUT /api/user and uses the refreshed token for following requests.
+func (c *Client) UpdateUser(ctx context.Context, user UpdateUser) (User, error) {
+	return c.authenticate(ctx, http.MethodPut, "/api/user", userWrapper[UpdateUser]{User: user})
+}
+
+func (c *Client) authenticate(ctx context.Context, method, path string, in any) (User, error) {
+	var res userWrapper[User]
+	if err := c.do(ctx, method, path, nil, in, &res); err != nil {
+		return User{}, err
+	}
+	c.SetToken(res.User.Token)
+	return res.User, nil
+}
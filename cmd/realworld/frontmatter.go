@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/raeperd/realworld.go/client"
+)
+
+const frontMatterDelimiter = "---"
+
+// frontMatter is the YAML header of a markdown post:
+//
+//	---
+//	title: How to train your dragon
+//	description: Ever wonder how?
+//	tags: [dragons, training]
+//	---
+//	You have to believe.
+type frontMatter struct {
+	Title       string   `yaml:"title"`
+	Description string   `yaml:"description"`
+	Tags        []string `yaml:"tags"`
+}
+
+// parseArticle splits a markdown post into its front matter and body.
+// The body is everything after the closing delimiter, without the leading blank lines.
+func parseArticle(src []byte) (client.NewArticle, error) {
+	src = bytes.ReplaceAll(src, []byte("\r\n"), []byte("\n"))
+	rest, ok := bytes.CutPrefix(src, []byte(frontMatterDelimiter+"\n"))
+	if !ok {
+		return client.NewArticle{}, errors.New("missing front matter: the file must start with " + frontMatterDelimiter)
+	}
+	header, body, ok := cutLine(rest, frontMatterDelimiter)
+	if !ok {
+		return client.NewArticle{}, errors.New("unterminated front matter: missing closing " + frontMatterDelimiter)
+	}
+
+	var fm frontMatter
+	if err := yaml.Unmarshal(header, &fm); err != nil {
+		return client.NewArticle{}, fmt.Errorf("parse front matter: %w", err)
+	}
+	if fm.Title == "" {
+		return client.NewArticle{}, errors.New("front matter: title is required")
+	}
+	if fm.Tags == nil {
+		fm.Tags = []string{}
+	}
+	return client.NewArticle{
+		Title:       fm.Title,
+		Description: fm.Description,
+		Body:        string(bytes.TrimLeft(body, "\n")),
+		TagList:     fm.Tags,
+	}, nil
+}
+
+// cutLine slices s around the first line equal to line, returning the text before and after it.
+func cutLine(s []byte, line string) (before, after []byte, found bool) {
+	for off := 0; off <= len(s); {
+		end := bytes.IndexByte(s[off:], '\n')
+		if end < 0 {
+			if string(s[off:]) == line {
+				return s[:off], nil, true
+			}
+			break
+		}
+		if string(s[off:off+end]) == line {
+			return s[:off], s[off+end+1:], true
+		}
+		off += end + 1
+	}
+	return s, nil, false
+}
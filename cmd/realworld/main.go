@@ -0,0 +1,278 @@
+// Command realworld is a command-line client for the RealWorld API.
+//
+// It logs in once, stores the token in a profile under the user config directory,
+// and reuses it for the following commands:
+//
+//	realworld -server http://localhost:8080 login -email jake@jake.jake
+//	realworld articles list -tag go
+//	realworld articles create -f post.md
+//	realworld comments add how-to-train-your-dragon "Nice post"
+//	realworld follow jake
+//	realworld -o json feed
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/raeperd/realworld.go/client"
+)
+
+func main() {
+	if err := run(context.Background(), os.Args, os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "%s\n", err)
+		os.Exit(1)
+	}
+}
+
+const defaultServer = "http://localhost:8080"
+
+const usage = `usage: %s [flags] <command> [args]
+
+commands:
+  login -email EMAIL [-password PASSWORD]     log in and store the token in the profile
+  logout                                      remove the token from the profile
+  articles list [-tag T] [-author U] [-favorited U] [-limit N] [-offset N]
+  articles get SLUG
+  articles create -f FILE                     publish a markdown file with front matter
+  comments list SLUG
+  comments add SLUG BODY
+  follow USERNAME
+  unfollow USERNAME
+  feed [-limit N] [-offset N]
+
+flags:
+`
+
+// run parses the global flags, loads the profile and dispatches to the command in args.
+// The password for login is read from stdin when -password is not given.
+func run(ctx context.Context, args []string, stdin io.Reader, w io.Writer) error {
+	var configPath, profileName, server, format string
+	fs := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	fs.SetOutput(w)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), usage, fs.Name())
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&configPath, "config", "", "profiles file (default realworld/profiles.json in the user config dir)")
+	fs.StringVar(&profileName, "profile", "default", "profile to read the server and token from")
+	fs.StringVar(&server, "server", "", "API base URL (default the profile's server or "+defaultServer+")")
+	fs.StringVar(&format, "o", formatTable, "output format: table or json")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	if format != formatTable && format != formatJSON {
+		return fmt.Errorf("unknown output format %q", format)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+
+	if configPath == "" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return fmt.Errorf("locate config dir: %w", err)
+		}
+		configPath = defaultProfilesPath(dir)
+	}
+	profiles, err := loadProfiles(configPath)
+	if err != nil {
+		return err
+	}
+	p := profiles[profileName]
+	if server != "" {
+		p.Server = server
+	}
+	if p.Server == "" {
+		p.Server = defaultServer
+	}
+
+	ctx, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	c := client.New(p.Server, client.WithToken(p.Token))
+	out := printer{w: w, format: format}
+	cmd, cmdArgs := fs.Arg(0), fs.Args()[1:]
+
+	switch cmd {
+	case "login":
+		user, err := login(ctx, c, cmdArgs, stdin)
+		if err != nil {
+			return err
+		}
+		p.Token = user.Token
+		profiles[profileName] = p
+		if err := saveProfiles(configPath, profiles); err != nil {
+			return err
+		}
+		return out.user(user)
+	case "logout":
+		p.Token = ""
+		profiles[profileName] = p
+		return saveProfiles(configPath, profiles)
+	case "articles":
+		return articles(ctx, c, out, cmdArgs)
+	case "comments":
+		return comments(ctx, c, out, cmdArgs)
+	case "follow", "unfollow":
+		username, err := exactArgs(cmd, cmdArgs, "USERNAME")
+		if err != nil {
+			return err
+		}
+		follow := c.Follow
+		if cmd == "unfollow" {
+			follow = c.Unfollow
+		}
+		profile, err := follow(ctx, username[0])
+		if err != nil {
+			return err
+		}
+		return out.profile(profile)
+	case "feed":
+		var opts client.FeedOptions
+		fs := flag.NewFlagSet("feed", flag.ContinueOnError)
+		fs.SetOutput(w)
+		fs.IntVar(&opts.Limit, "limit", 0, "maximum number of articles")
+		fs.IntVar(&opts.Offset, "offset", 0, "number of articles to skip")
+		if err := fs.Parse(cmdArgs); err != nil {
+			return err
+		}
+		list, err := c.GetFeed(ctx, opts)
+		if err != nil {
+			return err
+		}
+		return out.articles(list)
+	default:
+		fs.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+}
+
+func login(ctx context.Context, c *client.Client, args []string, stdin io.Reader) (client.User, error) {
+	var email, password string
+	fs := flag.NewFlagSet("login", flag.ContinueOnError)
+	fs.StringVar(&email, "email", "", "account email")
+	fs.StringVar(&password, "password", "", "account password (default read from stdin)")
+	if err := fs.Parse(args); err != nil {
+		return client.User{}, err
+	}
+	if email == "" {
+		return client.User{}, errors.New("login: -email is required")
+	}
+	if password == "" {
+		line, err := bufio.NewReader(stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return client.User{}, fmt.Errorf("read password: %w", err)
+		}
+		password = strings.TrimRight(line, "\r\n")
+	}
+	return c.Login(ctx, email, password)
+}
+
+func articles(ctx context.Context, c *client.Client, out printer, args []string) error {
+	if len(args) == 0 {
+		return errors.New("articles: missing subcommand: list, get or create")
+	}
+	switch sub, args := args[0], args[1:]; sub {
+	case "list":
+		var opts client.ArticlesOptions
+		fs := flag.NewFlagSet("articles list", flag.ContinueOnError)
+		fs.SetOutput(out.w)
+		fs.StringVar(&opts.Tag, "tag", "", "only articles with this tag")
+		fs.StringVar(&opts.Author, "author", "", "only articles by this username")
+		fs.StringVar(&opts.Favorited, "favorited", "", "only articles favorited by this username")
+		fs.IntVar(&opts.Limit, "limit", 0, "maximum number of articles")
+		fs.IntVar(&opts.Offset, "offset", 0, "number of articles to skip")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		list, err := c.GetArticles(ctx, opts)
+		if err != nil {
+			return err
+		}
+		return out.articles(list)
+	case "get":
+		slug, err := exactArgs("articles get", args, "SLUG")
+		if err != nil {
+			return err
+		}
+		article, err := c.GetArticle(ctx, slug[0])
+		if err != nil {
+			return err
+		}
+		return out.article(article)
+	case "create":
+		var path string
+		fs := flag.NewFlagSet("articles create", flag.ContinueOnError)
+		fs.SetOutput(out.w)
+		fs.StringVar(&path, "f", "", "markdown file with title, description and tags in front matter")
+		if err := fs.Parse(args); err != nil {
+			return err
+		}
+		if path == "" {
+			return errors.New("articles create: -f is required")
+		}
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		article, err := parseArticle(src)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		created, err := c.CreateArticle(ctx, article)
+		if err != nil {
+			return err
+		}
+		return out.article(created)
+	default:
+		return fmt.Errorf("articles: unknown subcommand %q", sub)
+	}
+}
+
+func comments(ctx context.Context, c *client.Client, out printer, args []string) error {
+	if len(args) == 0 {
+		return errors.New("comments: missing subcommand: list or add")
+	}
+	switch sub, args := args[0], args[1:]; sub {
+	case "list":
+		slug, err := exactArgs("comments list", args, "SLUG")
+		if err != nil {
+			return err
+		}
+		list, err := c.GetComments(ctx, slug[0])
+		if err != nil {
+			return err
+		}
+		return out.comments(list)
+	case "add":
+		slugBody, err := exactArgs("comments add", args, "SLUG", "BODY")
+		if err != nil {
+			return err
+		}
+		comment, err := c.AddComment(ctx, slugBody[0], slugBody[1])
+		if err != nil {
+			return err
+		}
+		return out.comments([]client.Comment{comment})
+	default:
+		return fmt.Errorf("comments: unknown subcommand %q", sub)
+	}
+}
+
+// exactArgs returns args if it has one value per name, and a usage error naming them otherwise.
+func exactArgs(cmd string, args []string, names ...string) ([]string, error) {
+	if len(args) != len(names) {
+		return nil, fmt.Errorf("usage: %s %s", cmd, strings.Join(names, " "))
+	}
+	return args, nil
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
+)
+
+func TestRun_LoginStoresTokenForNextCommands(t *testing.T) {
+	t.Parallel()
+
+	var gotAuth, gotTag string
+	var created client.NewArticle
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/users/login", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			User struct {
+				Email    string `json:"email"`
+				Password string `json:"password"`
+			} `json:"user"`
+		}
+		test.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		test.Equal(t, "jake@jake.jake", req.User.Email)
+		test.Equal(t, "jakejake", req.User.Password)
+		_, _ = w.Write([]byte(`{"user":{"email":"jake@jake.jake","username":"jake","token":"jwt"}}`))
+	})
+	mux.HandleFunc("GET /api/articles", func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotTag = r.Header.Get("Authorization"), r.URL.Query().Get("tag")
+		_, _ = w.Write([]byte(`{"articles":[{"slug":"go-tips","title":"Go tips","tagList":["go"],"author":{"username":"jake"}}],"articlesCount":1}`))
+	})
+	mux.HandleFunc("POST /api/articles", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Article client.NewArticle `json:"article"`
+		}
+		test.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		created = req.Article
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(`{"article":{"slug":"go-tips","title":"Go tips","author":{"username":"jake"}}}`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	dir := t.TempDir()
+	config := filepath.Join(dir, "profiles.json")
+	realworld := func(stdin string, args ...string) (string, error) {
+		var out bytes.Buffer
+		args = append([]string{"realworld", "-config", config}, args...)
+		err := run(t.Context(), args, strings.NewReader(stdin), &out)
+		return out.String(), err
+	}
+
+	// Login reads the password from stdin and stores the server and token
+	out, err := realworld("jakejake\n", "-server", server.URL, "login", "-email", "jake@jake.jake")
+	test.Nil(t, err)
+	test.Contains(t, out, "jake@jake.jake")
+
+	profiles, err := loadProfiles(config)
+	test.Nil(t, err)
+	test.Equal(t, profile{Server: server.URL, Token: "jwt"}, profiles["default"])
+
+	// Following commands reuse the profile without -server
+	out, err = realworld("", "articles", "list", "-tag", "go")
+	test.Nil(t, err)
+	test.Equal(t, "Token jwt", gotAuth)
+	test.Equal(t, "go", gotTag)
+	test.Contains(t, out, "SLUG")
+	test.Contains(t, out, "go-tips")
+
+	out, err = realworld("", "-o", "json", "articles", "list")
+	test.Nil(t, err)
+	var list client.Articles
+	test.Nil(t, json.Unmarshal([]byte(out), &list))
+	test.Equal(t, int64(1), list.ArticlesCount)
+
+	post := filepath.Join(dir, "post.md")
+	test.Nil(t, os.WriteFile(post, []byte("---\ntitle: Go tips\ndescription: Small ones\ntags: [go, tips]\n---\n\n# Tips\n"), 0o600))
+	_, err = realworld("", "articles", "create", "-f", post)
+	test.Nil(t, err)
+	test.Equal(t, "Go tips", created.Title)
+	test.Equal(t, "Small ones", created.Description)
+	test.Equal(t, "# Tips\n", created.Body)
+	test.Equal(t, 2, len(created.TagList))
+
+	// Logout forgets the token but keeps the server
+	_, err = realworld("", "logout")
+	test.Nil(t, err)
+	profiles, err = loadProfiles(config)
+	test.Nil(t, err)
+	test.Equal(t, profile{Server: server.URL}, profiles["default"])
+}
+
+func TestSaveProfiles(t *testing.T) {
+	t.Parallel()
+
+	// A file holding null loads as no profiles, which can be added to
+	config := filepath.Join(t.TempDir(), "profiles.json")
+	test.Nil(t, os.WriteFile(config, []byte("null\n"), 0o644))
+	profiles, err := loadProfiles(config)
+	test.Nil(t, err)
+	profiles["default"] = profile{Server: "http://localhost:8080", Token: "jwt"}
+
+	// Saving tightens the mode of the existing file, since it holds tokens
+	test.Nil(t, saveProfiles(config, profiles))
+	info, err := os.Stat(config)
+	test.Nil(t, err)
+	test.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	profiles, err = loadProfiles(config)
+	test.Nil(t, err)
+	test.Equal(t, "jwt", profiles["default"].Token)
+}
+
+func TestRun_Errors(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string][]string{
+		"missing command":       {},
+		"unknown command":       {"publish"},
+		"unknown output format": {"-o", "yaml", "feed"},
+		"follow without user":   {"follow"},
+		"create without file":   {"articles", "create"},
+		"comments add too few":  {"comments", "add", "slug"},
+	}
+
+	for name, args := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			args = append([]string{"realworld", "-config", filepath.Join(t.TempDir(), "profiles.json")}, args...)
+			test.NotNil(t, run(t.Context(), args, strings.NewReader(""), &bytes.Buffer{}))
+		})
+	}
+}
+
+func TestParseArticle(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		src  string
+		want client.NewArticle
+		err  bool
+	}{
+		"full": {
+			src:  "---\ntitle: Title\ndescription: Desc\ntags:\n  - a\n  - b\n---\nBody\n",
+			want: client.NewArticle{Title: "Title", Description: "Desc", Body: "Body\n", TagList: []string{"a", "b"}},
+		},
+		"crlf and no tags": {
+			src:  "---\r\ntitle: Title\r\n---\r\n\r\nBody",
+			want: client.NewArticle{Title: "Title", Body: "Body", TagList: []string{}},
+		},
+		"horizontal rule in body": {
+			src:  "---\ntitle: Title\n---\nabove\n---\nbelow",
+			want: client.NewArticle{Title: "Title", Body: "above\n---\nbelow", TagList: []string{}},
+		},
+		"no front matter":    {src: "# Title\n", err: true},
+		"unterminated":       {src: "---\ntitle: Title\n", err: true},
+		"missing title":      {src: "---\ndescription: Desc\n---\nBody", err: true},
+		"invalid yaml":       {src: "---\ntitle: [\n---\nBody", err: true},
+		"empty front matter": {src: "---\n---\nBody", err: true},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parseArticle([]byte(tc.src))
+			if tc.err {
+				test.NotNil(t, err)
+				return
+			}
+			test.Nil(t, err)
+			test.DeepEqual(t, tc.want, got)
+		})
+	}
+}
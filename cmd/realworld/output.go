@@ -0,0 +1,90 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/raeperd/realworld.go/client"
+)
+
+const (
+	formatTable = "table"
+	formatJSON  = "json"
+)
+
+// printer writes command results as an aligned table for people or as indented JSON for scripts.
+type printer struct {
+	w      io.Writer
+	format string
+}
+
+func (p printer) user(user client.User) error {
+	return p.print(user, []string{"USERNAME", "EMAIL"}, [][]string{{user.Username, user.Email}})
+}
+
+func (p printer) profile(profile client.Profile) error {
+	return p.print(profile, []string{"USERNAME", "FOLLOWING"}, [][]string{{profile.Username, fmt.Sprint(profile.Following)}})
+}
+
+func (p printer) article(article client.Article) error {
+	return p.print(article, articleHeader, [][]string{articleRow(article)})
+}
+
+func (p printer) articles(list client.Articles) error {
+	rows := make([][]string, 0, len(list.Articles))
+	for _, article := range list.Articles {
+		rows = append(rows, articleRow(article))
+	}
+	return p.print(list, articleHeader, rows)
+}
+
+var articleHeader = []string{"SLUG", "TITLE", "AUTHOR", "TAGS", "FAVORITES", "CREATED"}
+
+func articleRow(article client.Article) []string {
+	return []string{
+		article.Slug,
+		article.Title,
+		article.Author.Username,
+		strings.Join(article.TagList, ","),
+		fmt.Sprint(article.FavoritesCount),
+		article.CreatedAt.Format(time.DateTime),
+	}
+}
+
+func (p printer) comments(list []client.Comment) error {
+	rows := make([][]string, 0, len(list))
+	for _, comment := range list {
+		rows = append(rows, []string{
+			fmt.Sprint(comment.ID),
+			comment.Author.Username,
+			comment.CreatedAt.Format(time.DateTime),
+			comment.Body,
+		})
+	}
+	return p.print(list, []string{"ID", "AUTHOR", "CREATED", "BODY"}, rows)
+}
+
+// print writes v as JSON, or header and rows as a table.
+// Table cells are cut to their first line so multi-line text does not break the alignment.
+func (p printer) print(v any, header []string, rows [][]string) error {
+	if p.format == formatJSON {
+		enc := json.NewEncoder(p.w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+
+	tw := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i], _, _ = strings.Cut(cell, "\n")
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
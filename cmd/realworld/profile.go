@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// profile is a server and the token of the user logged in to it.
+type profile struct {
+	Server string `json:"server"`
+	Token  string `json:"token,omitempty"`
+}
+
+// defaultProfilesPath returns the profiles file inside the user config directory dir.
+func defaultProfilesPath(dir string) string {
+	return filepath.Join(dir, "realworld", "profiles.json")
+}
+
+// loadProfiles reads profiles by name from path. A missing file yields no profiles.
+func loadProfiles(path string) (map[string]profile, error) {
+	profiles := map[string]profile{}
+	b, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(b, &profiles); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	if profiles == nil { // the file held null
+		profiles = map[string]profile{}
+	}
+	return profiles, nil
+}
+
+// saveProfiles writes profiles to path, readable only by the current user since they hold tokens.
+// The mode of an existing file is tightened too, as [os.WriteFile] only applies it to new files.
+func saveProfiles(path string, profiles map[string]profile) error {
+	b, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o600); err != nil {
+		return err
+	}
+	return os.Chmod(path, 0o600)
+}
@@ -6,11 +6,13 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
-	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
+	"github.com/raeperd/realworld.go/internal/validate"
 )
 
-func handlePostArticlesSlugComments(db *sql.DB) http.HandlerFunc {
+func handlePostArticlesSlugComments(db store.Store, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var request commentPostRequestBody
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -34,17 +36,15 @@ func handlePostArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 		// Get article slug from URL path
 		slug := r.PathValue("slug")
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-
 		// Get article by slug to verify it exists
-		article, err := queries.GetArticleBySlug(r.Context(), slug)
+		article, err := tx.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -55,7 +55,7 @@ func handlePostArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Create comment
-		comment, err := queries.CreateComment(r.Context(), sqlite.CreateCommentParams{
+		comment, err := tx.CreateComment(r.Context(), store.CreateCommentParams{
 			Body:      request.Comment.Body,
 			ArticleID: article.ID,
 			AuthorID:  userID,
@@ -66,7 +66,7 @@ func handlePostArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get comment with author details
-		commentWithAuthor, err := queries.GetCommentWithAuthor(r.Context(), comment.ID)
+		commentWithAuthor, err := tx.GetCommentWithAuthor(r.Context(), comment.ID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
@@ -75,7 +75,8 @@ func handlePostArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 		// Check if current user is following the comment author
 		following := false
 		if userID != commentWithAuthor.AuthorID {
-			isFollowingInt, err := queries.IsFollowing(r.Context(), sqlite.IsFollowingParams{
+			var err error
+			following, err = tx.IsFollowing(r.Context(), store.IsFollowingParams{
 				FollowerID: userID,
 				FollowedID: commentWithAuthor.AuthorID,
 			})
@@ -83,13 +84,14 @@ func handlePostArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 				return
 			}
-			following = isFollowingInt == 1
 		}
 
 		if err := tx.Commit(); err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		commentsCreated.Inc()
+		responses.Invalidate(articleTag(article.Slug)) // for its comments count
 
 		// Build response
 		response := commentResponseBody{
@@ -107,6 +109,7 @@ func handlePostArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 			},
 		}
 
+		w.Header().Set("ETag", commentETag(commentWithAuthor.ID, commentWithAuthor.Body, commentWithAuthor.UpdatedAt))
 		encodeResponse(r.Context(), http.StatusCreated, response, w)
 	}
 }
@@ -116,13 +119,14 @@ type commentPostRequestBody struct {
 }
 
 func (r commentPostRequestBody) Validate() []error {
-	var errs []error
-	if r.Comment.Body == "" {
-		errs = append(errs, errors.New("body is required"))
-	}
-	return errs
+	return validate.Errors(
+		validate.Field("body", r.Comment.Body, validate.Required, validate.MaxLength(maxCommentBodyLength)),
+	)
 }
 
+// maxCommentBodyLength is the limit for comment bodies, also documented in api/openapi.yaml.
+const maxCommentBodyLength = 10000
+
 type commentPostRequest struct {
 	Body string `json:"body"`
 }
@@ -139,7 +143,7 @@ type commentPayload struct {
 	Author    authorProfile `json:"author"`
 }
 
-func handleGetArticlesSlugComments(db *sql.DB) http.HandlerFunc {
+func handleGetArticlesSlugComments(db store.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Get article slug from URL path
 		slug := r.PathValue("slug")
@@ -147,10 +151,8 @@ func handleGetArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 		// Get optional user ID from context (for following status)
 		userID, _ := r.Context().Value(userIDKey).(int64)
 
-		queries := sqlite.New(db)
-
 		// Verify article exists first
-		_, err := queries.GetArticleBySlug(r.Context(), slug)
+		_, err := db.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -161,7 +163,7 @@ func handleGetArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get comments by article slug
-		comments, err := queries.GetCommentsByArticleSlug(r.Context(), slug)
+		comments, err := db.GetCommentsByArticleSlug(r.Context(), slug)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
@@ -186,7 +188,7 @@ func handleGetArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 				}
 
 				// Single batch query to get all following relationships
-				followedIDs, err := queries.GetFollowingByIDs(r.Context(), sqlite.GetFollowingByIDsParams{
+				followedIDs, err := db.GetFollowingByIDs(r.Context(), store.GetFollowingByIDsParams{
 					FollowerID:  userID,
 					FollowedIds: authorIDs,
 				})
@@ -226,15 +228,29 @@ func handleGetArticlesSlugComments(db *sql.DB) http.HandlerFunc {
 			Comments: commentPayloads,
 		}
 
+		// Deleting a comment does not leave a time behind, so the list has an ETag but no Last-Modified
+		if notModified(w, r, etag(response), time.Time{}) {
+			return
+		}
 		encodeResponse(r.Context(), http.StatusOK, response, w)
 	}
 }
 
+// commentETag is the strong entity tag of a comment, which If-Match of DELETE compares with.
+// It covers the comment but not its author, since the comment alone is what DELETE removes.
+func commentETag(id int64, body string, updatedAt time.Time) string {
+	return strongETag(struct {
+		ID        int64
+		Body      string
+		UpdatedAt int64
+	}{id, body, updatedAt.UnixNano()})
+}
+
 type commentsResponseBody struct {
 	Comments []commentPayload `json:"comments"`
 }
 
-func handleDeleteArticlesSlugCommentsID(db *sql.DB) http.HandlerFunc {
+func handleDeleteArticlesSlugCommentsID(db store.Store, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		slug := r.PathValue("slug")
 		commentIDStr := r.PathValue("id")
@@ -253,10 +269,8 @@ func handleDeleteArticlesSlugCommentsID(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		queries := sqlite.New(db)
-
 		// Verify article exists
-		article, err := queries.GetArticleBySlug(r.Context(), slug)
+		article, err := db.GetArticleBySlug(r.Context(), slug)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("article not found")}, w)
@@ -267,7 +281,7 @@ func handleDeleteArticlesSlugCommentsID(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Get comment by ID
-		comment, err := queries.GetCommentByID(r.Context(), commentID)
+		comment, err := db.GetCommentByID(r.Context(), commentID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("comment not found")}, w)
@@ -289,12 +303,20 @@ func handleDeleteArticlesSlugCommentsID(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		// Refuse the delete if the client's copy of the comment is out of date
+		if !ifMatch(w, r, "comment", func() (string, error) {
+			return commentETag(comment.ID, comment.Body, comment.UpdatedAt), nil
+		}) {
+			return
+		}
+
 		// Delete the comment
-		err = queries.DeleteComment(r.Context(), commentID)
+		err = db.DeleteComment(r.Context(), commentID)
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		responses.Invalidate(articleTag(article.Slug)) // for its comments count
 
 		// Return 204 No Content
 		w.WriteHeader(http.StatusNoContent)
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// envPrefix prefixes the environment variable of each flag, such as REALWORLD_JWT_SECRET for -jwt-secret.
+const envPrefix = "REALWORLD_"
+
+// defaultJWTSecret is the default of -jwt-secret, which the server only accepts in -dev mode.
+const defaultJWTSecret = "default-secret"
+
+// redactedFlags hold secrets that "config print -redacted" leaves out.
+var redactedFlags = []string{"jwt-secret", "admin-token"}
+
+// loadConfig sets the flags of fs that are not set on the command line from the environment, or else from the
+// config file at -config. So a flag takes, in order of precedence, its command line value, its [envFlag] variable,
+// its key in the config file, and its default. The config file is TOML, YAML or JSON by its extension,
+// with a key for each flag, as printed by "config print".
+func loadConfig(fs *flag.FlagSet, lookupEnv func(string) (string, bool)) error {
+	explicit := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { explicit[f.Name] = true })
+
+	path := fs.Lookup("config").Value.String()
+	if env, ok := lookupEnv(envFlag("config")); ok && !explicit["config"] {
+		path = env
+	}
+	if path != "" {
+		values, err := readConfigFile(path)
+		if err != nil {
+			return fmt.Errorf("config file %s: %w", path, err)
+		}
+		for key, value := range values {
+			name := strings.ReplaceAll(key, "_", "-")
+			if fs.Lookup(name) == nil || name == "config" {
+				return fmt.Errorf("config file %s: unknown key %q", path, key)
+			}
+			if explicit[name] {
+				continue
+			}
+			if err := fs.Set(name, value); err != nil {
+				return fmt.Errorf("config file %s: %s: %w", path, key, err)
+			}
+		}
+	}
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		env, ok := lookupEnv(envFlag(f.Name))
+		if !ok || explicit[f.Name] || f.Name == "config" || err != nil {
+			return
+		}
+		if setErr := fs.Set(f.Name, env); setErr != nil {
+			err = fmt.Errorf("%s: %w", envFlag(f.Name), setErr)
+		}
+	})
+	return err
+}
+
+// envFlag returns the environment variable of the flag name.
+func envFlag(name string) string {
+	return envPrefix + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// readConfigFile reads the flag values of the config file at path, keyed by flag name.
+// Lists are joined with commas, as flags such as -cors-origins take them.
+func readConfigFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var values map[string]any
+	switch ext := filepath.Ext(path); ext {
+	case ".toml":
+		return parseTOML(b)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &values)
+	case ".json":
+		dec := json.NewDecoder(bytes.NewReader(b))
+		dec.UseNumber()
+		err = dec.Decode(&values)
+	default:
+		return nil, fmt.Errorf("unknown config file extension %q, want .toml, .yaml, .yml or .json", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	config := make(map[string]string, len(values))
+	for key, value := range values {
+		switch value := value.(type) {
+		case map[string]any:
+			return nil, fmt.Errorf("%s: nested keys are not supported", key)
+		case []any:
+			items := make([]string, len(value))
+			for i, item := range value {
+				items[i] = fmt.Sprint(item)
+			}
+			config[key] = strings.Join(items, ",")
+		case nil:
+			config[key] = ""
+		default:
+			config[key] = fmt.Sprint(value)
+		}
+	}
+	return config, nil
+}
+
+// parseTOML parses the top-level "key = value" pairs of a TOML document, which is all a config file has.
+// Values are strings, numbers, booleans, or arrays of them on one line.
+func parseTOML(b []byte) (map[string]string, error) {
+	config := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(b))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			return nil, fmt.Errorf("line %d: tables are not supported", n)
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: want key = value", n)
+		}
+		key = strings.Trim(strings.TrimSpace(key), `"`)
+
+		value = strings.TrimSpace(value)
+		if strings.HasPrefix(value, "[") {
+			list, rest, ok := strings.Cut(value[1:], "]")
+			if !ok || !isTOMLComment(rest) {
+				return nil, fmt.Errorf("line %d: arrays must be on one line", n)
+			}
+			var items []string
+			for item := range strings.SplitSeq(list, ",") {
+				if item = strings.TrimSpace(item); item == "" {
+					continue
+				}
+				s, _, err := parseTOMLValue(item)
+				if err != nil {
+					return nil, fmt.Errorf("line %d: %w", n, err)
+				}
+				items = append(items, s)
+			}
+			config[key] = strings.Join(items, ",")
+			continue
+		}
+		s, rest, err := parseTOMLValue(value)
+		if err != nil || !isTOMLComment(rest) {
+			return nil, fmt.Errorf("line %d: invalid value %s", n, value)
+		}
+		config[key] = s
+	}
+	return config, scanner.Err()
+}
+
+// parseTOMLValue parses the string, number or boolean at the start of s, and returns what follows it.
+func parseTOMLValue(s string) (value, rest string, err error) {
+	switch {
+	case strings.HasPrefix(s, `"`):
+		for i := 1; i < len(s); i++ {
+			if s[i] == '\\' {
+				i++
+				continue
+			}
+			if s[i] == '"' {
+				value, err := strconv.Unquote(s[:i+1])
+				return value, s[i+1:], err
+			}
+		}
+		return "", "", errors.New("unterminated string")
+	case strings.HasPrefix(s, "'"):
+		value, rest, ok := strings.Cut(s[1:], "'")
+		if !ok {
+			return "", "", errors.New("unterminated string")
+		}
+		return value, rest, nil
+	default:
+		value, rest, _ := strings.Cut(s, "#")
+		if rest != "" {
+			rest = "#" + rest
+		}
+		return strings.TrimSpace(value), rest, nil
+	}
+}
+
+func isTOMLComment(s string) bool {
+	s = strings.TrimSpace(s)
+	return s == "" || strings.HasPrefix(s, "#")
+}
+
+// runConfig runs the config commands, which print the configuration loaded by [loadConfig] to w.
+func runConfig(w io.Writer, config *flag.FlagSet, args []string) error {
+	if len(args) < 2 || args[1] != "print" {
+		return fmt.Errorf("unknown config command, available commands:\n  config print [-redacted]")
+	}
+	fs := flag.NewFlagSet("config print", flag.ContinueOnError)
+	fs.SetOutput(w)
+	redacted := fs.Bool("redacted", false, "leave out secrets, and passwords of database connection strings")
+	if err := parseAdminArgs(fs, args[2:]); err != nil {
+		return err
+	}
+
+	var err error
+	config.VisitAll(func(f *flag.Flag) {
+		if f.Name == "config" || err != nil {
+			return
+		}
+		value := f.Value.String()
+		if *redacted {
+			value = redact(f.Name, value)
+		}
+		_, err = fmt.Fprintf(w, "%s = %s\n", f.Name, tomlValue(value))
+	})
+	return err
+}
+
+// redact returns value of the flag name without the secrets in it.
+func redact(name, value string) string {
+	for _, secret := range redactedFlags {
+		if name == secret && value != "" {
+			return "REDACTED"
+		}
+	}
+	if name == "db" {
+		return redactDSN(value)
+	}
+	return value
+}
+
+// dsnPassword matches the password of a key=value connection string such as "host=db password='a b'",
+// where values with spaces are quoted and quotes in them escaped with a backslash.
+var dsnPassword = regexp.MustCompile(`(\bpassword\s*=\s*)('(?:[^'\\]|\\.)*'|\S*)`)
+
+// redactDSN returns the connection string dsn without its passwords, either in a URL, where they can be in the
+// user info and the password query parameter, or in key=value pairs.
+func redactDSN(dsn string) string {
+	if !strings.Contains(dsn, "://") {
+		return dsnPassword.ReplaceAllString(dsn, "${1}REDACTED")
+	}
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn
+	}
+	if u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), "REDACTED")
+		}
+	}
+	if query := u.Query(); query.Has("password") {
+		query.Set("password", "REDACTED")
+		u.RawQuery = query.Encode()
+	}
+	return u.String()
+}
+
+// tomlValue returns s as TOML: booleans and integers as they are, and anything else as a string.
+func tomlValue(s string) string {
+	if s == "true" || s == "false" {
+		return s
+	}
+	if _, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return s
+	}
+	return strconv.Quote(s)
+}
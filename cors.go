@@ -0,0 +1,154 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsPolicy tells browsers which origins may call the API, see [cors].
+type corsPolicy struct {
+	origins     []string // exact origins, patterns with one "*" such as https://*.example.com, or "*" for any origin
+	credentials bool     // whether requests may carry cookies and Authorization set by the browser
+	maxAge      time.Duration
+}
+
+// Methods, request headers and response headers of the API that cross-origin requests may use.
+var (
+	corsMethods        = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodDelete}
+	corsRequestHeaders = []string{
+		"Authorization", "Content-Type", "Idempotency-Key", "If-Match", "If-Modified-Since", "If-None-Match",
+		"Traceparent", "X-Request-ID",
+	}
+	corsExposedHeaders = []string{
+		"ETag", "Article-ETag", "Last-Modified", "Idempotent-Replayed", "Retry-After", "X-Request-ID",
+		"RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "RateLimit-Policy",
+	}
+)
+
+// newCORSPolicy returns the policy for the comma-separated origins, which must each be "*", or a scheme and host
+// such as https://example.com with at most one "*" standing for any part of the host or port.
+// With credentials, no origin may allow any site, as "*" and https://* do, since any site could then act as its users.
+func newCORSPolicy(origins string, credentials bool, maxAge time.Duration) (*corsPolicy, error) {
+	policy := &corsPolicy{credentials: credentials, maxAge: maxAge}
+	for origin := range strings.SplitSeq(origins, ",") {
+		origin = strings.TrimSpace(origin)
+		if origin == "" {
+			continue
+		}
+		scheme, host, ok := strings.Cut(origin, "://")
+		if origin != "*" && (!ok || scheme == "" || host == "" || strings.Contains(host, "/") || strings.Count(origin, "*") > 1) {
+			return nil, fmt.Errorf("invalid CORS origin %q, want * or a scheme and host such as https://*.example.com", origin)
+		}
+		if hostname, _, _ := strings.Cut(host, ":"); credentials && (origin == "*" || hostname == "*") {
+			return nil, fmt.Errorf("CORS origin %q allows any site, which can't be given credentials", origin)
+		}
+		policy.origins = append(policy.origins, strings.TrimSuffix(origin, "/"))
+	}
+	return policy, nil
+}
+
+// anyOrigin reports whether every origin is allowed without credentials, so responses can be shared with "*".
+func (p *corsPolicy) anyOrigin() bool {
+	return !p.credentials && slices.Contains(p.origins, "*")
+}
+
+// allows reports whether origin matches one of the origins of the policy.
+func (p *corsPolicy) allows(origin string) bool {
+	if origin == "" {
+		return false
+	}
+	for _, allowed := range p.origins {
+		before, after, wildcard := strings.Cut(allowed, "*")
+		if allowed == "*" {
+			return true
+		}
+		if !wildcard {
+			if origin == allowed {
+				return true
+			}
+			continue
+		}
+		if len(origin) > len(before)+len(after) && strings.HasPrefix(origin, before) && strings.HasSuffix(origin, after) &&
+			!strings.Contains(origin[len(before):len(origin)-len(after)], "/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cors is a middleware that handles CORS (Cross-Origin Resource Sharing) for the API by policy.
+// A nil policy allows all origins without credentials, which is what RealWorld frontends expect.
+// Preflight requests from allowed origins are answered with the requested method and headers only when the API
+// accepts them, and other requests carry the headers the client may read. Responses that depend on the Origin
+// header say so with Vary, so caches don't share them between origins.
+func cors(next http.Handler, policy *corsPolicy) http.Handler {
+	if policy == nil {
+		policy = &corsPolicy{origins: []string{"*"}, maxAge: 10 * time.Minute}
+	}
+	exposed := strings.Join(corsExposedHeaders, ", ")
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && origin != "" && r.Header.Get("Access-Control-Request-Method") != ""
+		if !policy.anyOrigin() {
+			w.Header().Add("Vary", "Origin")
+		}
+		if preflight {
+			w.Header().Add("Vary", "Access-Control-Request-Method")
+			w.Header().Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		allowed := policy.allows(origin)
+		switch {
+		case policy.anyOrigin():
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		case allowed:
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			if policy.credentials {
+				w.Header().Set("Access-Control-Allow-Credentials", "true")
+			}
+		}
+
+		if !preflight {
+			if allowed || policy.anyOrigin() {
+				w.Header().Set("Access-Control-Expose-Headers", exposed)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// A preflight always succeeds, and the browser refuses the actual request unless it got the allow headers
+		if allowed && slices.Contains(corsMethods, r.Header.Get("Access-Control-Request-Method")) {
+			if headers, ok := allowedHeaders(r.Header.Values("Access-Control-Request-Headers")); ok {
+				w.Header().Set("Access-Control-Allow-Methods", r.Header.Get("Access-Control-Request-Method"))
+				if headers != "" {
+					w.Header().Set("Access-Control-Allow-Headers", headers)
+				}
+				w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(policy.maxAge/time.Second)))
+			}
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowedHeaders returns the headers of Access-Control-Request-Headers values, comma-separated,
+// or false if any of them is not one of [corsRequestHeaders].
+func allowedHeaders(values []string) (string, bool) {
+	var headers []string
+	for _, value := range values {
+		for header := range strings.SplitSeq(value, ",") {
+			header = strings.TrimSpace(header)
+			if header == "" {
+				continue
+			}
+			if !slices.ContainsFunc(corsRequestHeaders, func(h string) bool { return strings.EqualFold(h, header) }) {
+				return "", false
+			}
+			headers = append(headers, strings.ToLower(header))
+		}
+	}
+	return strings.Join(headers, ", "), true
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+)
+
+func TestCORSPolicy_Allows(t *testing.T) {
+	t.Parallel()
+
+	policy, err := newCORSPolicy("https://example.com, https://*.example.com,http://localhost:*", false, time.Minute)
+	test.Nil(t, err)
+	for origin, want := range map[string]bool{
+		"https://example.com":         true,
+		"https://app.example.com":     true,
+		"https://a.b.example.com":     true,
+		"http://localhost:3000":       true,
+		"":                            false,
+		"http://example.com":          false,
+		"https://example.com.evil.io": false,
+		"https://evilexample.com":     false,
+		"https://.example.com":        false,
+		"http://localhost":            false,
+	} {
+		test.Equal(t, want, policy.allows(origin))
+	}
+
+	for _, origins := range []string{"example.com", "https://", "https://*.*.example.com", "https://example.com/path"} {
+		_, err := newCORSPolicy(origins, false, time.Minute)
+		test.NotNil(t, err)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	t.Parallel()
+
+	handler := func(policy *corsPolicy) http.Handler {
+		return cors(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) { w.WriteHeader(http.StatusOK) }), policy)
+	}
+	serve := func(h http.Handler, method string, header ...string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/articles", nil)
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	credentials, err := newCORSPolicy("https://app.example.com", true, time.Hour)
+	test.Nil(t, err)
+	h := handler(credentials)
+
+	// Allowed origins are echoed, with credentials, and told which headers they may read
+	rec := serve(h, http.MethodGet, "Origin", "https://app.example.com")
+	test.Equal(t, http.StatusOK, rec.Code)
+	test.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	test.Equal(t, "true", rec.Header().Get("Access-Control-Allow-Credentials"))
+	test.Contains(t, rec.Header().Get("Access-Control-Expose-Headers"), "ETag")
+	test.Equal(t, "Origin", rec.Header().Get("Vary"))
+
+	// Other origins get no CORS headers, but the response still varies by origin
+	rec = serve(h, http.MethodGet, "Origin", "https://evil.example.com")
+	test.Equal(t, "", rec.Header().Get("Access-Control-Allow-Origin"))
+	test.Equal(t, "", rec.Header().Get("Access-Control-Allow-Credentials"))
+	test.Equal(t, "Origin", rec.Header().Get("Vary"))
+
+	// Preflight requests get the method and headers they asked for, when the API accepts them
+	rec = serve(h, http.MethodOptions, "Origin", "https://app.example.com",
+		"Access-Control-Request-Method", "PUT", "Access-Control-Request-Headers", "authorization,if-match")
+	test.Equal(t, http.StatusNoContent, rec.Code)
+	test.Equal(t, "PUT", rec.Header().Get("Access-Control-Allow-Methods"))
+	test.Equal(t, "authorization, if-match", rec.Header().Get("Access-Control-Allow-Headers"))
+	test.Equal(t, "3600", rec.Header().Get("Access-Control-Max-Age"))
+	test.DeepEqual(t, []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}, rec.Header().Values("Vary"))
+
+	for name, header := range map[string][]string{
+		"method":  {"Origin", "https://app.example.com", "Access-Control-Request-Method", "PATCH"},
+		"headers": {"Origin", "https://app.example.com", "Access-Control-Request-Method", "PUT", "Access-Control-Request-Headers", "X-Secret"},
+		"origin":  {"Origin", "https://evil.example.com", "Access-Control-Request-Method", "PUT"},
+	} {
+		t.Run("disallowed "+name, func(t *testing.T) {
+			rec := serve(h, http.MethodOptions, header...)
+			test.Equal(t, http.StatusNoContent, rec.Code)
+			test.Equal(t, "", rec.Header().Get("Access-Control-Allow-Methods"))
+			test.Equal(t, "", rec.Header().Get("Access-Control-Allow-Headers"))
+		})
+	}
+
+	// OPTIONS that isn't a preflight goes on to the routes
+	test.Equal(t, http.StatusOK, serve(h, http.MethodOptions).Code)
+
+	// Without a policy any origin may call the API, with responses shared between origins
+	rec = serve(handler(nil), http.MethodGet, "Origin", "https://app.example.com")
+	test.Equal(t, "*", rec.Header().Get("Access-Control-Allow-Origin"))
+	test.Equal(t, "", rec.Header().Get("Vary"))
+
+	// Credentials are never given to any site
+	for _, origins := range []string{"*", "https://*", "https://*:8443", "https://app.example.com,*"} {
+		_, err := newCORSPolicy(origins, true, time.Hour)
+		test.NotNil(t, err)
+		test.Contains(t, err.Error(), "any site")
+	}
+}
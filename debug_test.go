@@ -0,0 +1,69 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/raeperd/test"
+)
+
+func TestRoute_Debug(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", "")
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	api := httptest.NewServer(route(log, "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret"}))
+	t.Cleanup(api.Close)
+	debug := httptest.NewServer(routeDebug(log))
+	t.Cleanup(debug.Close)
+
+	for _, path := range []string{"/debug/pprof/cmdline", "/debug/vars", "/metrics"} {
+		test.Equal(t, http.StatusNotFound, getStatus(t, api.URL+path))
+		test.Equal(t, http.StatusOK, getStatus(t, debug.URL+path))
+	}
+	test.Equal(t, http.StatusNotFound, getStatus(t, debug.URL+"/health"))
+}
+
+func TestRun_DebugAddr(t *testing.T) {
+	t.Parallel()
+
+	port, debugAddr := freePort(t), "localhost:"+freePort(t)
+	api := "http://localhost:" + port
+	startServer(t, http.DefaultClient, api+"/health", "-port", port, "-debug-addr", debugAddr)
+
+	test.Equal(t, http.StatusNotFound, getStatus(t, api+"/debug/pprof/cmdline"))
+	test.Equal(t, http.StatusNotFound, getStatus(t, api+"/metrics"))
+	test.Equal(t, http.StatusOK, getStatus(t, "http://"+debugAddr+"/debug/pprof/cmdline"))
+	test.Equal(t, http.StatusOK, getStatus(t, "http://"+debugAddr+"/metrics"))
+
+	err := run(t.Context(), io.Discard, []string{"test", "-debug-addr", "6060", "-jwt-secret", "test-secret"}, "vtest")
+	test.NotNil(t, err)
+	test.Contains(t, err.Error(), "-debug-addr")
+}
+
+// freePort returns a TCP port that was free when asked.
+func freePort(t *testing.T) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "localhost:0")
+	test.Nil(t, err)
+	defer listener.Close() //nolint:errcheck
+	return strconv.Itoa(listener.Addr().(*net.TCPAddr).Port)
+}
+
+func getStatus(t *testing.T, url string) int {
+	t.Helper()
+
+	res, err := http.Get(url)
+	test.Nil(t, err)
+	_ = res.Body.Close()
+	return res.StatusCode
+}
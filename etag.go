@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// etag returns a weak entity tag for the representation v, a response type that always encodes.
+// It hashes v as JSON, so it changes with updated_at, the counters and every other field of the response,
+// including edits that land within the same second, which updated_at alone does not tell apart.
+func etag(v any) string {
+	return "W/" + strongETag(v)
+}
+
+// strongETag returns a strong entity tag for v, which always encodes, for If-Match of writes to compare with.
+func strongETag(v any) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("etag of %T: %v", v, err))
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// lastModifiedDelay is how old a Last-Modified must be to be sent. HTTP dates have whole seconds, so a change
+// later in the same second, or one committed late with the time its transaction started, would get the same date,
+// and If-Modified-Since with it would answer 304 for a response that has changed.
+const lastModifiedDelay = 2 * time.Second
+
+// lastModified returns the latest of times, the updated_at and touched_at of the rows a response shows.
+// touched_at moves with the favorites, comments, tags and followers that updated_at does not, and is zero until then.
+func lastModified(times ...time.Time) time.Time {
+	var latest time.Time
+	for _, t := range times {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// notModified sets the ETag and, unless zero or more recent than lastModifiedDelay, the Last-Modified header
+// of a GET response. If the request's If-None-Match or If-Modified-Since shows the client has this representation
+// already, it responds 304 Not Modified and returns true, and the handler must not write a body.
+func notModified(w http.ResponseWriter, r *http.Request, etag string, lastModified time.Time) bool {
+	w.Header().Set("ETag", etag)
+	if time.Since(lastModified) < lastModifiedDelay {
+		lastModified = time.Time{}
+	}
+	if !lastModified.IsZero() {
+		w.Header().Set("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	}
+	if !isNotModified(r.Header, etag, lastModified) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// isNotModified evaluates the If-None-Match and If-Modified-Since headers of a request as RFC 9110 does:
+// If-None-Match compares weakly, and If-Modified-Since only counts without it, with the second resolution of HTTP dates.
+// Without a lastModified, If-Modified-Since is ignored.
+func isNotModified(h http.Header, etag string, lastModified time.Time) bool {
+	if inm := h.Get("If-None-Match"); inm != "" {
+		return etagMatches(inm, etag, false)
+	}
+	if ims := h.Get("If-Modified-Since"); ims != "" && !lastModified.IsZero() {
+		since, err := http.ParseTime(ims)
+		return err == nil && !lastModified.Truncate(time.Second).After(since)
+	}
+	return false
+}
+
+// etagMatches reports whether an If-Match or If-None-Match list of entity tags matches etag. "*" matches any.
+// As RFC 9110 requires, If-None-Match compares weakly, ignoring the W/ prefix, and If-Match strongly,
+// where a weak tag matches nothing.
+func etagMatches(list, etag string, strong bool) bool {
+	if strings.TrimSpace(list) == "*" {
+		return true
+	}
+	if strong && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+	for candidate := range strings.SplitSeq(list, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if !strong {
+			candidate, etag = strings.TrimPrefix(candidate, "W/"), strings.TrimPrefix(etag, "W/")
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// ifMatch checks the If-Match header of a request that changes what, against the strong entity tag from current.
+// It returns true if there is no If-Match header or it matches. Otherwise it responds 412 Precondition Failed,
+// or 500 if current fails, and returns false.
+// current is only called for requests with an If-Match header, since it may need queries of its own.
+func ifMatch(w http.ResponseWriter, r *http.Request, what string, current func() (string, error)) bool {
+	list := r.Header.Get("If-Match")
+	if list == "" {
+		return true
+	}
+	tag, err := current()
+	if err != nil {
+		encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+		return false
+	}
+	if !etagMatches(list, tag, true) {
+		encodeErrorResponse(r.Context(), http.StatusPreconditionFailed, []error{errors.New(what + " has been modified")}, w)
+		return false
+	}
+	return true
+}
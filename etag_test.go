@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+)
+
+func TestETagMatches(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		list, etag   string
+		weak, strong bool
+	}{
+		"same":           {list: `"abc"`, etag: `"abc"`, weak: true, strong: true},
+		"same weak":      {list: `W/"abc"`, etag: `W/"abc"`, weak: true, strong: false},
+		"weak of strong": {list: `W/"abc"`, etag: `"abc"`, weak: true, strong: false},
+		"strong of weak": {list: `"abc"`, etag: `W/"abc"`, weak: true, strong: false},
+		"other":          {list: `"abd"`, etag: `"abc"`, weak: false, strong: false},
+		"list":           {list: `"abd", "abc"`, etag: `"abc"`, weak: true, strong: true},
+		"any":            {list: ` * `, etag: `W/"abc"`, weak: true, strong: true},
+		"unquoted":       {list: `abc`, etag: `"abc"`, weak: false, strong: false},
+		"star in a list": {list: `"abd", *`, etag: `"abc"`, weak: false, strong: false},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			test.Equal(t, tc.weak, etagMatches(tc.list, tc.etag, false))
+			test.Equal(t, tc.strong, etagMatches(tc.list, tc.etag, true))
+		})
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	t.Parallel()
+
+	modified := time.Now().Add(-time.Hour)
+	date := func(t time.Time) string { return t.UTC().Format(http.TimeFormat) }
+	testcases := map[string]struct {
+		lastModified time.Time
+		header       []string
+		notModified  bool
+		sent         bool
+	}{
+		"no validators":            {lastModified: modified, sent: true},
+		"same date":                {lastModified: modified, header: []string{"If-Modified-Since", date(modified)}, notModified: true, sent: true},
+		"later date":               {lastModified: modified, header: []string{"If-Modified-Since", date(modified.Add(time.Minute))}, notModified: true, sent: true},
+		"earlier date":             {lastModified: modified, header: []string{"If-Modified-Since", date(modified.Add(-time.Second))}, sent: true},
+		"invalid date":             {lastModified: modified, header: []string{"If-Modified-Since", "yesterday"}, sent: true},
+		"matching etag":            {lastModified: modified, header: []string{"If-None-Match", `"abc"`}, notModified: true, sent: true},
+		"etag takes precedence":    {lastModified: modified, header: []string{"If-None-Match", `W/"abd"`, "If-Modified-Since", date(modified)}, sent: true},
+		"no time":                  {header: []string{"If-Modified-Since", date(time.Now())}},
+		"changed within the delay": {lastModified: time.Now(), header: []string{"If-Modified-Since", date(time.Now().Add(time.Hour))}},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			for i := 0; i+1 < len(tc.header); i += 2 {
+				r.Header.Set(tc.header[i], tc.header[i+1])
+			}
+			w := httptest.NewRecorder()
+			test.Equal(t, tc.notModified, notModified(w, r, `W/"abc"`, tc.lastModified))
+			test.Equal(t, `W/"abc"`, w.Header().Get("ETag"))
+			test.Equal(t, tc.sent, w.Header().Get("Last-Modified") != "")
+		})
+	}
+
+	test.Equal(t, modified, lastModified(time.Time{}, modified.Add(-time.Hour), modified))
+}
+
+func TestETag_Article(t *testing.T) {
+	t.Parallel()
+
+	author, authorUser := registerUser(t, "etag_author")
+	reader, readerUser := registerUser(t, "etag_reader")
+	article := createArticle(t, author, fmt.Sprintf("ETag %d", time.Now().UnixNano()), "etag")
+	path := "/api/articles/" + article.Slug
+
+	// Anonymous requests go through the response cache, authenticated ones do not
+	for name, token := range map[string]string{"anonymous": "", "authenticated": readerUser.Token} {
+		t.Run(name, func(t *testing.T) {
+			res := conditional(t, http.MethodGet, path, token, "")
+			test.Equal(t, http.StatusOK, res.StatusCode)
+			etag := res.Header.Get("ETag")
+			test.True(t, strings.HasPrefix(etag, `W/"`))
+
+			res = conditional(t, http.MethodGet, path, token, "", "If-None-Match", etag)
+			test.Equal(t, http.StatusNotModified, res.StatusCode)
+			test.Equal(t, etag, res.Header.Get("ETag"))
+
+			// The article was created since, whether or not it is old enough for a Last-Modified yet
+			past := time.Now().Add(-time.Hour).UTC().Format(http.TimeFormat)
+			res = conditional(t, http.MethodGet, path, token, "", "If-Modified-Since", past)
+			test.Equal(t, http.StatusOK, res.StatusCode)
+		})
+	}
+
+	// A favorite changes the counter but not updated_at, and still changes the ETag
+	res := conditional(t, http.MethodGet, path, readerUser.Token, "")
+	before := res.Header.Get("ETag")
+	_, err := reader.Favorite(t.Context(), article.Slug)
+	test.Nil(t, err)
+	res = conditional(t, http.MethodGet, path, readerUser.Token, "", "If-None-Match", before)
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	test.NotEqual(t, before, res.Header.Get("ETag"))
+
+	// The Article-ETag of what the author last saw guards updates and deletes
+	res = conditional(t, http.MethodGet, path, authorUser.Token, "")
+	seen, seenResponse := res.Header.Get("Article-ETag"), res.Header.Get("ETag")
+	test.True(t, strings.HasPrefix(seen, `"`))
+	update := `{"article":{"body":"Edited"}}`
+	res = conditional(t, http.MethodPut, path, authorUser.Token, update, "If-Match", `"stale"`)
+	test.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	// If-Match compares strongly, so neither the weak ETag of the response nor a weak copy of the tag matches
+	res = conditional(t, http.MethodPut, path, authorUser.Token, update, "If-Match", seenResponse)
+	test.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res = conditional(t, http.MethodPut, path, authorUser.Token, update, "If-Match", "W/"+seen)
+	test.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+
+	// A favorite and a comment by others between the author's GET and PUT leave the article itself unchanged
+	other, _ := registerUser(t, "etag_other")
+	_, err = other.Favorite(t.Context(), article.Slug)
+	test.Nil(t, err)
+	_, err = other.AddComment(t.Context(), article.Slug, "Nice")
+	test.Nil(t, err)
+	res = conditional(t, http.MethodPut, path, authorUser.Token, update, "If-Match", seen)
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	updated := res.Header.Get("Article-ETag")
+	test.NotEqual(t, seen, updated)
+
+	// A second edit from the same copy loses, even within the same second as the first
+	res = conditional(t, http.MethodPut, path, authorUser.Token, `{"article":{"body":"Edited again"}}`, "If-Match", seen)
+	test.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res = conditional(t, http.MethodDelete, path, authorUser.Token, "", "If-Match", seen)
+	test.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res = conditional(t, http.MethodDelete, path, authorUser.Token, "", "If-Match", updated)
+	test.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestETag_Comments(t *testing.T) {
+	t.Parallel()
+
+	author, authorUser := registerUser(t, "etag_commenter")
+	article := createArticle(t, author, fmt.Sprintf("ETag comments %d", time.Now().UnixNano()))
+	path := "/api/articles/" + article.Slug + "/comments"
+
+	res := conditional(t, http.MethodGet, path, "", "")
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	list := res.Header.Get("ETag")
+	res = conditional(t, http.MethodGet, path, "", "", "If-None-Match", list)
+	test.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	res = conditional(t, http.MethodPost, path, authorUser.Token, `{"comment":{"body":"First"}}`)
+	test.Equal(t, http.StatusCreated, res.StatusCode)
+	comment := res.Header.Get("ETag")
+	test.NotZero(t, comment)
+	res = conditional(t, http.MethodGet, path, "", "", "If-None-Match", list)
+	test.Equal(t, http.StatusOK, res.StatusCode)
+
+	comments, err := author.GetComments(t.Context(), article.Slug)
+	test.Nil(t, err)
+	test.Equal(t, 1, len(comments))
+	commentPath := fmt.Sprintf("%s/%d", path, comments[0].ID)
+	res = conditional(t, http.MethodDelete, commentPath, authorUser.Token, "", "If-Match", list)
+	test.Equal(t, http.StatusPreconditionFailed, res.StatusCode)
+	res = conditional(t, http.MethodDelete, commentPath, authorUser.Token, "", "If-Match", comment)
+	test.Equal(t, http.StatusNoContent, res.StatusCode)
+}
+
+func TestETag_ProfileAndTags(t *testing.T) {
+	t.Parallel()
+
+	_, user := registerUser(t, "etag_profile")
+	follower, _ := registerUser(t, "etag_follower")
+	path := "/api/profiles/" + user.Username
+
+	res := conditional(t, http.MethodGet, path, "", "")
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	etag := res.Header.Get("ETag")
+	res = conditional(t, http.MethodGet, path, "", "", "If-None-Match", etag)
+	test.Equal(t, http.StatusNotModified, res.StatusCode)
+
+	// followersCount is part of the profile
+	_, err := follower.Follow(t.Context(), user.Username)
+	test.Nil(t, err)
+	res = conditional(t, http.MethodGet, path, "", "", "If-None-Match", etag)
+	test.Equal(t, http.StatusOK, res.StatusCode)
+
+	res = conditional(t, http.MethodGet, "/api/tags", "", "")
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	res = conditional(t, http.MethodGet, "/api/tags", "", "", "If-None-Match", "*")
+	test.Equal(t, http.StatusNotModified, res.StatusCode)
+}
+
+// conditional sends a request to the test server as the user of token, or anonymously without one,
+// with header given as name and value pairs, and returns the response with its body read and closed.
+func conditional(t *testing.T, method, path, token, body string, header ...string) *http.Response {
+	t.Helper()
+
+	req, err := http.NewRequestWithContext(t.Context(), method, endpoint+path, strings.NewReader(body))
+	test.Nil(t, err)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	test.Nil(t, err)
+	_, _ = io.Copy(io.Discard, res.Body)
+	_ = res.Body.Close()
+	return res
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+// maxIdempotencyKeyLength is the limit for Idempotency-Key headers, also documented in api/openapi.yaml.
+const maxIdempotencyKeyLength = 255
+
+// idempotencyKeys keeps the responses to requests with an Idempotency-Key in the database for ttl,
+// so a client that retries after a timeout gets the response of the first try instead of a duplicate.
+type idempotencyKeys struct {
+	db  store.Store
+	ttl time.Duration
+	log *slog.Logger
+}
+
+// newIdempotencyKeys returns idempotency keys stored in db for ttl.
+func newIdempotencyKeys(db store.Store, ttl time.Duration, log *slog.Logger) *idempotencyKeys {
+	return &idempotencyKeys{db: db, ttl: ttl, log: log}
+}
+
+// idempotent is a middleware for requests of an authenticated user, so it must run after [authenticate].
+// The first request with an Idempotency-Key runs as usual, and its response is stored for the user and key
+// unless it is a server error, which the client should be free to retry. Retries with the same key
+// get the stored response back with an Idempotent-Replayed header, or 409 while the first request is still running,
+// and 422 if the method, path or body is different from the first request.
+// Requests without the header, and any request with a nil keys, pass through.
+func idempotent(next http.Handler, keys *idempotencyKeys) http.Handler {
+	if keys == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		userID, ok := r.Context().Value(userIDKey).(int64)
+		if key == "" || !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			encodeErrorResponse(r.Context(), http.StatusUnprocessableEntity,
+				[]error{fmt.Errorf("idempotency key must be at most %d characters", maxIdempotencyKeyLength)}, w)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		hash := sha256.New()
+		_, _ = fmt.Fprintf(hash, "%s %s\n", r.Method, r.URL.Path)
+		_, _ = hash.Write(body)
+		requestHash := hex.EncodeToString(hash.Sum(nil))
+
+		reserved, err := keys.db.ReserveIdempotencyKey(r.Context(), store.ReserveIdempotencyKeyParams{
+			UserID:         userID,
+			IdempotencyKey: key,
+			RequestHash:    requestHash,
+			TtlSeconds:     int64(keys.ttl / time.Second),
+		})
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+		if reserved == 0 {
+			keys.replay(w, r, userID, key, requestHash)
+			return
+		}
+
+		rec := bufferedResponse{header: make(http.Header)}
+		next.ServeHTTP(&rec, r)
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+
+		// The response is stored even if the client has gone, since that is the client that will retry
+		ctx := context.WithoutCancel(r.Context())
+		if rec.status >= http.StatusInternalServerError {
+			err = keys.db.DeleteIdempotencyKey(ctx, store.DeleteIdempotencyKeyParams{UserID: userID, IdempotencyKey: key})
+		} else {
+			header, _ := json.Marshal(rec.header)
+			err = keys.db.SaveIdempotencyResponse(ctx, store.SaveIdempotencyResponseParams{
+				Status:         int64(rec.status),
+				Header:         string(header),
+				Body:           rec.body.String(),
+				UserID:         userID,
+				IdempotencyKey: key,
+			})
+		}
+		if err != nil {
+			keys.log.ErrorContext(ctx, "failed to store idempotency key", slog.String("error", err.Error()))
+		}
+
+		for name, values := range rec.header {
+			w.Header()[name] = values
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(rec.body.Bytes())
+	})
+}
+
+// replay responds to a retry with the key of the user with the stored response of the first request.
+func (k *idempotencyKeys) replay(w http.ResponseWriter, r *http.Request, userID int64, key, requestHash string) {
+	saved, err := k.db.GetIdempotencyKey(r.Context(), store.GetIdempotencyKeyParams{UserID: userID, IdempotencyKey: key})
+	if errors.Is(err, sql.ErrNoRows) {
+		// Deleted after a server error or as expired since it was reserved, so the client may try again
+		encodeErrorResponse(r.Context(), http.StatusConflict, []error{errors.New("request with this idempotency key failed, retry it")}, w)
+		return
+	}
+	if err != nil {
+		encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+		return
+	}
+
+	switch {
+	case saved.RequestHash != requestHash:
+		encodeErrorResponse(r.Context(), http.StatusUnprocessableEntity, []error{errors.New("idempotency key was used for a different request")}, w)
+	case saved.Status == 0:
+		encodeErrorResponse(r.Context(), http.StatusConflict, []error{errors.New("request with this idempotency key is still running")}, w)
+	default:
+		var header http.Header
+		if err := json.Unmarshal([]byte(saved.Header), &header); err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+		for name, values := range header {
+			w.Header()[name] = values
+		}
+		w.Header().Set("Idempotent-Replayed", "true")
+		w.WriteHeader(int(saved.Status))
+		_, _ = io.WriteString(w, saved.Body)
+	}
+}
+
+// prune deletes expired keys every interval until ctx is done.
+// Expired keys are free to reserve again either way, so this only keeps the table small.
+func (k *idempotencyKeys) prune(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if n, err := k.db.DeleteExpiredIdempotencyKeys(ctx); err != nil {
+				k.log.ErrorContext(ctx, "failed to prune idempotency keys", slog.String("error", err.Error()))
+			} else if n > 0 {
+				k.log.InfoContext(ctx, "pruned idempotency keys", slog.Int64("count", n))
+			}
+		}
+	}
+}
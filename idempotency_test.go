@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+func TestIdempotent_Retry(t *testing.T) {
+	t.Parallel()
+
+	author, authorUser := registerUser(t, "idempotent_author")
+	_, otherUser := registerUser(t, "idempotent_other")
+	unique := fmt.Sprintf("%d", time.Now().UnixNano())
+	post := fmt.Sprintf(`{"article":{"title":"Retried %s","description":"Retried","body":"Retried"}}`, unique)
+	key := "article-" + unique
+
+	first := conditional(t, http.MethodPost, "/api/articles", authorUser.Token, post, "Idempotency-Key", key)
+	test.Equal(t, http.StatusCreated, first.StatusCode)
+	test.Equal(t, "", first.Header.Get("Idempotent-Replayed"))
+	retry := conditional(t, http.MethodPost, "/api/articles", authorUser.Token, post, "Idempotency-Key", key)
+	test.Equal(t, http.StatusCreated, retry.StatusCode)
+	test.Equal(t, "true", retry.Header.Get("Idempotent-Replayed"))
+	test.Equal(t, first.Header.Get("ETag"), retry.Header.Get("ETag"))
+
+	articles, err := author.GetArticles(t.Context(), client.ArticlesOptions{Author: authorUser.Username})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(articles.Articles))
+
+	// The key belongs to the request it was first used for, and to its user
+	res := conditional(t, http.MethodPost, "/api/articles", authorUser.Token, strings.Replace(post, "Retried", "Other", 1), "Idempotency-Key", key)
+	test.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+	res = conditional(t, http.MethodPost, "/api/articles", otherUser.Token, strings.Replace(post, "Retried", "Another", 1), "Idempotency-Key", key)
+	test.Equal(t, http.StatusCreated, res.StatusCode)
+	test.Equal(t, "", res.Header.Get("Idempotent-Replayed"))
+
+	// Comments on the article are retried the same way
+	comments := "/api/articles/" + articles.Articles[0].Slug + "/comments"
+	for range 2 {
+		res := conditional(t, http.MethodPost, comments, authorUser.Token, `{"comment":{"body":"Once"}}`, "Idempotency-Key", "comment-"+unique)
+		test.Equal(t, http.StatusCreated, res.StatusCode)
+	}
+	list, err := author.GetComments(t.Context(), articles.Articles[0].Slug)
+	test.Nil(t, err)
+	test.Equal(t, 1, len(list))
+
+	res = conditional(t, http.MethodPost, "/api/articles", authorUser.Token, post, "Idempotency-Key", strings.Repeat("k", maxIdempotencyKeyLength+1))
+	test.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
+}
+
+func TestIdempotent_RunningAndFailed(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "idempotency.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	user, err := db.CreateUser(t.Context(), store.CreateUserParams{Username: "idempotent", Email: "idempotent@example.com", Password: "hash"})
+	test.Nil(t, err)
+
+	status := make(chan int)
+	var calls int
+	handler := idempotent(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		calls++
+		w.WriteHeader(<-status)
+		_, _ = io.WriteString(w, fmt.Sprintf("call %d", calls))
+	}), newIdempotencyKeys(db, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	serve := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/api/articles", strings.NewReader(`{}`))
+		req = req.WithContext(context.WithValue(req.Context(), userIDKey, user.ID))
+		req.Header.Set("Idempotency-Key", "key")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	// A server error is not kept, so the retry runs again
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- serve() }()
+	status <- http.StatusInternalServerError
+	test.Equal(t, http.StatusInternalServerError, (<-done).Code)
+
+	go func() { done <- serve() }()
+	for { // Wait for the first request to reserve the key
+		if _, err := db.GetIdempotencyKey(t.Context(), store.GetIdempotencyKeyParams{UserID: user.ID, IdempotencyKey: "key"}); err == nil {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	test.Equal(t, http.StatusConflict, serve().Code)
+	status <- http.StatusCreated
+	test.Equal(t, "call 2", (<-done).Body.String())
+
+	rec := serve()
+	test.Equal(t, http.StatusCreated, rec.Code)
+	test.Equal(t, "call 2", rec.Body.String())
+	test.Equal(t, "true", rec.Header().Get("Idempotent-Replayed"))
+}
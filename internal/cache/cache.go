@@ -0,0 +1,194 @@
+// Package cache provides an in-memory cache bounded in size, with expiring entries and tag-based invalidation.
+// Concurrent misses of the same key share a single load.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Cache maps keys to values of type V, evicting the least recently used entries beyond its size in bytes.
+// Entries are stored with tags, and [Cache.Invalidate] removes every entry with one of the given tags.
+// A nil *Cache is valid and caches nothing.
+type Cache[V any] struct {
+	maxBytes int64
+	ttl      time.Duration
+	size     func(V) int64
+	now      func() time.Time
+
+	mu    sync.Mutex
+	lru   *list.List // of *entry[V], most recently used first
+	keys  map[string]*list.Element
+	tags  map[string]map[string]struct{} // keys by tag
+	calls map[string]*call[V]
+	epoch uint64 // incremented by Invalidate, so loads that raced with it are not stored
+	bytes int64
+	stats Stats
+}
+
+type entry[V any] struct {
+	key     string
+	value   V
+	tags    []string
+	size    int64
+	expires time.Time
+}
+
+// call is a load in flight, which callers of the same key wait for instead of loading again.
+type call[V any] struct {
+	wg    sync.WaitGroup
+	value V
+	done  bool // false if the load panicked
+}
+
+// Stats counts the lookups and evictions of a cache, and holds its current size.
+type Stats struct {
+	Hits          int64 `json:"hits"`
+	Misses        int64 `json:"misses"`
+	Shared        int64 `json:"shared"` // misses that waited for the load of another caller
+	Evictions     int64 `json:"evictions"`
+	Invalidations int64 `json:"invalidations"`
+	Entries       int   `json:"entries"`
+	Bytes         int64 `json:"bytes"`
+}
+
+// New returns a cache of at most maxBytes, as counted by size for each value, whose entries expire after ttl.
+func New[V any](maxBytes int64, ttl time.Duration, size func(V) int64) *Cache[V] {
+	return &Cache[V]{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		size:     size,
+		now:      time.Now,
+		lru:      list.New(),
+		keys:     make(map[string]*list.Element),
+		tags:     make(map[string]map[string]struct{}),
+		calls:    make(map[string]*call[V]),
+	}
+}
+
+// Do returns the value cached for key, or calls load for it.
+// Callers that miss the same key while load runs wait for it and share its value.
+// The value is stored with tags only if load reports ok, and if no invalidation happened while it ran.
+func (c *Cache[V]) Do(key string, load func() (value V, tags []string, ok bool)) V {
+	if c == nil {
+		value, _, _ := load()
+		return value
+	}
+
+	c.mu.Lock()
+	if el, found := c.keys[key]; found {
+		e := el.Value.(*entry[V])
+		if c.now().Before(e.expires) {
+			c.lru.MoveToFront(el)
+			c.stats.Hits++
+			c.mu.Unlock()
+			return e.value
+		}
+		c.remove(el)
+	}
+	if cl, found := c.calls[key]; found {
+		c.stats.Shared++
+		c.mu.Unlock()
+		cl.wg.Wait()
+		if cl.done {
+			return cl.value
+		}
+		value, _, _ := load()
+		return value
+	}
+	cl := &call[V]{}
+	cl.wg.Add(1)
+	c.calls[key] = cl
+	epoch := c.epoch
+	c.stats.Misses++
+	c.mu.Unlock()
+
+	var tags []string
+	var ok bool
+	defer func() {
+		c.mu.Lock()
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		if cl.done && ok && epoch == c.epoch {
+			c.add(key, cl.value, tags)
+		}
+		c.mu.Unlock()
+		cl.wg.Done()
+	}()
+	cl.value, tags, ok = load()
+	cl.done = true
+	return cl.value
+}
+
+// add stores value under key, evicting the least recently used entries to make room. c.mu must be held.
+func (c *Cache[V]) add(key string, value V, tags []string) {
+	size := c.size(value) + int64(len(key))
+	if size > c.maxBytes {
+		return
+	}
+	if el, found := c.keys[key]; found {
+		c.remove(el)
+	}
+	for c.bytes+size > c.maxBytes {
+		c.remove(c.lru.Back())
+		c.stats.Evictions++
+	}
+
+	c.keys[key] = c.lru.PushFront(&entry[V]{key: key, value: value, tags: tags, size: size, expires: c.now().Add(c.ttl)})
+	c.bytes += size
+	for _, tag := range tags {
+		if c.tags[tag] == nil {
+			c.tags[tag] = make(map[string]struct{})
+		}
+		c.tags[tag][key] = struct{}{}
+	}
+}
+
+// remove removes the entry of el. c.mu must be held.
+func (c *Cache[V]) remove(el *list.Element) {
+	e := c.lru.Remove(el).(*entry[V])
+	delete(c.keys, e.key)
+	c.bytes -= e.size
+	for _, tag := range e.tags {
+		delete(c.tags[tag], e.key)
+		if len(c.tags[tag]) == 0 {
+			delete(c.tags, tag)
+		}
+	}
+}
+
+// Invalidate removes the entries with any of tags.
+// Loads in flight are not stored, and later callers load again instead of waiting for them,
+// so a value read before the change that caused the invalidation is never returned after it.
+func (c *Cache[V]) Invalidate(tags ...string) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.epoch++
+	clear(c.calls)
+	for _, tag := range tags {
+		for key := range c.tags[tag] {
+			c.remove(c.keys[key])
+			c.stats.Invalidations++
+		}
+	}
+}
+
+// Stats returns the current statistics of the cache.
+func (c *Cache[V]) Stats() Stats {
+	if c == nil {
+		return Stats{}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stats := c.stats
+	stats.Entries = len(c.keys)
+	stats.Bytes = c.bytes
+	return stats
+}
@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+)
+
+func newTestCache(maxBytes int64, ttl time.Duration) *Cache[string] {
+	return New(maxBytes, ttl, func(v string) int64 { return int64(len(v)) })
+}
+
+// value returns a load that counts its calls in n.
+func value(v string, n *atomic.Int64, tags ...string) func() (string, []string, bool) {
+	return func() (string, []string, bool) {
+		n.Add(1)
+		return v, tags, true
+	}
+}
+
+func TestCache_Do(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCache(1024, time.Minute)
+	var loads atomic.Int64
+	test.Equal(t, "a", c.Do("key", value("a", &loads)))
+	test.Equal(t, "a", c.Do("key", value("b", &loads)))
+	test.Equal(t, int64(1), loads.Load())
+
+	// Values not ok to store are returned but loaded again
+	notOK := func() (string, []string, bool) { loads.Add(1); return "error", nil, false }
+	test.Equal(t, "error", c.Do("other", notOK))
+	test.Equal(t, "error", c.Do("other", notOK))
+	test.Equal(t, int64(3), loads.Load())
+
+	stats := c.Stats()
+	test.Equal(t, int64(1), stats.Hits)
+	test.Equal(t, int64(3), stats.Misses)
+	test.Equal(t, 1, stats.Entries)
+	test.Equal(t, int64(len("key")+len("a")), stats.Bytes)
+}
+
+func TestCache_TTL(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	c := newTestCache(1024, time.Minute)
+	c.now = func() time.Time { return now }
+	var loads atomic.Int64
+	c.Do("key", value("a", &loads))
+	now = now.Add(59 * time.Second)
+	test.Equal(t, "a", c.Do("key", value("b", &loads)))
+	now = now.Add(time.Second)
+	test.Equal(t, "b", c.Do("key", value("b", &loads)))
+	test.Equal(t, int64(2), loads.Load())
+}
+
+func TestCache_Evict(t *testing.T) {
+	t.Parallel()
+
+	// Room for three entries of a one byte key and a one byte value
+	c := newTestCache(6, time.Minute)
+	var loads atomic.Int64
+	for _, key := range []string{"a", "b", "c"} {
+		c.Do(key, value(key, &loads))
+	}
+	c.Do("a", value("a", &loads)) // b is now the least recently used
+	c.Do("d", value("d", &loads))
+	test.Equal(t, int64(4), loads.Load())
+
+	c.Do("a", value("a", &loads))
+	c.Do("c", value("c", &loads))
+	test.Equal(t, int64(4), loads.Load())
+	c.Do("b", value("b", &loads))
+	test.Equal(t, int64(5), loads.Load())
+	test.Equal(t, int64(2), c.Stats().Evictions)
+
+	// Values larger than the cache are never stored
+	c.Do("huge", value("too large to cache", &loads))
+	c.Do("huge", value("too large to cache", &loads))
+	test.Equal(t, int64(7), loads.Load())
+	test.Equal(t, 3, c.Stats().Entries)
+}
+
+func TestCache_Invalidate(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCache(1024, time.Minute)
+	var loads atomic.Int64
+	c.Do("list", value("list", &loads, "article:a", "article:b"))
+	c.Do("a", value("a", &loads, "article:a"))
+	c.Do("b", value("b", &loads, "article:b"))
+
+	c.Invalidate("article:a", "unknown")
+	test.Equal(t, 1, c.Stats().Entries)
+	test.Equal(t, int64(2), c.Stats().Invalidations)
+	c.Do("b", value("b", &loads))
+	test.Equal(t, int64(3), loads.Load())
+	c.Do("list", value("list", &loads))
+	test.Equal(t, int64(4), loads.Load())
+
+	var nilCache *Cache[string]
+	nilCache.Invalidate("article:a")
+	test.Equal(t, "nil", nilCache.Do("key", value("nil", &loads)))
+}
+
+func TestCache_Singleflight(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCache(1024, time.Minute)
+	var loads atomic.Int64
+	release := make(chan struct{})
+	slow := func() (string, []string, bool) {
+		loads.Add(1)
+		<-release
+		return "slow", nil, true
+	}
+
+	var wg sync.WaitGroup
+	results := make([]string, 10)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.Do("key", slow)
+		}()
+	}
+	for c.Stats().Shared < int64(len(results)-1) {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+
+	test.Equal(t, int64(1), loads.Load())
+	for _, result := range results {
+		test.Equal(t, "slow", result)
+	}
+}
+
+func TestCache_InvalidateDuringLoad(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCache(1024, time.Minute)
+	var loads atomic.Int64
+	stale := func() (string, []string, bool) {
+		loads.Add(1)
+		c.Invalidate("article:a") // a write committed while the value was read
+		return "stale", []string{"article:a"}, true
+	}
+	test.Equal(t, "stale", c.Do("a", stale))
+	test.Equal(t, "fresh", c.Do("a", value("fresh", &loads)))
+	test.Equal(t, int64(2), loads.Load())
+}
+
+func TestCache_Panic(t *testing.T) {
+	t.Parallel()
+
+	c := newTestCache(1024, time.Minute)
+	func() {
+		defer func() { test.NotNil(t, recover()) }()
+		c.Do("key", func() (string, []string, bool) { panic("load failed") })
+	}()
+
+	// The failed load is forgotten rather than left for callers to wait on
+	var loads atomic.Int64
+	test.Equal(t, "a", c.Do("key", value("a", &loads)))
+	test.Equal(t, 1, c.Stats().Entries)
+}
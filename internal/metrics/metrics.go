@@ -0,0 +1,287 @@
+// Package metrics keeps counters and histograms in memory and writes them in the Prometheus text exposition format.
+// It covers what the server reports, without the dependencies of the Prometheus client library:
+// metrics are registered once on a [Registry], and each series is picked by the values of its labels.
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultBuckets are the upper bounds of histogram buckets for request latencies in seconds, as in the Prometheus client.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry holds the metrics that [Registry.WriteTo] writes. The zero value is not usable, see [NewRegistry].
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]family
+}
+
+// family is a metric of a registry with all of its series.
+type family interface {
+	write(w *bufio.Writer)
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{families: make(map[string]family)}
+}
+
+func (r *Registry) register(name string, f family) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.families[name]; ok {
+		panic("metrics: " + name + " registered twice")
+	}
+	r.families[name] = f
+}
+
+// WriteTo writes every metric of r in the text exposition format, sorted by name and then by label values.
+func (r *Registry) WriteTo(w io.Writer) (int64, error) {
+	r.mu.Lock()
+	names := make([]string, 0, len(r.families))
+	for name := range r.families {
+		names = append(names, name)
+	}
+	families := make([]family, len(names))
+	sort.Strings(names)
+	for i, name := range names {
+		families[i] = r.families[name]
+	}
+	r.mu.Unlock()
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	for _, f := range families {
+		f.write(bw)
+	}
+	err := bw.Flush()
+	return cw.n, err
+}
+
+// ServeHTTP serves the metrics of r to a Prometheus scrape.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = r.WriteTo(w)
+}
+
+// Counter is a metric that only goes up, with a series for each combination of label values.
+type Counter struct {
+	desc
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labels []string
+	value  float64
+}
+
+// Counter registers and returns a counter with the label names labels.
+func (r *Registry) Counter(name, help string, labels ...string) *Counter {
+	c := &Counter{desc: desc{name: name, help: help, kind: "counter", labels: labels}, series: make(map[string]*counterSeries)}
+	r.register(name, c)
+	return c
+}
+
+// Inc adds 1 to the series of the label values, which must be as many as the label names.
+func (c *Counter) Inc(values ...string) {
+	c.Add(1, values...)
+}
+
+// Add adds v, which must not be negative, to the series of the label values.
+func (c *Counter) Add(v float64, values ...string) {
+	key := c.key(values)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s, ok := c.series[key]
+	if !ok {
+		s = &counterSeries{labels: slices.Clone(values)}
+		c.series[key] = s
+	}
+	s.value += v
+}
+
+func (c *Counter) write(w *bufio.Writer) {
+	c.header(w)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, key := range sortedKeys(c.series) {
+		s := c.series[key]
+		c.sample(w, "", s.labels, "", "", s.value)
+	}
+}
+
+// Histogram is a metric that counts observations in buckets, with a series for each combination of label values.
+type Histogram struct {
+	desc
+	buckets []float64
+	mu      sync.Mutex
+	series  map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64 // per bucket, not cumulative, with the +Inf bucket last
+	sum    float64
+	count  uint64
+}
+
+// Histogram registers and returns a histogram with the bucket upper bounds buckets, in increasing order,
+// and the label names labels. The +Inf bucket is implied.
+func (r *Registry) Histogram(name, help string, buckets []float64, labels ...string) *Histogram {
+	h := &Histogram{desc: desc{name: name, help: help, kind: "histogram", labels: labels}, buckets: buckets, series: make(map[string]*histogramSeries)}
+	r.register(name, h)
+	return h
+}
+
+// Observe counts v in the series of the label values, which must be as many as the label names.
+func (h *Histogram) Observe(v float64, values ...string) {
+	key := h.key(values)
+	bucket := sort.SearchFloat64s(h.buckets, v) // the first bucket whose bound is not below v
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogramSeries{labels: slices.Clone(values), counts: make([]uint64, len(h.buckets)+1)}
+		h.series[key] = s
+	}
+	s.counts[bucket]++
+	s.sum += v
+	s.count++
+}
+
+func (h *Histogram) write(w *bufio.Writer) {
+	h.header(w)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+		var cumulative uint64
+		for i, count := range s.counts {
+			cumulative += count
+			le := math.Inf(1)
+			if i < len(h.buckets) {
+				le = h.buckets[i]
+			}
+			h.sample(w, "_bucket", s.labels, "le", formatFloat(le), float64(cumulative))
+		}
+		h.sample(w, "_sum", s.labels, "", "", s.sum)
+		h.sample(w, "_count", s.labels, "", "", float64(s.count))
+	}
+}
+
+// Func is a metric whose samples are collected when it is written, for values kept elsewhere such as pool stats.
+type Func struct {
+	desc
+	collect func(sample func(v float64, values ...string))
+}
+
+// GaugeFunc registers a gauge with the label names labels, whose samples come from collect.
+// collect calls sample once for each series, with as many label values as label names.
+func (r *Registry) GaugeFunc(name, help string, labels []string, collect func(sample func(v float64, values ...string))) {
+	r.register(name, &Func{desc: desc{name: name, help: help, kind: "gauge", labels: labels}, collect: collect})
+}
+
+// CounterFunc is [Registry.GaugeFunc] for values that only go up.
+func (r *Registry) CounterFunc(name, help string, labels []string, collect func(sample func(v float64, values ...string))) {
+	r.register(name, &Func{desc: desc{name: name, help: help, kind: "counter", labels: labels}, collect: collect})
+}
+
+func (f *Func) write(w *bufio.Writer) {
+	f.header(w)
+	f.collect(func(v float64, values ...string) {
+		f.key(values) // checks the number of values
+		f.sample(w, "", values, "", "", v)
+	})
+}
+
+// desc is the name, help and label names shared by every kind of metric.
+type desc struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+}
+
+// key returns the key of the series of the label values.
+func (d *desc) key(values []string) string {
+	if len(values) != len(d.labels) {
+		panic(fmt.Sprintf("metrics: %s has labels %v, got values %v", d.name, d.labels, values))
+	}
+	return strings.Join(values, "\xff")
+}
+
+func (d *desc) header(w *bufio.Writer) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", d.name, helpEscaper.Replace(d.help), d.name, d.kind)
+}
+
+// sample writes a sample of the metric with the name suffix, its label values and an extra label, such as le, unless empty.
+func (d *desc) sample(w *bufio.Writer, suffix string, values []string, extraName, extraValue string, v float64) {
+	w.WriteString(d.name + suffix)
+	if len(values) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, value := range values {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, d.labels[i], labelEscaper.Replace(value))
+		}
+		if extraName != "" {
+			if len(values) > 0 {
+				w.WriteByte(',')
+			}
+			fmt.Fprintf(w, `%s="%s"`, extraName, extraValue)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteByte(' ')
+	w.WriteString(formatFloat(v))
+	w.WriteByte('\n')
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// countingWriter counts the bytes written for [Registry.WriteTo].
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/raeperd/test"
+)
+
+func TestRegistry_WriteTo(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	requests := r.Counter("requests_total", "Requests by route.", "route", "status")
+	latency := r.Histogram("latency_seconds", "Latency\nin seconds.", []float64{0.1, 1}, "route")
+	r.GaugeFunc("pool_open", "Open connections.", []string{"pool"}, func(sample func(float64, ...string)) {
+		sample(2, "writer")
+		sample(5, "reader")
+	})
+	r.CounterFunc("uptime_total", "Uptime.", nil, func(sample func(float64, ...string)) {
+		sample(1.5)
+	})
+
+	requests.Inc("GET /b", "200")
+	requests.Inc("GET /a", "200")
+	requests.Add(2, "GET /a", "200")
+	requests.Inc(`GET /"quoted"\`, "500")
+	latency.Observe(0.05, "GET /a")
+	latency.Observe(0.1, "GET /a")
+	latency.Observe(3, "GET /a")
+
+	var b strings.Builder
+	n, err := r.WriteTo(&b)
+	test.Nil(t, err)
+	test.Equal(t, int64(b.Len()), n)
+	test.Equal(t, `# HELP latency_seconds Latency\nin seconds.
+# TYPE latency_seconds histogram
+latency_seconds_bucket{route="GET /a",le="0.1"} 2
+latency_seconds_bucket{route="GET /a",le="1"} 2
+latency_seconds_bucket{route="GET /a",le="+Inf"} 3
+latency_seconds_sum{route="GET /a"} 3.15
+latency_seconds_count{route="GET /a"} 3
+# HELP pool_open Open connections.
+# TYPE pool_open gauge
+pool_open{pool="writer"} 2
+pool_open{pool="reader"} 5
+# HELP requests_total Requests by route.
+# TYPE requests_total counter
+requests_total{route="GET /\"quoted\"\\",status="500"} 1
+requests_total{route="GET /a",status="200"} 3
+requests_total{route="GET /b",status="200"} 1
+# HELP uptime_total Uptime.
+# TYPE uptime_total counter
+uptime_total 1.5
+`, b.String())
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	r.Counter("empty_total", "Nothing yet.").Inc()
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	test.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	test.Equal(t, "# HELP empty_total Nothing yet.\n# TYPE empty_total counter\nempty_total 1\n", rec.Body.String())
+}
+
+func TestRegistry_Panics(t *testing.T) {
+	t.Parallel()
+
+	r := NewRegistry()
+	c := r.Counter("twice_total", "Twice.", "label")
+	for name, f := range map[string]func(){
+		"registered twice": func() { r.Counter("twice_total", "Twice.") },
+		"missing label":    func() { c.Inc() },
+		"too many labels":  func() { c.Inc("a", "b") },
+		"histogram label":  func() { r.Histogram("h", "H.", DefaultBuckets, "route").Observe(1) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			defer func() { test.NotNil(t, recover()) }()
+			f()
+		})
+	}
+}
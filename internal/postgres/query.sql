@@ -0,0 +1,391 @@
+-- name: CreateUser :one
+INSERT INTO users (username, email, password, bio, image) VALUES ($1, $2, $3, $4, $5) RETURNING *;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users WHERE email = $1;
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: UpdateUser :one
+UPDATE users
+SET
+    email = COALESCE(sqlc.narg('email'), email),
+    username = COALESCE(sqlc.narg('username'), username),
+    password = COALESCE(sqlc.narg('password'), password),
+    bio = COALESCE(sqlc.narg('bio'), bio),
+    image = COALESCE(sqlc.narg('image'), image)
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: GetUserByUsername :one
+SELECT * FROM users WHERE username = $1;
+
+-- name: CreateFollow :exec
+INSERT INTO follows (follower_id, followed_id) VALUES ($1, $2)
+ON CONFLICT (follower_id, followed_id) DO NOTHING;
+
+-- name: IsFollowing :one
+SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = $1 AND followed_id = $2);
+
+-- name: GetFollowingByIDs :many
+SELECT followed_id FROM follows
+WHERE follower_id = $1 AND followed_id = ANY(sqlc.arg('followed_ids')::bigint[]);
+
+-- name: DeleteFollow :exec
+DELETE FROM follows WHERE follower_id = $1 AND followed_id = $2;
+
+-- name: GetAllTags :many
+SELECT name FROM tags ORDER BY name;
+
+-- name: CreateArticle :one
+INSERT INTO articles (slug, title, description, body, author_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetOrCreateTag :one
+-- Note: The "DO UPDATE SET name = EXCLUDED.name" is intentional - it makes RETURNING * return the existing row
+-- on conflict. ON CONFLICT DO NOTHING won't return the existing row.
+INSERT INTO tags (name) VALUES ($1)
+ON CONFLICT (name) DO UPDATE SET name = EXCLUDED.name
+RETURNING *;
+
+-- name: AssociateArticleTag :exec
+INSERT INTO article_tags (article_id, tag_id) VALUES ($1, $2);
+
+-- name: GetArticleBySlug :one
+SELECT
+    a.*,
+    u.username as author_username,
+    u.bio as author_bio,
+    u.image as author_image,
+    u.updated_at as author_updated_at,
+    u.touched_at as author_touched_at
+FROM articles a
+JOIN users u ON a.author_id = u.id
+WHERE a.slug = $1;
+
+-- name: GetArticleTagsByArticleID :many
+SELECT t.name
+FROM tags t
+JOIN article_tags at ON t.id = at.tag_id
+WHERE at.article_id = $1
+ORDER BY t.name;
+
+-- name: GetArticleTagsByArticleIDs :many
+SELECT at.article_id, t.name
+FROM tags t
+JOIN article_tags at ON t.id = at.tag_id
+WHERE at.article_id = ANY(sqlc.arg('article_ids')::bigint[])
+ORDER BY at.article_id, t.name;
+
+-- name: GetFavoritesCount :one
+SELECT favorites_count FROM articles WHERE id = $1;
+
+-- name: IsFavorited :one
+SELECT EXISTS(SELECT 1 FROM favorites WHERE user_id = $1 AND article_id = $2);
+
+-- name: CreateFavorite :exec
+INSERT INTO favorites (user_id, article_id) VALUES ($1, $2)
+ON CONFLICT (user_id, article_id) DO NOTHING;
+
+-- name: DeleteFavorite :exec
+DELETE FROM favorites WHERE user_id = $1 AND article_id = $2;
+
+-- name: UpdateArticle :one
+UPDATE articles
+SET
+    slug = COALESCE(sqlc.narg('slug'), slug),
+    title = COALESCE(sqlc.narg('title'), title),
+    description = COALESCE(sqlc.narg('description'), description),
+    body = COALESCE(sqlc.narg('body'), body)
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: DeleteArticle :exec
+DELETE FROM articles WHERE id = $1;
+
+-- name: CreateComment :one
+INSERT INTO comments (body, article_id, author_id)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetCommentWithAuthor :one
+SELECT
+    c.*,
+    u.username as author_username,
+    u.bio as author_bio,
+    u.image as author_image
+FROM comments c
+JOIN users u ON c.author_id = u.id
+WHERE c.id = $1;
+
+-- name: GetCommentsByArticleSlug :many
+SELECT
+    c.id,
+    c.body,
+    c.created_at,
+    c.updated_at,
+    c.author_id,
+    u.username as author_username,
+    u.bio as author_bio,
+    u.image as author_image
+FROM comments c
+JOIN articles a ON c.article_id = a.id
+JOIN users u ON c.author_id = u.id
+WHERE a.slug = $1
+ORDER BY c.created_at DESC;
+
+-- name: GetCommentByID :one
+SELECT id, body, article_id, author_id, created_at, updated_at
+FROM comments
+WHERE id = $1;
+
+-- name: DeleteComment :exec
+DELETE FROM comments
+WHERE id = $1;
+
+-- name: ListArticles :many
+-- Each filter is skipped when NULL, so one query serves the plain list and every combination of filters.
+-- before_created_at and before_id continue after the last article of the previous page, ordered by (created_at, id).
+SELECT
+    a.id,
+    a.slug,
+    a.title,
+    a.description,
+    a.created_at,
+    a.updated_at,
+    a.favorites_count,
+    a.comments_count,
+    a.author_id,
+    u.username as author_username,
+    u.bio as author_bio,
+    u.image as author_image
+FROM articles a
+JOIN users u ON a.author_id = u.id
+WHERE (sqlc.narg('tag')::text IS NULL OR a.id IN (
+        SELECT at.article_id FROM article_tags at JOIN tags t ON at.tag_id = t.id WHERE t.name = sqlc.narg('tag')
+    ))
+    AND (sqlc.narg('author')::text IS NULL OR u.username = sqlc.narg('author'))
+    AND (sqlc.narg('favorited')::text IS NULL OR a.id IN (
+        SELECT f.article_id FROM favorites f JOIN users fu ON f.user_id = fu.id WHERE fu.username = sqlc.narg('favorited')
+    ))
+    AND (sqlc.narg('before_created_at')::timestamp IS NULL OR a.created_at < sqlc.narg('before_created_at')
+        OR (a.created_at = sqlc.narg('before_created_at') AND a.id < sqlc.narg('before_id')))
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT sqlc.arg('limit')::bigint OFFSET sqlc.arg('offset')::bigint;
+
+-- name: CountArticles :one
+SELECT COUNT(*)
+FROM articles a
+JOIN users u ON a.author_id = u.id
+WHERE (sqlc.narg('tag')::text IS NULL OR a.id IN (
+        SELECT at.article_id FROM article_tags at JOIN tags t ON at.tag_id = t.id WHERE t.name = sqlc.narg('tag')
+    ))
+    AND (sqlc.narg('author')::text IS NULL OR u.username = sqlc.narg('author'))
+    AND (sqlc.narg('favorited')::text IS NULL OR a.id IN (
+        SELECT f.article_id FROM favorites f JOIN users fu ON f.user_id = fu.id WHERE fu.username = sqlc.narg('favorited')
+    ));
+
+-- name: CheckFavoritedByUser :many
+SELECT article_id
+FROM favorites
+WHERE user_id = $1 AND article_id = ANY(sqlc.arg('article_ids')::bigint[]);
+
+-- name: ListArticlesFeed :many
+-- Paginates like ListArticles.
+SELECT
+    a.id,
+    a.slug,
+    a.title,
+    a.description,
+    a.created_at,
+    a.updated_at,
+    a.favorites_count,
+    a.comments_count,
+    a.author_id,
+    u.username as author_username,
+    u.bio as author_bio,
+    u.image as author_image
+FROM articles a
+JOIN users u ON a.author_id = u.id
+WHERE a.author_id IN (
+    SELECT followed_id FROM follows WHERE follower_id = sqlc.arg('follower_id')
+)
+    AND (sqlc.narg('before_created_at')::timestamp IS NULL OR a.created_at < sqlc.narg('before_created_at')
+        OR (a.created_at = sqlc.narg('before_created_at') AND a.id < sqlc.narg('before_id')))
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT sqlc.arg('limit')::bigint OFFSET sqlc.arg('offset')::bigint;
+
+-- name: CountArticlesFeed :one
+SELECT COUNT(*)
+FROM articles a
+WHERE a.author_id IN (
+    SELECT followed_id FROM follows WHERE follower_id = $1
+);
+
+-- name: RecountFavorites :execrows
+-- The recount queries repair the counter columns maintained by the triggers in schema.sql.
+-- They only touch rows whose counter is off, so the number of rows is the number of repairs.
+UPDATE articles SET favorites_count = (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id)
+WHERE favorites_count != (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id);
+
+-- name: RecountComments :execrows
+UPDATE articles SET comments_count = (SELECT COUNT(*) FROM comments c WHERE c.article_id = articles.id)
+WHERE comments_count != (SELECT COUNT(*) FROM comments c WHERE c.article_id = articles.id);
+
+-- name: RecountFollowers :execrows
+UPDATE users SET followers_count = (SELECT COUNT(*) FROM follows f WHERE f.followed_id = users.id)
+WHERE followers_count != (SELECT COUNT(*) FROM follows f WHERE f.followed_id = users.id);
+
+-- name: ListFeedItems :many
+-- Reads the home feed from feed_items, and pages like ListArticlesFeed, which computes the same feed from follows.
+SELECT
+    a.id,
+    a.slug,
+    a.title,
+    a.description,
+    a.created_at,
+    a.updated_at,
+    a.favorites_count,
+    a.comments_count,
+    a.author_id,
+    u.username as author_username,
+    u.bio as author_bio,
+    u.image as author_image
+FROM feed_items fi
+JOIN articles a ON fi.article_id = a.id
+JOIN users u ON a.author_id = u.id
+WHERE fi.user_id = sqlc.arg('user_id')
+    AND (sqlc.narg('before_created_at')::timestamp IS NULL OR fi.created_at < sqlc.narg('before_created_at')
+        OR (fi.created_at = sqlc.narg('before_created_at') AND fi.article_id < sqlc.narg('before_id')))
+ORDER BY fi.created_at DESC, fi.article_id DESC
+LIMIT sqlc.arg('limit')::bigint OFFSET sqlc.arg('offset')::bigint;
+
+-- name: CountFeedItems :one
+SELECT COUNT(*) FROM feed_items WHERE user_id = $1;
+
+-- name: FanOutArticle :exec
+-- The feed queries add and remove feed_items for the follows as they are when the job runs,
+-- so jobs that are retried or run out of order still leave the feeds matching the follows.
+INSERT INTO feed_items (user_id, article_id, author_id, created_at)
+SELECT f.follower_id, a.id, a.author_id, a.created_at
+FROM articles a
+JOIN follows f ON f.followed_id = a.author_id
+WHERE a.id = sqlc.arg('article_id')
+ON CONFLICT (user_id, article_id) DO NOTHING;
+
+-- name: BackfillFeed :exec
+INSERT INTO feed_items (user_id, article_id, author_id, created_at)
+SELECT f.follower_id, a.id, a.author_id, a.created_at
+FROM follows f
+JOIN articles a ON a.author_id = f.followed_id
+WHERE f.follower_id = sqlc.arg('follower_id') AND f.followed_id = sqlc.arg('author_id')
+ON CONFLICT (user_id, article_id) DO NOTHING;
+
+-- name: PruneFeed :exec
+DELETE FROM feed_items
+WHERE user_id = sqlc.arg('follower_id') AND author_id = sqlc.arg('author_id')
+    AND NOT EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = feed_items.user_id AND f.followed_id = feed_items.author_id);
+
+-- name: CreateJob :exec
+INSERT INTO jobs (kind, payload) VALUES ($1, $2);
+
+-- name: NextJob :one
+SELECT * FROM jobs
+WHERE run_at <= (now() AT TIME ZONE 'utc')
+ORDER BY run_at, id
+LIMIT 1;
+
+-- name: DeleteJob :exec
+DELETE FROM jobs WHERE id = $1;
+
+-- name: RetryJob :exec
+UPDATE jobs
+SET attempts = attempts + 1,
+    last_error = sqlc.arg('last_error'),
+    run_at = (now() AT TIME ZONE 'utc') + make_interval(secs => sqlc.arg('delay_seconds')::bigint)
+WHERE id = sqlc.arg('id');
+
+-- name: ReserveIdempotencyKey :execrows
+-- Claims the key for a new request, or takes over a key that has expired
+-- or whose request has run for over a minute, which it takes for a request that stopped without a response, such as in a restart.
+INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at)
+VALUES (sqlc.arg('user_id'), sqlc.arg('idempotency_key'), sqlc.arg('request_hash'),
+        (now() AT TIME ZONE 'utc') + make_interval(secs => sqlc.arg('ttl_seconds')::bigint))
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET request_hash = excluded.request_hash,
+    status = 0,
+    header = '',
+    body = '',
+    created_at = (now() AT TIME ZONE 'utc'),
+    expires_at = excluded.expires_at
+WHERE idempotency_keys.expires_at <= (now() AT TIME ZONE 'utc')
+   OR (idempotency_keys.status = 0
+       AND idempotency_keys.created_at <= (now() AT TIME ZONE 'utc') - interval '1 minute');
+
+-- name: GetIdempotencyKey :one
+SELECT * FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2;
+
+-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET status = sqlc.arg('status'), header = sqlc.arg('header'), body = sqlc.arg('body')
+WHERE user_id = sqlc.arg('user_id') AND idempotency_key = sqlc.arg('idempotency_key');
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE user_id = $1 AND idempotency_key = $2;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= (now() AT TIME ZONE 'utc');
+
+-- name: TakeRateLimit :one
+-- Takes a request from the bucket of key, with the generic cell rate algorithm: each request moves tat
+-- interval_ms later, from now at the earliest, unless that would take it beyond tolerance_ms from now.
+-- A new bucket is full. The update reads now, the interval and the tolerance from the excluded row,
+-- as excluded.tat is now plus the interval.
+INSERT INTO rate_limits (key, tat, interval_ms, tolerance_ms, allowed)
+VALUES (sqlc.arg('key'), sqlc.arg('now_ms')::bigint + sqlc.arg('interval_ms')::bigint, sqlc.arg('interval_ms'), sqlc.arg('tolerance_ms'), TRUE)
+ON CONFLICT (key) DO UPDATE
+SET tat = CASE
+        WHEN GREATEST(rate_limits.tat + excluded.interval_ms, excluded.tat) <= excluded.tat - excluded.interval_ms + excluded.tolerance_ms
+        THEN GREATEST(rate_limits.tat + excluded.interval_ms, excluded.tat)
+        ELSE rate_limits.tat
+    END,
+    allowed = GREATEST(rate_limits.tat + excluded.interval_ms, excluded.tat) <= excluded.tat - excluded.interval_ms + excluded.tolerance_ms,
+    interval_ms = excluded.interval_ms,
+    tolerance_ms = excluded.tolerance_ms
+RETURNING tat, allowed;
+
+-- name: DeleteExpiredRateLimits :execrows
+DELETE FROM rate_limits WHERE tat <= sqlc.arg('now_ms');
+
+-- name: CreateLoginEvent :exec
+INSERT INTO login_events (user_id, email, ip, user_agent, success) VALUES ($1, $2, $3, $4, $5);
+
+-- name: ListLoginFailures :many
+-- Lists the times of the latest failed logins to email since its last successful one, within window_seconds of now.
+SELECT created_at FROM login_events
+WHERE email = sqlc.arg('email') AND success = FALSE
+  AND created_at > (now() AT TIME ZONE 'utc') - make_interval(secs => sqlc.arg('window_seconds')::bigint)
+  AND id > (SELECT COALESCE(MAX(id), 0) FROM login_events s WHERE s.email = sqlc.arg('email') AND s.success = TRUE)
+ORDER BY id DESC
+LIMIT sqlc.arg('limit');
+
+-- name: ListLoginEvents :many
+SELECT * FROM login_events WHERE user_id = $1 ORDER BY id DESC LIMIT $2;
+
+-- name: CountUnknownLoginEvents :one
+SELECT COUNT(*) FROM login_events WHERE user_id IS NULL;
+
+-- name: DeleteOldLoginEvents :execrows
+-- Deletes the logins older than retention_seconds, and those to emails of no user older than unknown_retention_seconds.
+DELETE FROM login_events
+WHERE created_at <= (now() AT TIME ZONE 'utc') - make_interval(secs => sqlc.arg('retention_seconds')::bigint)
+   OR (user_id IS NULL AND created_at <= (now() AT TIME ZONE 'utc') - make_interval(secs => sqlc.arg('unknown_retention_seconds')::bigint));
+
+-- name: TrimUnknownLoginEvents :exec
+-- Deletes the logins to emails of no user but the latest keep.
+DELETE FROM login_events
+WHERE user_id IS NULL AND id <= (
+    SELECT id FROM login_events WHERE user_id IS NULL ORDER BY id DESC LIMIT 1 OFFSET sqlc.arg('keep')::bigint
+);
@@ -0,0 +1,310 @@
+-- Timestamps are stored as UTC in timestamp columns, the same as the datetime columns of the SQLite schema,
+-- so both backends return identical values regardless of the session time zone.
+
+CREATE TABLE IF NOT EXISTS users (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    username text NOT NULL,
+    email text NOT NULL,
+    password text NOT NULL,
+    bio text,
+    image text,
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    disabled_at timestamp,
+    followers_count BIGINT NOT NULL DEFAULT 0,
+    touched_at timestamp
+);
+
+-- Databases created before users could be disabled get the column here
+ALTER TABLE users ADD COLUMN IF NOT EXISTS disabled_at timestamp;
+
+CREATE OR REPLACE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+    NEW.updated_at = now() AT TIME ZONE 'utc';
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER update_users_updated_at
+    BEFORE UPDATE OF username, email, password, bio, image ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION set_updated_at();
+
+CREATE TABLE IF NOT EXISTS follows (
+    follower_id BIGINT NOT NULL,
+    followed_id BIGINT NOT NULL,
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    PRIMARY KEY (follower_id, followed_id),
+    FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (followed_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_follows_followed_id ON follows(followed_id);
+
+-- Counter columns are kept in step by triggers, which also fire for rows removed by ON DELETE CASCADE.
+CREATE OR REPLACE FUNCTION count_follows() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET followers_count = followers_count + 1 WHERE id = NEW.followed_id;
+    ELSE
+        UPDATE users SET followers_count = followers_count - 1 WHERE id = OLD.followed_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER follows_count
+    AFTER INSERT OR DELETE ON follows
+    FOR EACH ROW
+    EXECUTE FUNCTION count_follows();
+
+CREATE TABLE IF NOT EXISTS tags (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name text NOT NULL UNIQUE,
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+CREATE TABLE IF NOT EXISTS articles (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    slug text NOT NULL UNIQUE,
+    title text NOT NULL,
+    description text NOT NULL,
+    body text NOT NULL,
+    author_id BIGINT NOT NULL,
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    favorites_count BIGINT NOT NULL DEFAULT 0,
+    comments_count BIGINT NOT NULL DEFAULT 0,
+    touched_at timestamp,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE TRIGGER update_articles_updated_at
+    BEFORE UPDATE OF title, description, body ON articles
+    FOR EACH ROW
+    EXECUTE FUNCTION set_updated_at();
+
+CREATE INDEX IF NOT EXISTS idx_articles_author_id ON articles(author_id);
+-- Lists page by (created_at, id), replacing the created_at-only index of older databases
+DROP INDEX IF EXISTS idx_articles_created_at;
+CREATE INDEX IF NOT EXISTS idx_articles_created_at_id ON articles(created_at DESC, id DESC);
+
+CREATE TABLE IF NOT EXISTS article_tags (
+    article_id BIGINT NOT NULL,
+    tag_id BIGINT NOT NULL,
+    PRIMARY KEY (article_id, tag_id),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_article_tags_tag_id ON article_tags(tag_id);
+
+CREATE TABLE IF NOT EXISTS favorites (
+    user_id BIGINT NOT NULL,
+    article_id BIGINT NOT NULL,
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    PRIMARY KEY (user_id, article_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_favorites_article_id ON favorites(article_id);
+
+CREATE OR REPLACE FUNCTION count_favorites() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE articles SET favorites_count = favorites_count + 1 WHERE id = NEW.article_id;
+    ELSE
+        UPDATE articles SET favorites_count = favorites_count - 1 WHERE id = OLD.article_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER favorites_count
+    AFTER INSERT OR DELETE ON favorites
+    FOR EACH ROW
+    EXECUTE FUNCTION count_favorites();
+
+CREATE TABLE IF NOT EXISTS comments (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    body text NOT NULL,
+    article_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    updated_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE,
+    FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE OR REPLACE TRIGGER update_comments_updated_at
+    BEFORE UPDATE OF body ON comments
+    FOR EACH ROW
+    EXECUTE FUNCTION set_updated_at();
+
+CREATE INDEX IF NOT EXISTS idx_comments_article_id ON comments(article_id);
+CREATE INDEX IF NOT EXISTS idx_comments_author_id ON comments(author_id);
+
+CREATE OR REPLACE FUNCTION count_comments() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE articles SET comments_count = comments_count + 1 WHERE id = NEW.article_id;
+    ELSE
+        UPDATE articles SET comments_count = comments_count - 1 WHERE id = OLD.article_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER comments_count
+    AFTER INSERT OR DELETE ON comments
+    FOR EACH ROW
+    EXECUTE FUNCTION count_comments();
+
+-- Databases created before the counter columns get them here, counted once from the existing rows.
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT FROM information_schema.columns
+        WHERE table_schema = current_schema() AND table_name = 'articles' AND column_name = 'favorites_count'
+    ) THEN
+        ALTER TABLE articles
+            ADD COLUMN favorites_count BIGINT NOT NULL DEFAULT 0,
+            ADD COLUMN comments_count BIGINT NOT NULL DEFAULT 0;
+        ALTER TABLE users ADD COLUMN followers_count BIGINT NOT NULL DEFAULT 0;
+        UPDATE articles SET
+            favorites_count = (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id),
+            comments_count = (SELECT COUNT(*) FROM comments c WHERE c.article_id = articles.id);
+        UPDATE users SET followers_count = (SELECT COUNT(*) FROM follows f WHERE f.followed_id = users.id);
+    END IF;
+END;
+$$;
+
+-- touched_at is the last time a response about the row changed without updated_at: the favorites, comments and tags
+-- of an article, and the followers of a user. Together with updated_at it gives the Last-Modified of those responses.
+-- Databases created before it get the columns here, after the counter columns to keep the order of the columns.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS touched_at timestamp;
+ALTER TABLE articles ADD COLUMN IF NOT EXISTS touched_at timestamp;
+
+CREATE OR REPLACE FUNCTION touch_article() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE articles SET touched_at = now() AT TIME ZONE 'utc' WHERE id = NEW.article_id;
+    ELSE
+        UPDATE articles SET touched_at = now() AT TIME ZONE 'utc' WHERE id = OLD.article_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION touch_user() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        UPDATE users SET touched_at = now() AT TIME ZONE 'utc' WHERE id = NEW.followed_id;
+    ELSE
+        UPDATE users SET touched_at = now() AT TIME ZONE 'utc' WHERE id = OLD.followed_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE TRIGGER favorites_touch
+    AFTER INSERT OR DELETE ON favorites
+    FOR EACH ROW
+    EXECUTE FUNCTION touch_article();
+
+CREATE OR REPLACE TRIGGER comments_touch
+    AFTER INSERT OR DELETE ON comments
+    FOR EACH ROW
+    EXECUTE FUNCTION touch_article();
+
+CREATE OR REPLACE TRIGGER article_tags_touch
+    AFTER INSERT OR DELETE ON article_tags
+    FOR EACH ROW
+    EXECUTE FUNCTION touch_article();
+
+CREATE OR REPLACE TRIGGER follows_touch
+    AFTER INSERT OR DELETE ON follows
+    FOR EACH ROW
+    EXECUTE FUNCTION touch_user();
+
+-- feed_items is the home feed of each user, written ahead of reads: an article is fanned out to the followers
+-- of its author when it is published, and a follow or unfollow backfills or prunes the articles of that author.
+-- The jobs table queues these writes for the job runner.
+CREATE TABLE IF NOT EXISTS feed_items (
+    user_id BIGINT NOT NULL,
+    article_id BIGINT NOT NULL,
+    author_id BIGINT NOT NULL,
+    created_at timestamp NOT NULL,
+    PRIMARY KEY (user_id, article_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_items_user_id_created_at ON feed_items(user_id, created_at DESC, article_id DESC);
+
+-- Databases created before feed_items start with an empty table, filled here once from the current follows.
+-- An empty table with follows of authors with articles means exactly that, so this is a no-op on later starts.
+INSERT INTO feed_items (user_id, article_id, author_id, created_at)
+SELECT f.follower_id, a.id, a.author_id, a.created_at
+FROM follows f
+JOIN articles a ON a.author_id = f.followed_id
+WHERE NOT EXISTS (SELECT 1 FROM feed_items)
+ON CONFLICT (user_id, article_id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    kind text NOT NULL,
+    payload text NOT NULL,
+    attempts BIGINT NOT NULL DEFAULT 0,
+    last_error text,
+    run_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_run_at ON jobs(run_at, id);
+
+-- idempotency_keys holds the first response to each Idempotency-Key of a user, which retries of the same request replay
+-- until expires_at. status is 0 while the first request is still running.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    idempotency_key text NOT NULL,
+    request_hash text NOT NULL,
+    status BIGINT NOT NULL DEFAULT 0,
+    header text NOT NULL DEFAULT '',
+    body text NOT NULL DEFAULT '',
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc'),
+    expires_at timestamp NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- rate_limits holds a token bucket for each key of a rate limit policy, such as the client IP of login requests,
+-- as its theoretical arrival time (tat) in unix milliseconds: the bucket is full from then on, and requests are
+-- allowed while tat stays within tolerance_ms of now. Rows whose tat is past are full buckets and can be deleted.
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key text PRIMARY KEY,
+    tat BIGINT NOT NULL,
+    interval_ms BIGINT NOT NULL,
+    tolerance_ms BIGINT NOT NULL,
+    allowed BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_tat ON rate_limits(tat);
+
+-- login_events records each attempt to log in to an account by email, including emails of no user, for users to review
+-- and to throttle guessing: the failed attempts since the last successful one delay the next attempts, see ListLoginFailures.
+CREATE TABLE IF NOT EXISTS login_events (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    email text NOT NULL,
+    ip text NOT NULL,
+    user_agent text NOT NULL,
+    success BOOLEAN NOT NULL,
+    created_at timestamp NOT NULL DEFAULT (now() AT TIME ZONE 'utc')
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_email ON login_events(email, id);
+CREATE INDEX IF NOT EXISTS idx_login_events_user_id ON login_events(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at);
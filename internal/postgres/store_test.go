@@ -0,0 +1,133 @@
+package postgres_test
+
+import (
+	"database/sql"
+	_ "embed"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/internal/postgres"
+	"github.com/raeperd/realworld.go/internal/store"
+	"github.com/raeperd/realworld.go/internal/store/storetest"
+)
+
+//go:embed schema.sql
+var schema string
+
+// dsn is the server [TestStore] runs against, and clusterErr why [TestMain] could not start one when dsn is empty.
+var (
+	dsn        string
+	clusterErr error
+)
+
+// TestMain starts a throwaway PostgreSQL cluster for the tests with initdb and pg_ctl, in a temporary directory
+// and listening on a Unix socket only, so the suite needs no container and no server started by hand.
+// REALWORLD_TEST_POSTGRES_DSN points the tests at an existing server instead:
+//
+//	REALWORLD_TEST_POSTGRES_DSN='postgres://postgres@localhost:5432/postgres?sslmode=disable' go test ./internal/postgres
+func TestMain(m *testing.M) {
+	dsn = os.Getenv("REALWORLD_TEST_POSTGRES_DSN")
+	var stop func()
+	if dsn == "" {
+		dsn, stop, clusterErr = startCluster()
+	}
+	code := m.Run()
+	if stop != nil {
+		stop()
+	}
+	os.Exit(code)
+}
+
+// startCluster initializes and starts a cluster in a temporary directory, and returns its DSN
+// and a function that stops the cluster and removes the directory.
+func startCluster() (string, func(), error) {
+	initdb, err := postgresBinary("initdb")
+	if err != nil {
+		return "", nil, err
+	}
+	pgCtl, err := postgresBinary("pg_ctl")
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The socket path must fit in sockaddr_un, so the directory name is kept short
+	dir, err := os.MkdirTemp("", "rwpg-")
+	if err != nil {
+		return "", nil, err
+	}
+	data := filepath.Join(dir, "data")
+	remove := func() { _ = os.RemoveAll(dir) }
+
+	out, err := exec.Command(initdb, "-D", data, "-U", "postgres", "--auth=trust", "--encoding=UTF8", "--no-sync").CombinedOutput()
+	if err != nil {
+		remove()
+		return "", nil, fmt.Errorf("initdb: %w\n%s", err, out)
+	}
+	// fsync is off since the cluster is thrown away, and listen_addresses is empty so no TCP port can clash
+	options := fmt.Sprintf("-k %s -c listen_addresses='' -c fsync=off", dir)
+	out, err = exec.Command(pgCtl, "-D", data, "-l", filepath.Join(dir, "postgres.log"), "-o", options, "-w", "start").CombinedOutput()
+	if err != nil {
+		remove()
+		return "", nil, fmt.Errorf("pg_ctl start: %w\n%s", err, out)
+	}
+
+	stop := func() {
+		if out, err := exec.Command(pgCtl, "-D", data, "-m", "immediate", "-w", "stop").CombinedOutput(); err != nil {
+			log.Printf("pg_ctl stop: %v\n%s", err, out)
+		}
+		remove()
+	}
+	return fmt.Sprintf("host=%s user=postgres dbname=postgres sslmode=disable", dir), stop, nil
+}
+
+// postgresBinary looks up a PostgreSQL server binary on PATH, then in the versioned directories of Debian and Ubuntu
+// packages, which keep them off PATH.
+func postgresBinary(name string) (string, error) {
+	if path, err := exec.LookPath(name); err == nil {
+		return path, nil
+	}
+	matches, _ := filepath.Glob(filepath.Join("/usr/lib/postgresql", "*", "bin", name))
+	if len(matches) > 0 {
+		return matches[len(matches)-1], nil // the latest version
+	}
+	return "", errors.New(name + " not found on PATH or in /usr/lib/postgresql, install the PostgreSQL server or set REALWORLD_TEST_POSTGRES_DSN")
+}
+
+// TestStore runs the conformance suite against the cluster of [TestMain].
+// Without PostgreSQL it is skipped locally, but fails in CI so that the backend cannot go untested there.
+// Every subtest recreates the realworld_test schema, leaving the rest of the database alone.
+func TestStore(t *testing.T) {
+	if dsn == "" {
+		if os.Getenv("CI") != "" {
+			t.Fatalf("no PostgreSQL server for the conformance suite: %v", clusterErr)
+		}
+		t.Skipf("no PostgreSQL server: %v", clusterErr)
+	}
+
+	storetest.Run(t, func(t *testing.T) store.Store {
+		db, err := sql.Open("postgres", dsn)
+		test.Nil(t, err)
+		// A single connection keeps the search_path set below for every query
+		db.SetMaxOpenConns(1)
+		for _, stmt := range []string{
+			"DROP SCHEMA IF EXISTS realworld_test CASCADE",
+			"CREATE SCHEMA realworld_test",
+			"SET search_path TO realworld_test",
+			schema,
+		} {
+			_, err := db.ExecContext(t.Context(), stmt)
+			test.Nil(t, err)
+		}
+
+		s := postgres.NewStore(db)
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	})
+}
@@ -10,14 +10,17 @@ import (
 )
 
 type Article struct {
-	ID          int64
-	Slug        string
-	Title       string
-	Description string
-	Body        string
-	AuthorID    int64
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
+	ID             int64
+	Slug           string
+	Title          string
+	Description    string
+	Body           string
+	AuthorID       int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
+	TouchedAt      sql.NullTime
 }
 
 type ArticleTag struct {
@@ -40,25 +43,74 @@ type Favorite struct {
 	CreatedAt time.Time
 }
 
+type FeedItem struct {
+	UserID    int64
+	ArticleID int64
+	AuthorID  int64
+	CreatedAt time.Time
+}
+
 type Follow struct {
 	FollowerID int64
 	FollowedID int64
 	CreatedAt  time.Time
 }
 
-type Tag struct {
+type IdempotencyKey struct {
+	UserID         int64
+	IdempotencyKey string
+	RequestHash    string
+	Status         int64
+	Header         string
+	Body           string
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+type Job struct {
 	ID        int64
-	Name      string
+	Kind      string
+	Payload   string
+	Attempts  int64
+	LastError sql.NullString
+	RunAt     time.Time
 	CreatedAt time.Time
 }
 
-type User struct {
+type LoginEvent struct {
 	ID        int64
-	Username  string
+	UserID    sql.NullInt64
 	Email     string
-	Password  string
-	Bio       sql.NullString
-	Image     sql.NullString
+	Ip        string
+	UserAgent string
+	Success   bool
 	CreatedAt time.Time
-	UpdatedAt time.Time
+}
+
+type RateLimit struct {
+	Key         string
+	Tat         int64
+	IntervalMs  int64
+	ToleranceMs int64
+	Allowed     bool
+}
+
+type Tag struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+type User struct {
+	ID             int64
+	Username       string
+	Email          string
+	Password       string
+	Bio            sql.NullString
+	Image          sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DisabledAt     sql.NullTime
+	FollowersCount int64
+	TouchedAt      sql.NullTime
 }
@@ -26,6 +26,25 @@ func (q *Queries) AssociateArticleTag(ctx context.Context, arg AssociateArticleT
 	return err
 }
 
+const backfillFeed = `-- name: BackfillFeed :exec
+INSERT INTO feed_items (user_id, article_id, author_id, created_at)
+SELECT f.follower_id, a.id, a.author_id, a.created_at
+FROM follows f
+JOIN articles a ON a.author_id = f.followed_id
+WHERE f.follower_id = ?1 AND f.followed_id = ?2
+ON CONFLICT (user_id, article_id) DO NOTHING
+`
+
+type BackfillFeedParams struct {
+	FollowerID int64
+	AuthorID   int64
+}
+
+func (q *Queries) BackfillFeed(ctx context.Context, arg BackfillFeedParams) error {
+	_, err := q.db.ExecContext(ctx, backfillFeed, arg.FollowerID, arg.AuthorID)
+	return err
+}
+
 const checkFavoritedByUser = `-- name: CheckFavoritedByUser :many
 SELECT article_id
 FROM favorites
@@ -72,11 +91,26 @@ func (q *Queries) CheckFavoritedByUser(ctx context.Context, arg CheckFavoritedBy
 }
 
 const countArticles = `-- name: CountArticles :one
-SELECT COUNT(*) FROM articles
+SELECT COUNT(*)
+FROM articles a
+JOIN users u ON a.author_id = u.id
+WHERE (CAST(?1 AS TEXT) IS NULL OR a.id IN (
+        SELECT at.article_id FROM article_tags at JOIN tags t ON at.tag_id = t.id WHERE t.name = ?1
+    ))
+    AND (CAST(?2 AS TEXT) IS NULL OR u.username = ?2)
+    AND (CAST(?3 AS TEXT) IS NULL OR a.id IN (
+        SELECT f.article_id FROM favorites f JOIN users fu ON f.user_id = fu.id WHERE fu.username = ?3
+    ))
 `
 
-func (q *Queries) CountArticles(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countArticles)
+type CountArticlesParams struct {
+	Tag       sql.NullString
+	Author    sql.NullString
+	Favorited sql.NullString
+}
+
+func (q *Queries) CountArticles(ctx context.Context, arg CountArticlesParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countArticles, arg.Tag, arg.Author, arg.Favorited)
 	var count int64
 	err := row.Scan(&count)
 	return count, err
@@ -97,10 +131,32 @@ func (q *Queries) CountArticlesFeed(ctx context.Context, followerID int64) (int6
 	return count, err
 }
 
+const countFeedItems = `-- name: CountFeedItems :one
+SELECT COUNT(*) FROM feed_items WHERE user_id = ?
+`
+
+func (q *Queries) CountFeedItems(ctx context.Context, userID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedItems, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countUnknownLoginEvents = `-- name: CountUnknownLoginEvents :one
+SELECT COUNT(*) FROM login_events WHERE user_id IS NULL
+`
+
+func (q *Queries) CountUnknownLoginEvents(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnknownLoginEvents)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createArticle = `-- name: CreateArticle :one
 INSERT INTO articles (slug, title, description, body, author_id)
 VALUES (?, ?, ?, ?, ?)
-RETURNING id, slug, title, description, body, author_id, created_at, updated_at
+RETURNING id, slug, title, description, body, author_id, created_at, updated_at, favorites_count, comments_count, touched_at
 `
 
 type CreateArticleParams struct {
@@ -129,6 +185,9 @@ func (q *Queries) CreateArticle(ctx context.Context, arg CreateArticleParams) (A
 		&i.AuthorID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FavoritesCount,
+		&i.CommentsCount,
+		&i.TouchedAt,
 	)
 	return i, err
 }
@@ -189,8 +248,45 @@ func (q *Queries) CreateFollow(ctx context.Context, arg CreateFollowParams) erro
 	return err
 }
 
+const createJob = `-- name: CreateJob :exec
+INSERT INTO jobs (kind, payload) VALUES (?, ?)
+`
+
+type CreateJobParams struct {
+	Kind    string
+	Payload string
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) error {
+	_, err := q.db.ExecContext(ctx, createJob, arg.Kind, arg.Payload)
+	return err
+}
+
+const createLoginEvent = `-- name: CreateLoginEvent :exec
+INSERT INTO login_events (user_id, email, ip, user_agent, success) VALUES (?, ?, ?, ?, ?)
+`
+
+type CreateLoginEventParams struct {
+	UserID    sql.NullInt64
+	Email     string
+	Ip        string
+	UserAgent string
+	Success   bool
+}
+
+func (q *Queries) CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) error {
+	_, err := q.db.ExecContext(ctx, createLoginEvent,
+		arg.UserID,
+		arg.Email,
+		arg.Ip,
+		arg.UserAgent,
+		arg.Success,
+	)
+	return err
+}
+
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (username, email, password, bio, image) VALUES (?, ?, ?, ?, ?) RETURNING id, username, email, password, bio, image, created_at, updated_at
+INSERT INTO users (username, email, password, bio, image) VALUES (?, ?, ?, ?, ?) RETURNING id, username, email, password, bio, image, created_at, updated_at, disabled_at, followers_count, touched_at
 `
 
 type CreateUserParams struct {
@@ -219,6 +315,9 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.Image,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DisabledAt,
+		&i.FollowersCount,
+		&i.TouchedAt,
 	)
 	return i, err
 }
@@ -242,6 +341,30 @@ func (q *Queries) DeleteComment(ctx context.Context, id int64) error {
 	return err
 }
 
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys WHERE expires_at <= CURRENT_TIMESTAMP
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteExpiredRateLimits = `-- name: DeleteExpiredRateLimits :execrows
+DELETE FROM rate_limits WHERE tat <= ?1
+`
+
+func (q *Queries) DeleteExpiredRateLimits(ctx context.Context, nowMs int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteExpiredRateLimits, nowMs)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteFavorite = `-- name: DeleteFavorite :exec
 DELETE FROM favorites WHERE user_id = ? AND article_id = ?
 `
@@ -270,6 +393,83 @@ func (q *Queries) DeleteFollow(ctx context.Context, arg DeleteFollowParams) erro
 	return err
 }
 
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID         int64
+	IdempotencyKey string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deleteIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	return err
+}
+
+const deleteJob = `-- name: DeleteJob :exec
+DELETE FROM jobs WHERE id = ?
+`
+
+func (q *Queries) DeleteJob(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteJob, id)
+	return err
+}
+
+const deleteOldLoginEvents = `-- name: DeleteOldLoginEvents :execrows
+DELETE FROM login_events
+WHERE created_at <= DATETIME('now', '-' || CAST(?1 AS INTEGER) || ' seconds')
+   OR (user_id IS NULL AND created_at <= DATETIME('now', '-' || CAST(?2 AS INTEGER) || ' seconds'))
+`
+
+type DeleteOldLoginEventsParams struct {
+	RetentionSeconds        int64
+	UnknownRetentionSeconds int64
+}
+
+// Deletes the logins older than retention_seconds, and those to emails of no user older than unknown_retention_seconds.
+func (q *Queries) DeleteOldLoginEvents(ctx context.Context, arg DeleteOldLoginEventsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteOldLoginEvents, arg.RetentionSeconds, arg.UnknownRetentionSeconds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteTag = `-- name: DeleteTag :exec
+DELETE FROM tags WHERE id = ?
+`
+
+func (q *Queries) DeleteTag(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteTag, id)
+	return err
+}
+
+const disableUser = `-- name: DisableUser :exec
+UPDATE users SET disabled_at = CURRENT_TIMESTAMP WHERE id = ? AND disabled_at IS NULL
+`
+
+func (q *Queries) DisableUser(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, disableUser, id)
+	return err
+}
+
+const fanOutArticle = `-- name: FanOutArticle :exec
+INSERT INTO feed_items (user_id, article_id, author_id, created_at)
+SELECT f.follower_id, a.id, a.author_id, a.created_at
+FROM articles a
+JOIN follows f ON f.followed_id = a.author_id
+WHERE a.id = ?1
+ON CONFLICT (user_id, article_id) DO NOTHING
+`
+
+// The feed queries add and remove feed_items for the follows as they are when the job runs,
+// so jobs that are retried or run out of order still leave the feeds matching the follows.
+func (q *Queries) FanOutArticle(ctx context.Context, articleID int64) error {
+	_, err := q.db.ExecContext(ctx, fanOutArticle, articleID)
+	return err
+}
+
 const getAllTags = `-- name: GetAllTags :many
 SELECT name FROM tags ORDER BY name
 `
@@ -299,27 +499,34 @@ func (q *Queries) GetAllTags(ctx context.Context) ([]string, error) {
 
 const getArticleBySlug = `-- name: GetArticleBySlug :one
 SELECT
-    a.id, a.slug, a.title, a.description, a.body, a.author_id, a.created_at, a.updated_at,
+    a.id, a.slug, a.title, a.description, a.body, a.author_id, a.created_at, a.updated_at, a.favorites_count, a.comments_count, a.touched_at,
     u.username as author_username,
     u.bio as author_bio,
-    u.image as author_image
+    u.image as author_image,
+    u.updated_at as author_updated_at,
+    u.touched_at as author_touched_at
 FROM articles a
 JOIN users u ON a.author_id = u.id
 WHERE a.slug = ?
 `
 
 type GetArticleBySlugRow struct {
-	ID             int64
-	Slug           string
-	Title          string
-	Description    string
-	Body           string
-	AuthorID       int64
-	CreatedAt      time.Time
-	UpdatedAt      time.Time
-	AuthorUsername string
-	AuthorBio      sql.NullString
-	AuthorImage    sql.NullString
+	ID              int64
+	Slug            string
+	Title           string
+	Description     string
+	Body            string
+	AuthorID        int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	FavoritesCount  int64
+	CommentsCount   int64
+	TouchedAt       sql.NullTime
+	AuthorUsername  string
+	AuthorBio       sql.NullString
+	AuthorImage     sql.NullString
+	AuthorUpdatedAt time.Time
+	AuthorTouchedAt sql.NullTime
 }
 
 func (q *Queries) GetArticleBySlug(ctx context.Context, slug string) (GetArticleBySlugRow, error) {
@@ -334,9 +541,14 @@ func (q *Queries) GetArticleBySlug(ctx context.Context, slug string) (GetArticle
 		&i.AuthorID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FavoritesCount,
+		&i.CommentsCount,
+		&i.TouchedAt,
 		&i.AuthorUsername,
 		&i.AuthorBio,
 		&i.AuthorImage,
+		&i.AuthorUpdatedAt,
+		&i.AuthorTouchedAt,
 	)
 	return i, err
 }
@@ -538,60 +750,15 @@ func (q *Queries) GetCommentsByArticleSlug(ctx context.Context, slug string) ([]
 	return items, nil
 }
 
-const getFavoritesByArticleIDs = `-- name: GetFavoritesByArticleIDs :many
-SELECT article_id, COUNT(*) as count
-FROM favorites
-WHERE article_id IN (/*SLICE:article_ids*/?)
-GROUP BY article_id
-`
-
-type GetFavoritesByArticleIDsRow struct {
-	ArticleID int64
-	Count     int64
-}
-
-func (q *Queries) GetFavoritesByArticleIDs(ctx context.Context, articleIds []int64) ([]GetFavoritesByArticleIDsRow, error) {
-	query := getFavoritesByArticleIDs
-	var queryParams []interface{}
-	if len(articleIds) > 0 {
-		for _, v := range articleIds {
-			queryParams = append(queryParams, v)
-		}
-		query = strings.Replace(query, "/*SLICE:article_ids*/?", strings.Repeat(",?", len(articleIds))[1:], 1)
-	} else {
-		query = strings.Replace(query, "/*SLICE:article_ids*/?", "NULL", 1)
-	}
-	rows, err := q.db.QueryContext(ctx, query, queryParams...)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetFavoritesByArticleIDsRow
-	for rows.Next() {
-		var i GetFavoritesByArticleIDsRow
-		if err := rows.Scan(&i.ArticleID, &i.Count); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Close(); err != nil {
-		return nil, err
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-	return items, nil
-}
-
 const getFavoritesCount = `-- name: GetFavoritesCount :one
-SELECT COUNT(*) FROM favorites WHERE article_id = ?
+SELECT favorites_count FROM articles WHERE id = ?
 `
 
-func (q *Queries) GetFavoritesCount(ctx context.Context, articleID int64) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getFavoritesCount, articleID)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *Queries) GetFavoritesCount(ctx context.Context, id int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getFavoritesCount, id)
+	var favorites_count int64
+	err := row.Scan(&favorites_count)
+	return favorites_count, err
 }
 
 const getFollowingByIDs = `-- name: GetFollowingByIDs :many
@@ -638,6 +805,31 @@ func (q *Queries) GetFollowingByIDs(ctx context.Context, arg GetFollowingByIDsPa
 	return items, nil
 }
 
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT user_id, idempotency_key, request_hash, status, header, body, created_at, expires_at FROM idempotency_keys WHERE user_id = ? AND idempotency_key = ?
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID         int64
+	IdempotencyKey string
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRowContext(ctx, getIdempotencyKey, arg.UserID, arg.IdempotencyKey)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.IdempotencyKey,
+		&i.RequestHash,
+		&i.Status,
+		&i.Header,
+		&i.Body,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
 const getOrCreateTag = `-- name: GetOrCreateTag :one
 INSERT INTO tags (name) VALUES (?)
 ON CONFLICT(name) DO UPDATE SET name=name
@@ -653,8 +845,19 @@ func (q *Queries) GetOrCreateTag(ctx context.Context, name string) (Tag, error)
 	return i, err
 }
 
+const getTagByName = `-- name: GetTagByName :one
+SELECT id, name, created_at FROM tags WHERE name = ?
+`
+
+func (q *Queries) GetTagByName(ctx context.Context, name string) (Tag, error) {
+	row := q.db.QueryRowContext(ctx, getTagByName, name)
+	var i Tag
+	err := row.Scan(&i.ID, &i.Name, &i.CreatedAt)
+	return i, err
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password, bio, image, created_at, updated_at FROM users WHERE email = ?
+SELECT id, username, email, password, bio, image, created_at, updated_at, disabled_at, followers_count, touched_at FROM users WHERE email = ?
 `
 
 func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
@@ -669,12 +872,15 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Image,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DisabledAt,
+		&i.FollowersCount,
+		&i.TouchedAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, password, bio, image, created_at, updated_at FROM users WHERE id = ?
+SELECT id, username, email, password, bio, image, created_at, updated_at, disabled_at, followers_count, touched_at FROM users WHERE id = ?
 `
 
 func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
@@ -689,12 +895,15 @@ func (q *Queries) GetUserByID(ctx context.Context, id int64) (User, error) {
 		&i.Image,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DisabledAt,
+		&i.FollowersCount,
+		&i.TouchedAt,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, password, bio, image, created_at, updated_at FROM users WHERE username = ?
+SELECT id, username, email, password, bio, image, created_at, updated_at, disabled_at, followers_count, touched_at FROM users WHERE username = ?
 `
 
 func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
@@ -709,12 +918,15 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User,
 		&i.Image,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DisabledAt,
+		&i.FollowersCount,
+		&i.TouchedAt,
 	)
 	return i, err
 }
 
 const isFavorited = `-- name: IsFavorited :one
-SELECT EXISTS(SELECT 1 FROM favorites WHERE user_id = ? AND article_id = ?)
+SELECT CAST(EXISTS(SELECT 1 FROM favorites WHERE user_id = ? AND article_id = ?) AS BOOLEAN)
 `
 
 type IsFavoritedParams struct {
@@ -722,15 +934,15 @@ type IsFavoritedParams struct {
 	ArticleID int64
 }
 
-func (q *Queries) IsFavorited(ctx context.Context, arg IsFavoritedParams) (int64, error) {
+func (q *Queries) IsFavorited(ctx context.Context, arg IsFavoritedParams) (bool, error) {
 	row := q.db.QueryRowContext(ctx, isFavorited, arg.UserID, arg.ArticleID)
-	var column_1 int64
+	var column_1 bool
 	err := row.Scan(&column_1)
 	return column_1, err
 }
 
 const isFollowing = `-- name: IsFollowing :one
-SELECT EXISTS(SELECT 1 FROM follows WHERE follower_id = ? AND followed_id = ?)
+SELECT CAST(EXISTS(SELECT 1 FROM follows WHERE follower_id = ? AND followed_id = ?) AS BOOLEAN)
 `
 
 type IsFollowingParams struct {
@@ -738,9 +950,9 @@ type IsFollowingParams struct {
 	FollowedID int64
 }
 
-func (q *Queries) IsFollowing(ctx context.Context, arg IsFollowingParams) (int64, error) {
+func (q *Queries) IsFollowing(ctx context.Context, arg IsFollowingParams) (bool, error) {
 	row := q.db.QueryRowContext(ctx, isFollowing, arg.FollowerID, arg.FollowedID)
-	var column_1 int64
+	var column_1 bool
 	err := row.Scan(&column_1)
 	return column_1, err
 }
@@ -753,19 +965,35 @@ SELECT
     a.description,
     a.created_at,
     a.updated_at,
+    a.favorites_count,
+    a.comments_count,
     a.author_id,
     u.username as author_username,
     u.bio as author_bio,
     u.image as author_image
 FROM articles a
 JOIN users u ON a.author_id = u.id
-ORDER BY a.created_at DESC
-LIMIT ? OFFSET ?
+WHERE (CAST(?1 AS TEXT) IS NULL OR a.id IN (
+        SELECT at.article_id FROM article_tags at JOIN tags t ON at.tag_id = t.id WHERE t.name = ?1
+    ))
+    AND (CAST(?2 AS TEXT) IS NULL OR u.username = ?2)
+    AND (CAST(?3 AS TEXT) IS NULL OR a.id IN (
+        SELECT f.article_id FROM favorites f JOIN users fu ON f.user_id = fu.id WHERE fu.username = ?3
+    ))
+    AND (CAST(?4 AS TEXT) IS NULL OR a.created_at < ?4
+        OR (a.created_at = ?4 AND a.id < ?5))
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT ?7 OFFSET ?6
 `
 
 type ListArticlesParams struct {
-	Limit  int64
-	Offset int64
+	Tag             sql.NullString
+	Author          sql.NullString
+	Favorited       sql.NullString
+	BeforeCreatedAt sql.NullString
+	BeforeID        sql.NullInt64
+	Offset          int64
+	Limit           int64
 }
 
 type ListArticlesRow struct {
@@ -775,14 +1003,26 @@ type ListArticlesRow struct {
 	Description    string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
 	AuthorID       int64
 	AuthorUsername string
 	AuthorBio      sql.NullString
 	AuthorImage    sql.NullString
 }
 
+// Each filter is skipped when NULL, so one query serves the plain list and every combination of filters.
+// before_created_at and before_id continue after the last article of the previous page, ordered by (created_at, id).
 func (q *Queries) ListArticles(ctx context.Context, arg ListArticlesParams) ([]ListArticlesRow, error) {
-	rows, err := q.db.QueryContext(ctx, listArticles, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listArticles,
+		arg.Tag,
+		arg.Author,
+		arg.Favorited,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -797,6 +1037,8 @@ func (q *Queries) ListArticles(ctx context.Context, arg ListArticlesParams) ([]L
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FavoritesCount,
+			&i.CommentsCount,
 			&i.AuthorID,
 			&i.AuthorUsername,
 			&i.AuthorBio,
@@ -823,6 +1065,8 @@ SELECT
     a.description,
     a.created_at,
     a.updated_at,
+    a.favorites_count,
+    a.comments_count,
     a.author_id,
     u.username as author_username,
     u.bio as author_bio,
@@ -830,16 +1074,20 @@ SELECT
 FROM articles a
 JOIN users u ON a.author_id = u.id
 WHERE a.author_id IN (
-    SELECT followed_id FROM follows WHERE follower_id = ?
+    SELECT followed_id FROM follows WHERE follower_id = ?1
 )
-ORDER BY a.created_at DESC
-LIMIT ? OFFSET ?
+    AND (CAST(?2 AS TEXT) IS NULL OR a.created_at < ?2
+        OR (a.created_at = ?2 AND a.id < ?3))
+ORDER BY a.created_at DESC, a.id DESC
+LIMIT ?5 OFFSET ?4
 `
 
 type ListArticlesFeedParams struct {
-	FollowerID int64
-	Limit      int64
-	Offset     int64
+	FollowerID      int64
+	BeforeCreatedAt sql.NullString
+	BeforeID        sql.NullInt64
+	Offset          int64
+	Limit           int64
 }
 
 type ListArticlesFeedRow struct {
@@ -849,14 +1097,23 @@ type ListArticlesFeedRow struct {
 	Description    string
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
 	AuthorID       int64
 	AuthorUsername string
 	AuthorBio      sql.NullString
 	AuthorImage    sql.NullString
 }
 
+// Paginates like ListArticles.
 func (q *Queries) ListArticlesFeed(ctx context.Context, arg ListArticlesFeedParams) ([]ListArticlesFeedRow, error) {
-	rows, err := q.db.QueryContext(ctx, listArticlesFeed, arg.FollowerID, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listArticlesFeed,
+		arg.FollowerID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Offset,
+		arg.Limit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -871,6 +1128,98 @@ func (q *Queries) ListArticlesFeed(ctx context.Context, arg ListArticlesFeedPara
 			&i.Description,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.FavoritesCount,
+			&i.CommentsCount,
+			&i.AuthorID,
+			&i.AuthorUsername,
+			&i.AuthorBio,
+			&i.AuthorImage,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listFeedItems = `-- name: ListFeedItems :many
+SELECT
+    a.id,
+    a.slug,
+    a.title,
+    a.description,
+    a.created_at,
+    a.updated_at,
+    a.favorites_count,
+    a.comments_count,
+    a.author_id,
+    u.username as author_username,
+    u.bio as author_bio,
+    u.image as author_image
+FROM feed_items fi
+JOIN articles a ON fi.article_id = a.id
+JOIN users u ON a.author_id = u.id
+WHERE fi.user_id = ?1
+    AND (CAST(?2 AS TEXT) IS NULL OR fi.created_at < ?2
+        OR (fi.created_at = ?2 AND fi.article_id < ?3))
+ORDER BY fi.created_at DESC, fi.article_id DESC
+LIMIT ?5 OFFSET ?4
+`
+
+type ListFeedItemsParams struct {
+	UserID          int64
+	BeforeCreatedAt sql.NullString
+	BeforeID        sql.NullInt64
+	Offset          int64
+	Limit           int64
+}
+
+type ListFeedItemsRow struct {
+	ID             int64
+	Slug           string
+	Title          string
+	Description    string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
+	AuthorID       int64
+	AuthorUsername string
+	AuthorBio      sql.NullString
+	AuthorImage    sql.NullString
+}
+
+// Reads the home feed from feed_items, and pages like ListArticlesFeed, which computes the same feed from follows.
+func (q *Queries) ListFeedItems(ctx context.Context, arg ListFeedItemsParams) ([]ListFeedItemsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listFeedItems,
+		arg.UserID,
+		arg.BeforeCreatedAt,
+		arg.BeforeID,
+		arg.Offset,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListFeedItemsRow
+	for rows.Next() {
+		var i ListFeedItemsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Slug,
+			&i.Title,
+			&i.Description,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.FavoritesCount,
+			&i.CommentsCount,
 			&i.AuthorID,
 			&i.AuthorUsername,
 			&i.AuthorBio,
@@ -889,6 +1238,317 @@ func (q *Queries) ListArticlesFeed(ctx context.Context, arg ListArticlesFeedPara
 	return items, nil
 }
 
+const listLoginEvents = `-- name: ListLoginEvents :many
+SELECT id, user_id, email, ip, user_agent, success, created_at FROM login_events WHERE user_id = ? ORDER BY id DESC LIMIT ?
+`
+
+type ListLoginEventsParams struct {
+	UserID sql.NullInt64
+	Limit  int64
+}
+
+func (q *Queries) ListLoginEvents(ctx context.Context, arg ListLoginEventsParams) ([]LoginEvent, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginEvents, arg.UserID, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []LoginEvent
+	for rows.Next() {
+		var i LoginEvent
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Email,
+			&i.Ip,
+			&i.UserAgent,
+			&i.Success,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listLoginFailures = `-- name: ListLoginFailures :many
+SELECT created_at FROM login_events
+WHERE email = ?1 AND success = FALSE
+  AND created_at > DATETIME('now', '-' || CAST(?2 AS INTEGER) || ' seconds')
+  AND id > (SELECT COALESCE(MAX(id), 0) FROM login_events s WHERE s.email = ?1 AND s.success = TRUE)
+ORDER BY id DESC
+LIMIT ?3
+`
+
+type ListLoginFailuresParams struct {
+	Email         string
+	WindowSeconds int64
+	Limit         int64
+}
+
+// Lists the times of the latest failed logins to email since its last successful one, within window_seconds of now.
+func (q *Queries) ListLoginFailures(ctx context.Context, arg ListLoginFailuresParams) ([]time.Time, error) {
+	rows, err := q.db.QueryContext(ctx, listLoginFailures, arg.Email, arg.WindowSeconds, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []time.Time
+	for rows.Next() {
+		var created_at time.Time
+		if err := rows.Scan(&created_at); err != nil {
+			return nil, err
+		}
+		items = append(items, created_at)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const moveArticleTags = `-- name: MoveArticleTags :exec
+INSERT OR IGNORE INTO article_tags (article_id, tag_id)
+SELECT at.article_id, ?1 FROM article_tags at WHERE at.tag_id = ?2
+`
+
+type MoveArticleTagsParams struct {
+	ToTagID   int64
+	FromTagID int64
+}
+
+func (q *Queries) MoveArticleTags(ctx context.Context, arg MoveArticleTagsParams) error {
+	_, err := q.db.ExecContext(ctx, moveArticleTags, arg.ToTagID, arg.FromTagID)
+	return err
+}
+
+const nextJob = `-- name: NextJob :one
+SELECT id, kind, payload, attempts, last_error, run_at, created_at FROM jobs
+WHERE run_at <= CURRENT_TIMESTAMP
+ORDER BY run_at, id
+LIMIT 1
+`
+
+func (q *Queries) NextJob(ctx context.Context) (Job, error) {
+	row := q.db.QueryRowContext(ctx, nextJob)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Payload,
+		&i.Attempts,
+		&i.LastError,
+		&i.RunAt,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const pruneFeed = `-- name: PruneFeed :exec
+DELETE FROM feed_items
+WHERE user_id = ?1 AND author_id = ?2
+    AND NOT EXISTS (SELECT 1 FROM follows f WHERE f.follower_id = feed_items.user_id AND f.followed_id = feed_items.author_id)
+`
+
+type PruneFeedParams struct {
+	FollowerID int64
+	AuthorID   int64
+}
+
+func (q *Queries) PruneFeed(ctx context.Context, arg PruneFeedParams) error {
+	_, err := q.db.ExecContext(ctx, pruneFeed, arg.FollowerID, arg.AuthorID)
+	return err
+}
+
+const recountComments = `-- name: RecountComments :execrows
+UPDATE articles SET comments_count = (SELECT COUNT(*) FROM comments c WHERE c.article_id = articles.id)
+WHERE comments_count != (SELECT COUNT(*) FROM comments c WHERE c.article_id = articles.id)
+`
+
+func (q *Queries) RecountComments(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recountComments)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recountFavorites = `-- name: RecountFavorites :execrows
+UPDATE articles SET favorites_count = (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id)
+WHERE favorites_count != (SELECT COUNT(*) FROM favorites f WHERE f.article_id = articles.id)
+`
+
+// The recount queries repair the counter columns maintained by the triggers in schema.sql.
+// They only touch rows whose counter is off, so the number of rows is the number of repairs.
+func (q *Queries) RecountFavorites(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recountFavorites)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const recountFollowers = `-- name: RecountFollowers :execrows
+UPDATE users SET followers_count = (SELECT COUNT(*) FROM follows f WHERE f.followed_id = users.id)
+WHERE followers_count != (SELECT COUNT(*) FROM follows f WHERE f.followed_id = users.id)
+`
+
+func (q *Queries) RecountFollowers(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, recountFollowers)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reserveIdempotencyKey = `-- name: ReserveIdempotencyKey :execrows
+INSERT INTO idempotency_keys (user_id, idempotency_key, request_hash, expires_at)
+VALUES (?1, ?2, ?3,
+        DATETIME('now', '+' || CAST(?4 AS INTEGER) || ' seconds'))
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET request_hash = excluded.request_hash,
+    status = 0,
+    header = '',
+    body = '',
+    created_at = CURRENT_TIMESTAMP,
+    expires_at = excluded.expires_at
+WHERE idempotency_keys.expires_at <= CURRENT_TIMESTAMP
+   OR (idempotency_keys.status = 0
+       AND idempotency_keys.created_at <= DATETIME('now', '-1 minute'))
+`
+
+type ReserveIdempotencyKeyParams struct {
+	UserID         int64
+	IdempotencyKey string
+	RequestHash    string
+	TtlSeconds     int64
+}
+
+// Claims the key for a new request, or takes over a key that has expired
+// or whose request has run for over a minute, which it takes for a request that stopped without a response, such as in a restart.
+func (q *Queries) ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, reserveIdempotencyKey,
+		arg.UserID,
+		arg.IdempotencyKey,
+		arg.RequestHash,
+		arg.TtlSeconds,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const retryJob = `-- name: RetryJob :exec
+UPDATE jobs
+SET attempts = attempts + 1,
+    last_error = ?1,
+    run_at = DATETIME('now', '+' || CAST(?2 AS INTEGER) || ' seconds')
+WHERE id = ?3
+`
+
+type RetryJobParams struct {
+	LastError    sql.NullString
+	DelaySeconds int64
+	ID           int64
+}
+
+func (q *Queries) RetryJob(ctx context.Context, arg RetryJobParams) error {
+	_, err := q.db.ExecContext(ctx, retryJob, arg.LastError, arg.DelaySeconds, arg.ID)
+	return err
+}
+
+const saveIdempotencyResponse = `-- name: SaveIdempotencyResponse :exec
+UPDATE idempotency_keys
+SET status = ?1, header = ?2, body = ?3
+WHERE user_id = ?4 AND idempotency_key = ?5
+`
+
+type SaveIdempotencyResponseParams struct {
+	Status         int64
+	Header         string
+	Body           string
+	UserID         int64
+	IdempotencyKey string
+}
+
+func (q *Queries) SaveIdempotencyResponse(ctx context.Context, arg SaveIdempotencyResponseParams) error {
+	_, err := q.db.ExecContext(ctx, saveIdempotencyResponse,
+		arg.Status,
+		arg.Header,
+		arg.Body,
+		arg.UserID,
+		arg.IdempotencyKey,
+	)
+	return err
+}
+
+const takeRateLimit = `-- name: TakeRateLimit :one
+INSERT INTO rate_limits (key, tat, interval_ms, tolerance_ms, allowed)
+VALUES (?1, CAST(?2 AS INTEGER) + ?3, ?3, ?4, TRUE)
+ON CONFLICT (key) DO UPDATE
+SET tat = CASE
+        WHEN MAX(rate_limits.tat + excluded.interval_ms, excluded.tat) <= excluded.tat - excluded.interval_ms + excluded.tolerance_ms
+        THEN MAX(rate_limits.tat + excluded.interval_ms, excluded.tat)
+        ELSE rate_limits.tat
+    END,
+    allowed = MAX(rate_limits.tat + excluded.interval_ms, excluded.tat) <= excluded.tat - excluded.interval_ms + excluded.tolerance_ms,
+    interval_ms = excluded.interval_ms,
+    tolerance_ms = excluded.tolerance_ms
+RETURNING tat, allowed
+`
+
+type TakeRateLimitParams struct {
+	Key         string
+	NowMs       int64
+	IntervalMs  int64
+	ToleranceMs int64
+}
+
+type TakeRateLimitRow struct {
+	Tat     int64
+	Allowed bool
+}
+
+// Takes a request from the bucket of key, with the generic cell rate algorithm: each request moves tat
+// interval_ms later, from now at the earliest, unless that would take it beyond tolerance_ms from now.
+// A new bucket is full. The update reads now, the interval and the tolerance from the excluded row,
+// as excluded.tat is now plus the interval.
+func (q *Queries) TakeRateLimit(ctx context.Context, arg TakeRateLimitParams) (TakeRateLimitRow, error) {
+	row := q.db.QueryRowContext(ctx, takeRateLimit,
+		arg.Key,
+		arg.NowMs,
+		arg.IntervalMs,
+		arg.ToleranceMs,
+	)
+	var i TakeRateLimitRow
+	err := row.Scan(&i.Tat, &i.Allowed)
+	return i, err
+}
+
+const trimUnknownLoginEvents = `-- name: TrimUnknownLoginEvents :exec
+DELETE FROM login_events
+WHERE user_id IS NULL AND id <= (
+    SELECT id FROM login_events WHERE user_id IS NULL ORDER BY id DESC LIMIT 1 OFFSET ?1
+)
+`
+
+// Deletes the logins to emails of no user but the latest keep.
+func (q *Queries) TrimUnknownLoginEvents(ctx context.Context, keep int64) error {
+	_, err := q.db.ExecContext(ctx, trimUnknownLoginEvents, keep)
+	return err
+}
+
 const updateArticle = `-- name: UpdateArticle :one
 UPDATE articles
 SET
@@ -897,7 +1557,7 @@ SET
     description = COALESCE(?3, description),
     body = COALESCE(?4, body)
 WHERE id = ?5
-RETURNING id, slug, title, description, body, author_id, created_at, updated_at
+RETURNING id, slug, title, description, body, author_id, created_at, updated_at, favorites_count, comments_count, touched_at
 `
 
 type UpdateArticleParams struct {
@@ -926,6 +1586,9 @@ func (q *Queries) UpdateArticle(ctx context.Context, arg UpdateArticleParams) (A
 		&i.AuthorID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.FavoritesCount,
+		&i.CommentsCount,
+		&i.TouchedAt,
 	)
 	return i, err
 }
@@ -939,7 +1602,7 @@ SET
     bio = COALESCE(?4, bio),
     image = COALESCE(?5, image)
 WHERE id = ?6
-RETURNING id, username, email, password, bio, image, created_at, updated_at
+RETURNING id, username, email, password, bio, image, created_at, updated_at, disabled_at, followers_count, touched_at
 `
 
 type UpdateUserParams struct {
@@ -970,6 +1633,9 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 		&i.Image,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.DisabledAt,
+		&i.FollowersCount,
+		&i.TouchedAt,
 	)
 	return i, err
 }
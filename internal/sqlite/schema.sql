@@ -1,4 +1,4 @@
-CREATE TABLE users (
+CREATE TABLE IF NOT EXISTS users (
     id INTEGER PRIMARY KEY,
     username text NOT NULL,
     email text NOT NULL,
@@ -6,11 +6,14 @@ CREATE TABLE users (
     bio text,
     image text,
     created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
-    updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+    updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    disabled_at datetime,
+    followers_count INTEGER NOT NULL DEFAULT 0,
+    touched_at datetime
 );
 
 -- Trigger that avoids recursion by specifying which columns trigger the update
-CREATE TRIGGER update_users_updated_at
+CREATE TRIGGER IF NOT EXISTS update_users_updated_at
     AFTER UPDATE OF username, email, password, bio, image ON users
     FOR EACH ROW
 BEGIN
@@ -19,7 +22,7 @@ BEGIN
     WHERE rowid = NEW.rowid;
 END;
 
-CREATE TABLE follows (
+CREATE TABLE IF NOT EXISTS follows (
     follower_id INTEGER NOT NULL,
     followed_id INTEGER NOT NULL,
     created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -28,15 +31,31 @@ CREATE TABLE follows (
     FOREIGN KEY (followed_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
-CREATE INDEX idx_follows_followed_id ON follows(followed_id);
+CREATE INDEX IF NOT EXISTS idx_follows_followed_id ON follows(followed_id);
+
+-- Counter columns are kept in step by triggers, which also fire for rows removed by ON DELETE CASCADE.
+-- db recount repairs them should they ever drift.
+CREATE TRIGGER IF NOT EXISTS follows_count_insert
+    AFTER INSERT ON follows
+    FOR EACH ROW
+BEGIN
+    UPDATE users SET followers_count = followers_count + 1 WHERE id = NEW.followed_id;
+END;
 
-CREATE TABLE tags (
+CREATE TRIGGER IF NOT EXISTS follows_count_delete
+    AFTER DELETE ON follows
+    FOR EACH ROW
+BEGIN
+    UPDATE users SET followers_count = followers_count - 1 WHERE id = OLD.followed_id;
+END;
+
+CREATE TABLE IF NOT EXISTS tags (
     id INTEGER PRIMARY KEY,
     name text NOT NULL UNIQUE,
     created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
-CREATE TABLE articles (
+CREATE TABLE IF NOT EXISTS articles (
     id INTEGER PRIMARY KEY,
     slug text NOT NULL UNIQUE,
     title text NOT NULL,
@@ -45,10 +64,13 @@ CREATE TABLE articles (
     author_id INTEGER NOT NULL,
     created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    favorites_count INTEGER NOT NULL DEFAULT 0,
+    comments_count INTEGER NOT NULL DEFAULT 0,
+    touched_at datetime,
     FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
-CREATE TRIGGER update_articles_updated_at
+CREATE TRIGGER IF NOT EXISTS update_articles_updated_at
     AFTER UPDATE OF title, description, body ON articles
     FOR EACH ROW
 BEGIN
@@ -57,10 +79,12 @@ BEGIN
     WHERE rowid = NEW.rowid;
 END;
 
-CREATE INDEX idx_articles_author_id ON articles(author_id);
-CREATE INDEX idx_articles_created_at ON articles(created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_articles_author_id ON articles(author_id);
+-- Lists page by (created_at, id), replacing the created_at-only index of older databases
+DROP INDEX IF EXISTS idx_articles_created_at;
+CREATE INDEX IF NOT EXISTS idx_articles_created_at_id ON articles(created_at DESC, id DESC);
 
-CREATE TABLE article_tags (
+CREATE TABLE IF NOT EXISTS article_tags (
     article_id INTEGER NOT NULL,
     tag_id INTEGER NOT NULL,
     PRIMARY KEY (article_id, tag_id),
@@ -68,9 +92,9 @@ CREATE TABLE article_tags (
     FOREIGN KEY (tag_id) REFERENCES tags(id) ON DELETE CASCADE
 );
 
-CREATE INDEX idx_article_tags_tag_id ON article_tags(tag_id);
+CREATE INDEX IF NOT EXISTS idx_article_tags_tag_id ON article_tags(tag_id);
 
-CREATE TABLE favorites (
+CREATE TABLE IF NOT EXISTS favorites (
     user_id INTEGER NOT NULL,
     article_id INTEGER NOT NULL,
     created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
@@ -79,9 +103,23 @@ CREATE TABLE favorites (
     FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
 );
 
-CREATE INDEX idx_favorites_article_id ON favorites(article_id);
+CREATE INDEX IF NOT EXISTS idx_favorites_article_id ON favorites(article_id);
+
+CREATE TRIGGER IF NOT EXISTS favorites_count_insert
+    AFTER INSERT ON favorites
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET favorites_count = favorites_count + 1 WHERE id = NEW.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS favorites_count_delete
+    AFTER DELETE ON favorites
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET favorites_count = favorites_count - 1 WHERE id = OLD.article_id;
+END;
 
-CREATE TABLE comments (
+CREATE TABLE IF NOT EXISTS comments (
     id INTEGER PRIMARY KEY,
     body text NOT NULL,
     article_id INTEGER NOT NULL,
@@ -92,7 +130,7 @@ CREATE TABLE comments (
     FOREIGN KEY (author_id) REFERENCES users(id) ON DELETE CASCADE
 );
 
-CREATE TRIGGER update_comments_updated_at
+CREATE TRIGGER IF NOT EXISTS update_comments_updated_at
     AFTER UPDATE OF body ON comments
     FOR EACH ROW
 BEGIN
@@ -101,5 +139,160 @@ BEGIN
     WHERE rowid = NEW.rowid;
 END;
 
-CREATE INDEX idx_comments_article_id ON comments(article_id);
-CREATE INDEX idx_comments_author_id ON comments(author_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_comments_article_id ON comments(article_id);
+CREATE INDEX IF NOT EXISTS idx_comments_author_id ON comments(author_id);
+
+CREATE TRIGGER IF NOT EXISTS comments_count_insert
+    AFTER INSERT ON comments
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET comments_count = comments_count + 1 WHERE id = NEW.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_count_delete
+    AFTER DELETE ON comments
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET comments_count = comments_count - 1 WHERE id = OLD.article_id;
+END;
+
+-- touched_at is the last time a response about the row changed without updated_at: the favorites, comments and tags
+-- of an article, and the followers of a user. Together with updated_at it gives the Last-Modified of those responses.
+CREATE TRIGGER IF NOT EXISTS favorites_touch_insert
+    AFTER INSERT ON favorites
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET touched_at = CURRENT_TIMESTAMP WHERE id = NEW.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS favorites_touch_delete
+    AFTER DELETE ON favorites
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET touched_at = CURRENT_TIMESTAMP WHERE id = OLD.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_touch_insert
+    AFTER INSERT ON comments
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET touched_at = CURRENT_TIMESTAMP WHERE id = NEW.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS comments_touch_delete
+    AFTER DELETE ON comments
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET touched_at = CURRENT_TIMESTAMP WHERE id = OLD.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS article_tags_touch_insert
+    AFTER INSERT ON article_tags
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET touched_at = CURRENT_TIMESTAMP WHERE id = NEW.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS article_tags_touch_delete
+    AFTER DELETE ON article_tags
+    FOR EACH ROW
+BEGIN
+    UPDATE articles SET touched_at = CURRENT_TIMESTAMP WHERE id = OLD.article_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS follows_touch_insert
+    AFTER INSERT ON follows
+    FOR EACH ROW
+BEGIN
+    UPDATE users SET touched_at = CURRENT_TIMESTAMP WHERE id = NEW.followed_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS follows_touch_delete
+    AFTER DELETE ON follows
+    FOR EACH ROW
+BEGIN
+    UPDATE users SET touched_at = CURRENT_TIMESTAMP WHERE id = OLD.followed_id;
+END;
+
+-- feed_items is the home feed of each user, written ahead of reads: an article is fanned out to the followers
+-- of its author when it is published, and a follow or unfollow backfills or prunes the articles of that author.
+-- The jobs table queues these writes for the job runner.
+CREATE TABLE IF NOT EXISTS feed_items (
+    user_id INTEGER NOT NULL,
+    article_id INTEGER NOT NULL,
+    author_id INTEGER NOT NULL,
+    created_at datetime NOT NULL,
+    PRIMARY KEY (user_id, article_id),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (article_id) REFERENCES articles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_feed_items_user_id_created_at ON feed_items(user_id, created_at DESC, article_id DESC);
+
+-- Databases created before feed_items start with an empty table, filled here once from the current follows.
+-- An empty table with follows of authors with articles means exactly that, so this is a no-op on later starts.
+INSERT INTO feed_items (user_id, article_id, author_id, created_at)
+SELECT f.follower_id, a.id, a.author_id, a.created_at
+FROM follows f
+JOIN articles a ON a.author_id = f.followed_id
+WHERE NOT EXISTS (SELECT 1 FROM feed_items)
+ON CONFLICT (user_id, article_id) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS jobs (
+    id INTEGER PRIMARY KEY,
+    kind text NOT NULL,
+    payload text NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error text,
+    run_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_jobs_run_at ON jobs(run_at, id);
+
+-- idempotency_keys holds the first response to each Idempotency-Key of a user, which retries of the same request replay
+-- until expires_at. status is 0 while the first request is still running.
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    user_id INTEGER NOT NULL,
+    idempotency_key text NOT NULL,
+    request_hash text NOT NULL,
+    status INTEGER NOT NULL DEFAULT 0,
+    header text NOT NULL DEFAULT '',
+    body text NOT NULL DEFAULT '',
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at datetime NOT NULL,
+    PRIMARY KEY (user_id, idempotency_key),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- rate_limits holds a token bucket for each key of a rate limit policy, such as the client IP of login requests,
+-- as its theoretical arrival time (tat) in unix milliseconds: the bucket is full from then on, and requests are
+-- allowed while tat stays within tolerance_ms of now. Rows whose tat is past are full buckets and can be deleted.
+CREATE TABLE IF NOT EXISTS rate_limits (
+    key text PRIMARY KEY,
+    tat INTEGER NOT NULL,
+    interval_ms INTEGER NOT NULL,
+    tolerance_ms INTEGER NOT NULL,
+    allowed BOOLEAN NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rate_limits_tat ON rate_limits(tat);
+
+-- login_events records each attempt to log in to an account by email, including emails of no user, for users to review
+-- and to throttle guessing: the failed attempts since the last successful one delay the next attempts, see ListLoginFailures.
+CREATE TABLE IF NOT EXISTS login_events (
+    id INTEGER PRIMARY KEY,
+    user_id INTEGER,
+    email text NOT NULL,
+    ip text NOT NULL,
+    user_agent text NOT NULL,
+    success BOOLEAN NOT NULL,
+    created_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_events_email ON login_events(email, id);
+CREATE INDEX IF NOT EXISTS idx_login_events_user_id ON login_events(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_login_events_created_at ON login_events(created_at);
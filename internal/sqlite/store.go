@@ -0,0 +1,354 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+// Store implements [store.Store] on a SQLite database with the queries generated from query.sql.
+type Store struct {
+	querier
+	db    *sql.DB
+	hooks []store.QueryHook
+}
+
+var _ store.Store = (*Store)(nil)
+
+// NewStore returns a [Store] that runs its queries on db, calling hooks after each of them.
+func NewStore(db *sql.DB, hooks ...store.QueryHook) *Store {
+	return &Store{querier: querier{New(store.Hook(db, hooks...))}, db: db, hooks: hooks}
+}
+
+// BeginTx implements [store.Store].
+func (s *Store) BeginTx(ctx context.Context) (store.Tx, error) {
+	sqlTx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	return tx{querier: querier{New(store.Hook(sqlTx, s.hooks...))}, Tx: sqlTx}, nil
+}
+
+// Ping implements [store.Store].
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
+// Columns implements [store.Store].
+func (s *Store) Columns(ctx context.Context) (map[string][]string, error) {
+	rows, err := s.db.QueryContext(ctx, `SELECT m.name, c.name FROM sqlite_master m, pragma_table_info(m.name) c
+		WHERE m.type = 'table' ORDER BY m.name, c.cid`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+
+	columns := make(map[string][]string)
+	for rows.Next() {
+		var table, column string
+		if err := rows.Scan(&table, &column); err != nil {
+			return nil, err
+		}
+		columns[table] = append(columns[table], column)
+	}
+	return columns, rows.Err()
+}
+
+// Close implements [store.Store].
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Stats returns the statistics of the connection pool.
+func (s *Store) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// tx implements [store.Tx], taking Commit and Rollback from the embedded [sql.Tx].
+type tx struct {
+	querier
+	*sql.Tx
+}
+
+// querier implements [store.Querier] by converting between the generated types and those of the store package.
+type querier struct {
+	q *Queries
+}
+
+// convert converts each element of s with f.
+func convert[T, U any](s []T, f func(T) U) []U {
+	result := make([]U, len(s))
+	for i, v := range s {
+		result[i] = f(v)
+	}
+	return result
+}
+
+// timestamp formats t the way SQLite's CURRENT_TIMESTAMP stores times, so it compares as text against the stored columns.
+// The driver would bind a [time.Time] in [time.Time.String] format, which SQLite cannot compare.
+func timestamp(t sql.NullTime) sql.NullString {
+	return sql.NullString{String: t.Time.UTC().Format("2006-01-02 15:04:05.999999999"), Valid: t.Valid}
+}
+
+func (q querier) AssociateArticleTag(ctx context.Context, arg store.AssociateArticleTagParams) error {
+	return q.q.AssociateArticleTag(ctx, AssociateArticleTagParams(arg))
+}
+
+func (q querier) BackfillFeed(ctx context.Context, arg store.BackfillFeedParams) error {
+	return q.q.BackfillFeed(ctx, BackfillFeedParams(arg))
+}
+
+func (q querier) CheckFavoritedByUser(ctx context.Context, arg store.CheckFavoritedByUserParams) ([]int64, error) {
+	return q.q.CheckFavoritedByUser(ctx, CheckFavoritedByUserParams(arg))
+}
+
+func (q querier) CountArticles(ctx context.Context, arg store.CountArticlesParams) (int64, error) {
+	return q.q.CountArticles(ctx, CountArticlesParams(arg))
+}
+
+func (q querier) CountArticlesFeed(ctx context.Context, followerID int64) (int64, error) {
+	return q.q.CountArticlesFeed(ctx, followerID)
+}
+
+func (q querier) CountFeedItems(ctx context.Context, userID int64) (int64, error) {
+	return q.q.CountFeedItems(ctx, userID)
+}
+
+func (q querier) CountUnknownLoginEvents(ctx context.Context) (int64, error) {
+	return q.q.CountUnknownLoginEvents(ctx)
+}
+
+func (q querier) CreateArticle(ctx context.Context, arg store.CreateArticleParams) (store.Article, error) {
+	article, err := q.q.CreateArticle(ctx, CreateArticleParams(arg))
+	return store.Article(article), err
+}
+
+func (q querier) CreateComment(ctx context.Context, arg store.CreateCommentParams) (store.Comment, error) {
+	comment, err := q.q.CreateComment(ctx, CreateCommentParams(arg))
+	return store.Comment(comment), err
+}
+
+func (q querier) CreateFavorite(ctx context.Context, arg store.CreateFavoriteParams) error {
+	return q.q.CreateFavorite(ctx, CreateFavoriteParams(arg))
+}
+
+func (q querier) CreateFollow(ctx context.Context, arg store.CreateFollowParams) error {
+	return q.q.CreateFollow(ctx, CreateFollowParams(arg))
+}
+
+func (q querier) CreateJob(ctx context.Context, arg store.CreateJobParams) error {
+	return q.q.CreateJob(ctx, CreateJobParams(arg))
+}
+
+func (q querier) CreateLoginEvent(ctx context.Context, arg store.CreateLoginEventParams) error {
+	return q.q.CreateLoginEvent(ctx, CreateLoginEventParams(arg))
+}
+
+func (q querier) CreateUser(ctx context.Context, arg store.CreateUserParams) (store.User, error) {
+	user, err := q.q.CreateUser(ctx, CreateUserParams(arg))
+	return store.User(user), err
+}
+
+func (q querier) DeleteArticle(ctx context.Context, id int64) error {
+	return q.q.DeleteArticle(ctx, id)
+}
+
+func (q querier) DeleteComment(ctx context.Context, id int64) error {
+	return q.q.DeleteComment(ctx, id)
+}
+
+func (q querier) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return q.q.DeleteExpiredIdempotencyKeys(ctx)
+}
+
+func (q querier) DeleteExpiredRateLimits(ctx context.Context, nowMs int64) (int64, error) {
+	return q.q.DeleteExpiredRateLimits(ctx, nowMs)
+}
+
+func (q querier) DeleteFavorite(ctx context.Context, arg store.DeleteFavoriteParams) error {
+	return q.q.DeleteFavorite(ctx, DeleteFavoriteParams(arg))
+}
+
+func (q querier) DeleteFollow(ctx context.Context, arg store.DeleteFollowParams) error {
+	return q.q.DeleteFollow(ctx, DeleteFollowParams(arg))
+}
+
+func (q querier) DeleteIdempotencyKey(ctx context.Context, arg store.DeleteIdempotencyKeyParams) error {
+	return q.q.DeleteIdempotencyKey(ctx, DeleteIdempotencyKeyParams(arg))
+}
+
+func (q querier) DeleteJob(ctx context.Context, id int64) error {
+	return q.q.DeleteJob(ctx, id)
+}
+
+func (q querier) DeleteOldLoginEvents(ctx context.Context, arg store.DeleteOldLoginEventsParams) (int64, error) {
+	return q.q.DeleteOldLoginEvents(ctx, DeleteOldLoginEventsParams(arg))
+}
+
+func (q querier) FanOutArticle(ctx context.Context, articleID int64) error {
+	return q.q.FanOutArticle(ctx, articleID)
+}
+
+func (q querier) GetAllTags(ctx context.Context) ([]string, error) {
+	return q.q.GetAllTags(ctx)
+}
+
+func (q querier) GetArticleBySlug(ctx context.Context, slug string) (store.GetArticleBySlugRow, error) {
+	row, err := q.q.GetArticleBySlug(ctx, slug)
+	return store.GetArticleBySlugRow(row), err
+}
+
+func (q querier) GetArticleTagsByArticleID(ctx context.Context, articleID int64) ([]string, error) {
+	return q.q.GetArticleTagsByArticleID(ctx, articleID)
+}
+
+func (q querier) GetArticleTagsByArticleIDs(ctx context.Context, articleIds []int64) ([]store.GetArticleTagsByArticleIDsRow, error) {
+	rows, err := q.q.GetArticleTagsByArticleIDs(ctx, articleIds)
+	return convert(rows, func(r GetArticleTagsByArticleIDsRow) store.GetArticleTagsByArticleIDsRow {
+		return store.GetArticleTagsByArticleIDsRow(r)
+	}), err
+}
+
+func (q querier) GetCommentByID(ctx context.Context, id int64) (store.Comment, error) {
+	comment, err := q.q.GetCommentByID(ctx, id)
+	return store.Comment(comment), err
+}
+
+func (q querier) GetCommentWithAuthor(ctx context.Context, id int64) (store.GetCommentWithAuthorRow, error) {
+	row, err := q.q.GetCommentWithAuthor(ctx, id)
+	return store.GetCommentWithAuthorRow(row), err
+}
+
+func (q querier) GetCommentsByArticleSlug(ctx context.Context, slug string) ([]store.GetCommentsByArticleSlugRow, error) {
+	rows, err := q.q.GetCommentsByArticleSlug(ctx, slug)
+	return convert(rows, func(r GetCommentsByArticleSlugRow) store.GetCommentsByArticleSlugRow {
+		return store.GetCommentsByArticleSlugRow(r)
+	}), err
+}
+
+func (q querier) GetFavoritesCount(ctx context.Context, articleID int64) (int64, error) {
+	return q.q.GetFavoritesCount(ctx, articleID)
+}
+
+func (q querier) GetFollowingByIDs(ctx context.Context, arg store.GetFollowingByIDsParams) ([]int64, error) {
+	return q.q.GetFollowingByIDs(ctx, GetFollowingByIDsParams(arg))
+}
+
+func (q querier) GetIdempotencyKey(ctx context.Context, arg store.GetIdempotencyKeyParams) (store.IdempotencyKey, error) {
+	key, err := q.q.GetIdempotencyKey(ctx, GetIdempotencyKeyParams(arg))
+	return store.IdempotencyKey(key), err
+}
+
+func (q querier) GetOrCreateTag(ctx context.Context, name string) (store.Tag, error) {
+	tag, err := q.q.GetOrCreateTag(ctx, name)
+	return store.Tag(tag), err
+}
+
+func (q querier) GetUserByEmail(ctx context.Context, email string) (store.User, error) {
+	user, err := q.q.GetUserByEmail(ctx, email)
+	return store.User(user), err
+}
+
+func (q querier) GetUserByID(ctx context.Context, id int64) (store.User, error) {
+	user, err := q.q.GetUserByID(ctx, id)
+	return store.User(user), err
+}
+
+func (q querier) GetUserByUsername(ctx context.Context, username string) (store.User, error) {
+	user, err := q.q.GetUserByUsername(ctx, username)
+	return store.User(user), err
+}
+
+func (q querier) IsFavorited(ctx context.Context, arg store.IsFavoritedParams) (bool, error) {
+	return q.q.IsFavorited(ctx, IsFavoritedParams(arg))
+}
+
+func (q querier) IsFollowing(ctx context.Context, arg store.IsFollowingParams) (bool, error) {
+	return q.q.IsFollowing(ctx, IsFollowingParams(arg))
+}
+
+func (q querier) ListArticles(ctx context.Context, arg store.ListArticlesParams) ([]store.ListArticlesRow, error) {
+	rows, err := q.q.ListArticles(ctx, ListArticlesParams{
+		Tag:             arg.Tag,
+		Author:          arg.Author,
+		Favorited:       arg.Favorited,
+		BeforeCreatedAt: timestamp(arg.BeforeCreatedAt),
+		BeforeID:        arg.BeforeID,
+		Offset:          arg.Offset,
+		Limit:           arg.Limit,
+	})
+	return convert(rows, func(r ListArticlesRow) store.ListArticlesRow { return store.ListArticlesRow(r) }), err
+}
+
+func (q querier) ListArticlesFeed(ctx context.Context, arg store.ListArticlesFeedParams) ([]store.ListArticlesFeedRow, error) {
+	rows, err := q.q.ListArticlesFeed(ctx, ListArticlesFeedParams{
+		FollowerID:      arg.FollowerID,
+		BeforeCreatedAt: timestamp(arg.BeforeCreatedAt),
+		BeforeID:        arg.BeforeID,
+		Offset:          arg.Offset,
+		Limit:           arg.Limit,
+	})
+	return convert(rows, func(r ListArticlesFeedRow) store.ListArticlesFeedRow { return store.ListArticlesFeedRow(r) }), err
+}
+
+func (q querier) ListFeedItems(ctx context.Context, arg store.ListFeedItemsParams) ([]store.ListFeedItemsRow, error) {
+	rows, err := q.q.ListFeedItems(ctx, ListFeedItemsParams{
+		UserID:          arg.UserID,
+		BeforeCreatedAt: timestamp(arg.BeforeCreatedAt),
+		BeforeID:        arg.BeforeID,
+		Offset:          arg.Offset,
+		Limit:           arg.Limit,
+	})
+	return convert(rows, func(r ListFeedItemsRow) store.ListFeedItemsRow { return store.ListFeedItemsRow(r) }), err
+}
+
+func (q querier) ListLoginEvents(ctx context.Context, arg store.ListLoginEventsParams) ([]store.LoginEvent, error) {
+	events, err := q.q.ListLoginEvents(ctx, ListLoginEventsParams(arg))
+	return convert(events, func(e LoginEvent) store.LoginEvent { return store.LoginEvent(e) }), err
+}
+
+func (q querier) ListLoginFailures(ctx context.Context, arg store.ListLoginFailuresParams) ([]time.Time, error) {
+	return q.q.ListLoginFailures(ctx, ListLoginFailuresParams(arg))
+}
+
+func (q querier) NextJob(ctx context.Context) (store.Job, error) {
+	job, err := q.q.NextJob(ctx)
+	return store.Job(job), err
+}
+
+func (q querier) PruneFeed(ctx context.Context, arg store.PruneFeedParams) error {
+	return q.q.PruneFeed(ctx, PruneFeedParams(arg))
+}
+
+func (q querier) ReserveIdempotencyKey(ctx context.Context, arg store.ReserveIdempotencyKeyParams) (int64, error) {
+	return q.q.ReserveIdempotencyKey(ctx, ReserveIdempotencyKeyParams(arg))
+}
+
+func (q querier) RetryJob(ctx context.Context, arg store.RetryJobParams) error {
+	return q.q.RetryJob(ctx, RetryJobParams(arg))
+}
+
+func (q querier) SaveIdempotencyResponse(ctx context.Context, arg store.SaveIdempotencyResponseParams) error {
+	return q.q.SaveIdempotencyResponse(ctx, SaveIdempotencyResponseParams(arg))
+}
+
+func (q querier) TakeRateLimit(ctx context.Context, arg store.TakeRateLimitParams) (store.TakeRateLimitRow, error) {
+	row, err := q.q.TakeRateLimit(ctx, TakeRateLimitParams(arg))
+	return store.TakeRateLimitRow(row), err
+}
+
+func (q querier) TrimUnknownLoginEvents(ctx context.Context, keep int64) error {
+	return q.q.TrimUnknownLoginEvents(ctx, keep)
+}
+
+func (q querier) UpdateArticle(ctx context.Context, arg store.UpdateArticleParams) (store.Article, error) {
+	article, err := q.q.UpdateArticle(ctx, UpdateArticleParams(arg))
+	return store.Article(article), err
+}
+
+func (q querier) UpdateUser(ctx context.Context, arg store.UpdateUserParams) (store.User, error) {
+	user, err := q.q.UpdateUser(ctx, UpdateUserParams(arg))
+	return store.User(user), err
+}
@@ -0,0 +1,67 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
+	"github.com/raeperd/realworld.go/internal/store/storetest"
+)
+
+//go:embed schema.sql
+var schema string
+
+func TestStore(t *testing.T) {
+	t.Parallel()
+
+	storetest.Run(t, func(t *testing.T) store.Store {
+		db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "store.db")+"?_pragma=foreign_keys(1)")
+		test.Nil(t, err)
+		db.SetMaxOpenConns(1)
+		_, err = db.ExecContext(t.Context(), schema)
+		test.Nil(t, err)
+
+		s := sqlite.NewStore(db)
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	})
+}
+
+func TestStore_Hook(t *testing.T) {
+	t.Parallel()
+
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "hook.db"))
+	test.Nil(t, err)
+	_, err = db.ExecContext(t.Context(), schema)
+	test.Nil(t, err)
+
+	var names []string
+	var errs []error
+	s := sqlite.NewStore(db, func(_ context.Context, name string, start time.Time, err error) {
+		test.True(t, !start.IsZero())
+		names = append(names, name)
+		errs = append(errs, err)
+	})
+	t.Cleanup(func() { _ = s.Close() })
+
+	_, err = s.GetUserByID(t.Context(), 1)
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+	tx, err := s.BeginTx(t.Context())
+	test.Nil(t, err)
+	test.Nil(t, tx.CreateJob(t.Context(), store.CreateJobParams{Kind: "hook", Payload: "{}"}))
+	test.Nil(t, tx.Commit())
+
+	// No rows is the result of a query that ran fine
+	test.DeepEqual(t, []string{"GetUserByID", "CreateJob"}, names)
+	test.DeepEqual(t, []error{nil, nil}, errs)
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+// DBTX is the database or transaction that the queries generated by sqlc run on, the same in every backend.
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// QueryHook observes each query of a store after it has run: its name in query.sql, when it started and its error.
+// For queries that return rows, the time is until the first row and the error is that of running the query,
+// so it does not include reading the rows, or [sql.ErrNoRows].
+type QueryHook func(ctx context.Context, name string, start time.Time, err error)
+
+// Hook returns db with hooks called after each of its queries, or db itself without hooks.
+func Hook(db DBTX, hooks ...QueryHook) DBTX {
+	if len(hooks) == 0 {
+		return db
+	}
+	return hookedDB{db: db, hooks: hooks}
+}
+
+type hookedDB struct {
+	db    DBTX
+	hooks []QueryHook
+}
+
+func (h hookedDB) done(ctx context.Context, query string, start time.Time, err error) {
+	name := QueryName(query)
+	for _, hook := range h.hooks {
+		hook(ctx, name, start, err)
+	}
+}
+
+func (h hookedDB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	start := time.Now()
+	result, err := h.db.ExecContext(ctx, query, args...)
+	h.done(ctx, query, start, err)
+	return result, err
+}
+
+func (h hookedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return h.db.PrepareContext(ctx, query)
+}
+
+func (h hookedDB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	start := time.Now()
+	rows, err := h.db.QueryContext(ctx, query, args...)
+	h.done(ctx, query, start, err)
+	return rows, err
+}
+
+func (h hookedDB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	start := time.Now()
+	row := h.db.QueryRowContext(ctx, query, args...)
+	h.done(ctx, query, start, row.Err())
+	return row
+}
+
+// QueryName returns the name of a query generated by sqlc, from its "-- name: GetUserByID :one" comment,
+// or "unknown" for other queries.
+func QueryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "unknown"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
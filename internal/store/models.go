@@ -0,0 +1,373 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+)
+
+// The types below mirror the models, parameters and rows sqlc generates in the sqlite and postgres packages.
+// Both generate identical fields, so a backend converts between its types and these with a type conversion.
+
+type Article struct {
+	ID             int64
+	Slug           string
+	Title          string
+	Description    string
+	Body           string
+	AuthorID       int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
+	TouchedAt      sql.NullTime
+}
+
+type Comment struct {
+	ID        int64
+	Body      string
+	ArticleID int64
+	AuthorID  int64
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type IdempotencyKey struct {
+	UserID         int64
+	IdempotencyKey string
+	RequestHash    string
+	Status         int64
+	Header         string
+	Body           string
+	CreatedAt      time.Time
+	ExpiresAt      time.Time
+}
+
+type Job struct {
+	ID        int64
+	Kind      string
+	Payload   string
+	Attempts  int64
+	LastError sql.NullString
+	RunAt     time.Time
+	CreatedAt time.Time
+}
+
+type LoginEvent struct {
+	ID        int64
+	UserID    sql.NullInt64
+	Email     string
+	Ip        string
+	UserAgent string
+	Success   bool
+	CreatedAt time.Time
+}
+
+type Tag struct {
+	ID        int64
+	Name      string
+	CreatedAt time.Time
+}
+
+type User struct {
+	ID             int64
+	Username       string
+	Email          string
+	Password       string
+	Bio            sql.NullString
+	Image          sql.NullString
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	DisabledAt     sql.NullTime
+	FollowersCount int64
+	TouchedAt      sql.NullTime
+}
+
+type AssociateArticleTagParams struct {
+	ArticleID int64
+	TagID     int64
+}
+
+type BackfillFeedParams struct {
+	FollowerID int64
+	AuthorID   int64
+}
+
+type CheckFavoritedByUserParams struct {
+	UserID     int64
+	ArticleIds []int64
+}
+
+type CountArticlesParams struct {
+	Tag       sql.NullString
+	Author    sql.NullString
+	Favorited sql.NullString
+}
+
+type CreateArticleParams struct {
+	Slug        string
+	Title       string
+	Description string
+	Body        string
+	AuthorID    int64
+}
+
+type CreateCommentParams struct {
+	Body      string
+	ArticleID int64
+	AuthorID  int64
+}
+
+type CreateFavoriteParams struct {
+	UserID    int64
+	ArticleID int64
+}
+
+type CreateFollowParams struct {
+	FollowerID int64
+	FollowedID int64
+}
+
+type CreateJobParams struct {
+	Kind    string
+	Payload string
+}
+
+type CreateLoginEventParams struct {
+	UserID    sql.NullInt64
+	Email     string
+	Ip        string
+	UserAgent string
+	Success   bool
+}
+
+type CreateUserParams struct {
+	Username string
+	Email    string
+	Password string
+	Bio      sql.NullString
+	Image    sql.NullString
+}
+
+type DeleteFavoriteParams struct {
+	UserID    int64
+	ArticleID int64
+}
+
+type DeleteFollowParams struct {
+	FollowerID int64
+	FollowedID int64
+}
+
+type DeleteIdempotencyKeyParams struct {
+	UserID         int64
+	IdempotencyKey string
+}
+
+type DeleteOldLoginEventsParams struct {
+	RetentionSeconds        int64
+	UnknownRetentionSeconds int64
+}
+
+type GetArticleBySlugRow struct {
+	ID              int64
+	Slug            string
+	Title           string
+	Description     string
+	Body            string
+	AuthorID        int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	FavoritesCount  int64
+	CommentsCount   int64
+	TouchedAt       sql.NullTime
+	AuthorUsername  string
+	AuthorBio       sql.NullString
+	AuthorImage     sql.NullString
+	AuthorUpdatedAt time.Time
+	AuthorTouchedAt sql.NullTime
+}
+
+type GetArticleTagsByArticleIDsRow struct {
+	ArticleID int64
+	Name      string
+}
+
+type GetCommentWithAuthorRow struct {
+	ID             int64
+	Body           string
+	ArticleID      int64
+	AuthorID       int64
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	AuthorUsername string
+	AuthorBio      sql.NullString
+	AuthorImage    sql.NullString
+}
+
+type GetCommentsByArticleSlugRow struct {
+	ID             int64
+	Body           string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	AuthorID       int64
+	AuthorUsername string
+	AuthorBio      sql.NullString
+	AuthorImage    sql.NullString
+}
+
+type GetFollowingByIDsParams struct {
+	FollowerID  int64
+	FollowedIds []int64
+}
+
+type GetIdempotencyKeyParams struct {
+	UserID         int64
+	IdempotencyKey string
+}
+
+type IsFavoritedParams struct {
+	UserID    int64
+	ArticleID int64
+}
+
+type IsFollowingParams struct {
+	FollowerID int64
+	FollowedID int64
+}
+
+type ListArticlesFeedParams struct {
+	FollowerID      int64
+	BeforeCreatedAt sql.NullTime
+	BeforeID        sql.NullInt64
+	Offset          int64
+	Limit           int64
+}
+
+type ListArticlesFeedRow struct {
+	ID             int64
+	Slug           string
+	Title          string
+	Description    string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
+	AuthorID       int64
+	AuthorUsername string
+	AuthorBio      sql.NullString
+	AuthorImage    sql.NullString
+}
+
+type ListArticlesParams struct {
+	Tag             sql.NullString
+	Author          sql.NullString
+	Favorited       sql.NullString
+	BeforeCreatedAt sql.NullTime
+	BeforeID        sql.NullInt64
+	Offset          int64
+	Limit           int64
+}
+
+type ListArticlesRow struct {
+	ID             int64
+	Slug           string
+	Title          string
+	Description    string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
+	AuthorID       int64
+	AuthorUsername string
+	AuthorBio      sql.NullString
+	AuthorImage    sql.NullString
+}
+
+type ListFeedItemsParams struct {
+	UserID          int64
+	BeforeCreatedAt sql.NullTime
+	BeforeID        sql.NullInt64
+	Offset          int64
+	Limit           int64
+}
+
+type ListFeedItemsRow struct {
+	ID             int64
+	Slug           string
+	Title          string
+	Description    string
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+	FavoritesCount int64
+	CommentsCount  int64
+	AuthorID       int64
+	AuthorUsername string
+	AuthorBio      sql.NullString
+	AuthorImage    sql.NullString
+}
+
+type ListLoginEventsParams struct {
+	UserID sql.NullInt64
+	Limit  int64
+}
+
+type ListLoginFailuresParams struct {
+	Email         string
+	WindowSeconds int64
+	Limit         int64
+}
+
+type PruneFeedParams struct {
+	FollowerID int64
+	AuthorID   int64
+}
+
+type ReserveIdempotencyKeyParams struct {
+	UserID         int64
+	IdempotencyKey string
+	RequestHash    string
+	TtlSeconds     int64
+}
+
+type RetryJobParams struct {
+	LastError    sql.NullString
+	DelaySeconds int64
+	ID           int64
+}
+
+type SaveIdempotencyResponseParams struct {
+	Status         int64
+	Header         string
+	Body           string
+	UserID         int64
+	IdempotencyKey string
+}
+
+type TakeRateLimitParams struct {
+	Key         string
+	NowMs       int64
+	IntervalMs  int64
+	ToleranceMs int64
+}
+
+type TakeRateLimitRow struct {
+	Tat     int64
+	Allowed bool
+}
+
+type UpdateArticleParams struct {
+	Slug        sql.NullString
+	Title       sql.NullString
+	Description sql.NullString
+	Body        sql.NullString
+	ID          int64
+}
+
+type UpdateUserParams struct {
+	Email    sql.NullString
+	Username sql.NullString
+	Password sql.NullString
+	Bio      sql.NullString
+	Image    sql.NullString
+	ID       int64
+}
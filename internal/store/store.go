@@ -0,0 +1,89 @@
+// Package store defines the storage the HTTP handlers depend on, so the server runs on SQLite or PostgreSQL.
+// The sqlite and postgres packages implement [Store] with queries generated by sqlc from their own dialect,
+// and the conformance suite in storetest checks that both behave the same.
+package store
+
+import (
+	"context"
+	"time"
+)
+
+// Store is a database the handlers read from and write to.
+// Queries called on the Store itself run outside of any transaction.
+type Store interface {
+	Querier
+	// BeginTx starts a transaction. Its queries are applied together on Commit, or discarded on Rollback.
+	BeginTx(ctx context.Context) (Tx, error)
+	// Ping checks that the database can be reached.
+	Ping(ctx context.Context) error
+	// Columns lists the columns of each table of the database, so callers can check that the schema is applied.
+	Columns(ctx context.Context) (map[string][]string, error)
+	// Close closes the underlying database.
+	Close() error
+}
+
+// Tx is a transaction started by [Store.BeginTx].
+type Tx interface {
+	Querier
+	Commit() error
+	Rollback() error
+}
+
+// Querier lists the queries of query.sql shared by every backend.
+// Method names, parameters and results are those generated by sqlc, see models.go.
+type Querier interface {
+	AssociateArticleTag(ctx context.Context, arg AssociateArticleTagParams) error
+	BackfillFeed(ctx context.Context, arg BackfillFeedParams) error
+	CheckFavoritedByUser(ctx context.Context, arg CheckFavoritedByUserParams) ([]int64, error)
+	CountArticles(ctx context.Context, arg CountArticlesParams) (int64, error)
+	CountArticlesFeed(ctx context.Context, followerID int64) (int64, error)
+	CountFeedItems(ctx context.Context, userID int64) (int64, error)
+	CountUnknownLoginEvents(ctx context.Context) (int64, error)
+	CreateArticle(ctx context.Context, arg CreateArticleParams) (Article, error)
+	CreateComment(ctx context.Context, arg CreateCommentParams) (Comment, error)
+	CreateFavorite(ctx context.Context, arg CreateFavoriteParams) error
+	CreateFollow(ctx context.Context, arg CreateFollowParams) error
+	CreateJob(ctx context.Context, arg CreateJobParams) error
+	CreateLoginEvent(ctx context.Context, arg CreateLoginEventParams) error
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	DeleteArticle(ctx context.Context, id int64) error
+	DeleteComment(ctx context.Context, id int64) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+	DeleteExpiredRateLimits(ctx context.Context, nowMs int64) (int64, error)
+	DeleteFavorite(ctx context.Context, arg DeleteFavoriteParams) error
+	DeleteFollow(ctx context.Context, arg DeleteFollowParams) error
+	DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error
+	DeleteJob(ctx context.Context, id int64) error
+	DeleteOldLoginEvents(ctx context.Context, arg DeleteOldLoginEventsParams) (int64, error)
+	FanOutArticle(ctx context.Context, articleID int64) error
+	GetAllTags(ctx context.Context) ([]string, error)
+	GetArticleBySlug(ctx context.Context, slug string) (GetArticleBySlugRow, error)
+	GetArticleTagsByArticleID(ctx context.Context, articleID int64) ([]string, error)
+	GetArticleTagsByArticleIDs(ctx context.Context, articleIds []int64) ([]GetArticleTagsByArticleIDsRow, error)
+	GetCommentByID(ctx context.Context, id int64) (Comment, error)
+	GetCommentWithAuthor(ctx context.Context, id int64) (GetCommentWithAuthorRow, error)
+	GetCommentsByArticleSlug(ctx context.Context, slug string) ([]GetCommentsByArticleSlugRow, error)
+	GetFavoritesCount(ctx context.Context, articleID int64) (int64, error)
+	GetFollowingByIDs(ctx context.Context, arg GetFollowingByIDsParams) ([]int64, error)
+	GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error)
+	GetOrCreateTag(ctx context.Context, name string) (Tag, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetUserByID(ctx context.Context, id int64) (User, error)
+	GetUserByUsername(ctx context.Context, username string) (User, error)
+	IsFavorited(ctx context.Context, arg IsFavoritedParams) (bool, error)
+	IsFollowing(ctx context.Context, arg IsFollowingParams) (bool, error)
+	ListArticles(ctx context.Context, arg ListArticlesParams) ([]ListArticlesRow, error)
+	ListArticlesFeed(ctx context.Context, arg ListArticlesFeedParams) ([]ListArticlesFeedRow, error)
+	ListFeedItems(ctx context.Context, arg ListFeedItemsParams) ([]ListFeedItemsRow, error)
+	ListLoginEvents(ctx context.Context, arg ListLoginEventsParams) ([]LoginEvent, error)
+	ListLoginFailures(ctx context.Context, arg ListLoginFailuresParams) ([]time.Time, error)
+	NextJob(ctx context.Context) (Job, error)
+	PruneFeed(ctx context.Context, arg PruneFeedParams) error
+	ReserveIdempotencyKey(ctx context.Context, arg ReserveIdempotencyKeyParams) (int64, error)
+	RetryJob(ctx context.Context, arg RetryJobParams) error
+	SaveIdempotencyResponse(ctx context.Context, arg SaveIdempotencyResponseParams) error
+	TakeRateLimit(ctx context.Context, arg TakeRateLimitParams) (TakeRateLimitRow, error)
+	TrimUnknownLoginEvents(ctx context.Context, keep int64) error
+	UpdateArticle(ctx context.Context, arg UpdateArticleParams) (Article, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+}
@@ -0,0 +1,658 @@
+// Package storetest is the conformance suite every [store.Store] implementation must pass.
+// Backends run it from their own tests, so SQLite and PostgreSQL are held to the same behavior.
+package storetest
+
+import (
+	"cmp"
+	"database/sql"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+// Run runs the conformance suite. open must return an empty store with the schema applied,
+// and is called once per subtest so that subtests do not see each other's rows.
+func Run(t *testing.T, open func(t *testing.T) store.Store) {
+	t.Helper()
+
+	t.Run("Users", func(t *testing.T) { testUsers(t, open(t)) })
+	t.Run("Follows", func(t *testing.T) { testFollows(t, open(t)) })
+	t.Run("ArticlesAndTags", func(t *testing.T) { testArticlesAndTags(t, open(t)) })
+	t.Run("ListArticles", func(t *testing.T) { testListArticles(t, open(t)) })
+	t.Run("Favorites", func(t *testing.T) { testFavorites(t, open(t)) })
+	t.Run("Feed", func(t *testing.T) { testFeed(t, open(t)) })
+	t.Run("FeedItems", func(t *testing.T) { testFeedItems(t, open(t)) })
+	t.Run("Jobs", func(t *testing.T) { testJobs(t, open(t)) })
+	t.Run("IdempotencyKeys", func(t *testing.T) { testIdempotencyKeys(t, open(t)) })
+	t.Run("RateLimits", func(t *testing.T) { testRateLimits(t, open(t)) })
+	t.Run("LoginEvents", func(t *testing.T) { testLoginEvents(t, open(t)) })
+	t.Run("Comments", func(t *testing.T) { testComments(t, open(t)) })
+	t.Run("Transactions", func(t *testing.T) { testTransactions(t, open(t)) })
+	t.Run("Schema", func(t *testing.T) { testSchema(t, open(t)) })
+}
+
+func testUsers(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	user, err := s.CreateUser(ctx, store.CreateUserParams{
+		Username: "jake",
+		Email:    "jake@example.com",
+		Password: "jakejake",
+		Bio:      sql.NullString{String: "I work at statefarm", Valid: true},
+	})
+	test.Nil(t, err)
+	test.NotZero(t, user.ID)
+	test.Equal(t, "jake", user.Username)
+	test.Equal(t, "I work at statefarm", user.Bio.String)
+	test.Equal(t, false, user.Image.Valid)
+	test.Equal(t, false, user.DisabledAt.Valid)
+	test.NotZero(t, user.CreatedAt)
+
+	byID, err := s.GetUserByID(ctx, user.ID)
+	test.Nil(t, err)
+	test.Equal(t, user, byID)
+	byEmail, err := s.GetUserByEmail(ctx, "jake@example.com")
+	test.Nil(t, err)
+	test.Equal(t, user.ID, byEmail.ID)
+	byUsername, err := s.GetUserByUsername(ctx, "jake")
+	test.Nil(t, err)
+	test.Equal(t, user.ID, byUsername.ID)
+
+	_, err = s.GetUserByUsername(ctx, "nobody")
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+
+	// Only the given fields change
+	updated, err := s.UpdateUser(ctx, store.UpdateUserParams{
+		ID:    user.ID,
+		Image: sql.NullString{String: "https://example.com/jake.png", Valid: true},
+	})
+	test.Nil(t, err)
+	test.Equal(t, "jake", updated.Username)
+	test.Equal(t, "I work at statefarm", updated.Bio.String)
+	test.Equal(t, "https://example.com/jake.png", updated.Image.String)
+}
+
+func testFollows(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake, celeb, other := createUser(t, s, "jake"), createUser(t, s, "celeb"), createUser(t, s, "other")
+
+	follow := store.CreateFollowParams{FollowerID: jake.ID, FollowedID: celeb.ID}
+	test.Nil(t, s.CreateFollow(ctx, follow))
+	test.Nil(t, s.CreateFollow(ctx, follow)) // following twice is not an error
+
+	following, err := s.IsFollowing(ctx, store.IsFollowingParams{FollowerID: jake.ID, FollowedID: celeb.ID})
+	test.Nil(t, err)
+	test.True(t, following)
+	following, err = s.IsFollowing(ctx, store.IsFollowingParams{FollowerID: celeb.ID, FollowedID: jake.ID})
+	test.Nil(t, err)
+	test.Equal(t, false, following)
+
+	ids, err := s.GetFollowingByIDs(ctx, store.GetFollowingByIDsParams{FollowerID: jake.ID, FollowedIds: []int64{celeb.ID, other.ID}})
+	test.Nil(t, err)
+	test.DeepEqual(t, []int64{celeb.ID}, ids)
+	test.Nil(t, s.CreateFollow(ctx, store.CreateFollowParams{FollowerID: other.ID, FollowedID: celeb.ID}))
+	user, err := s.GetUserByID(ctx, celeb.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(2), user.FollowersCount)
+	test.True(t, user.TouchedAt.Valid) // follows move the Last-Modified of profiles
+	test.Equal(t, false, jake.TouchedAt.Valid)
+
+	test.Nil(t, s.DeleteFollow(ctx, store.DeleteFollowParams{FollowerID: jake.ID, FollowedID: celeb.ID}))
+	following, err = s.IsFollowing(ctx, store.IsFollowingParams{FollowerID: jake.ID, FollowedID: celeb.ID})
+	test.Nil(t, err)
+	test.Equal(t, false, following)
+	test.Nil(t, s.DeleteFollow(ctx, store.DeleteFollowParams{FollowerID: jake.ID, FollowedID: celeb.ID})) // a no-op, not a second decrement
+	user, err = s.GetUserByID(ctx, celeb.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), user.FollowersCount)
+}
+
+func testArticlesAndTags(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	author := createUser(t, s, "author")
+	article := createArticle(t, s, author, "how-to-train", "training", "dragons")
+
+	got, err := s.GetArticleBySlug(ctx, "how-to-train")
+	test.Nil(t, err)
+	test.Equal(t, article.ID, got.ID)
+	test.Equal(t, "author", got.AuthorUsername)
+	test.Equal(t, article.CreatedAt, got.CreatedAt)
+	_, err = s.GetArticleBySlug(ctx, "missing")
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+
+	// GetOrCreateTag returns the existing tag instead of failing on the unique name
+	first, err := s.GetOrCreateTag(ctx, "dragons")
+	test.Nil(t, err)
+	second, err := s.GetOrCreateTag(ctx, "dragons")
+	test.Nil(t, err)
+	test.Equal(t, first.ID, second.ID)
+
+	tags, err := s.GetArticleTagsByArticleID(ctx, article.ID)
+	test.Nil(t, err)
+	test.DeepEqual(t, []string{"dragons", "training"}, tags)
+
+	other := createArticle(t, s, author, "other", "angular")
+	rows, err := s.GetArticleTagsByArticleIDs(ctx, []int64{article.ID, other.ID})
+	test.Nil(t, err)
+	test.Equal(t, 3, len(rows))
+
+	all, err := s.GetAllTags(ctx)
+	test.Nil(t, err)
+	test.DeepEqual(t, []string{"angular", "dragons", "training"}, all)
+
+	updated, err := s.UpdateArticle(ctx, store.UpdateArticleParams{
+		ID:    article.ID,
+		Title: sql.NullString{String: "New title", Valid: true},
+		Slug:  sql.NullString{String: "new-title", Valid: true},
+	})
+	test.Nil(t, err)
+	test.Equal(t, "New title", updated.Title)
+	test.Equal(t, "new-title", updated.Slug)
+	test.Equal(t, article.Body, updated.Body)
+
+	// Deleting an article removes its tag associations, but keeps the tags
+	test.Nil(t, s.DeleteArticle(ctx, article.ID))
+	_, err = s.GetArticleBySlug(ctx, "new-title")
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+	tags, err = s.GetArticleTagsByArticleID(ctx, article.ID)
+	test.Nil(t, err)
+	test.Equal(t, 0, len(tags))
+	all, err = s.GetAllTags(ctx)
+	test.Nil(t, err)
+	test.Equal(t, 3, len(all))
+}
+
+func testListArticles(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake, celeb := createUser(t, s, "jake"), createUser(t, s, "celeb")
+	createArticle(t, s, jake, "jake-go", "go")
+	createArticle(t, s, jake, "jake-sqlite", "go", "sqlite")
+	favorite := createArticle(t, s, celeb, "celeb-go", "go")
+	createArticle(t, s, celeb, "celeb-untagged")
+	test.Nil(t, s.CreateFavorite(ctx, store.CreateFavoriteParams{UserID: jake.ID, ArticleID: favorite.ID}))
+
+	testcases := map[string]struct {
+		filter store.CountArticlesParams
+		want   []string
+	}{
+		"no filter":       {want: []string{"celeb-go", "celeb-untagged", "jake-go", "jake-sqlite"}},
+		"tag":             {filter: store.CountArticlesParams{Tag: valid("go")}, want: []string{"celeb-go", "jake-go", "jake-sqlite"}},
+		"author":          {filter: store.CountArticlesParams{Author: valid("celeb")}, want: []string{"celeb-go", "celeb-untagged"}},
+		"favorited":       {filter: store.CountArticlesParams{Favorited: valid("jake")}, want: []string{"celeb-go"}},
+		"tag and author":  {filter: store.CountArticlesParams{Tag: valid("go"), Author: valid("jake")}, want: []string{"jake-go", "jake-sqlite"}},
+		"unknown tag":     {filter: store.CountArticlesParams{Tag: valid("rust")}, want: []string{}},
+		"unknown favored": {filter: store.CountArticlesParams{Favorited: valid("nobody")}, want: []string{}},
+	}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			rows, err := s.ListArticles(ctx, store.ListArticlesParams{
+				Tag:       tc.filter.Tag,
+				Author:    tc.filter.Author,
+				Favorited: tc.filter.Favorited,
+				Limit:     20,
+			})
+			test.Nil(t, err)
+			slugs := make([]string, len(rows))
+			for i, row := range rows {
+				slugs[i] = row.Slug
+			}
+			slices.Sort(slugs)
+			test.DeepEqual(t, tc.want, slugs)
+
+			count, err := s.CountArticles(ctx, tc.filter)
+			test.Nil(t, err)
+			test.Equal(t, int64(len(tc.want)), count)
+		})
+	}
+
+	// Limit and offset page through the list without overlap
+	first, err := s.ListArticles(ctx, store.ListArticlesParams{Limit: 3})
+	test.Nil(t, err)
+	test.Equal(t, 3, len(first))
+	rest, err := s.ListArticles(ctx, store.ListArticlesParams{Limit: 3, Offset: 3})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(rest))
+	for _, row := range first {
+		test.NotEqual(t, rest[0].ID, row.ID)
+	}
+
+	// Keyset pages are ordered by (created_at, id) descending, which breaks ties of articles created in the same second,
+	// and an article created while paging does not shift the pages after it
+	var ids []int64
+	params := store.ListArticlesParams{Limit: 1}
+	for {
+		rows, err := s.ListArticles(ctx, params)
+		test.Nil(t, err)
+		if len(rows) == 0 {
+			break
+		}
+		if len(ids) == 1 {
+			createArticle(t, s, jake, "jake-late")
+		}
+		ids = append(ids, rows[0].ID)
+		params.BeforeCreatedAt = sql.NullTime{Time: rows[0].CreatedAt, Valid: true}
+		params.BeforeID = sql.NullInt64{Int64: rows[0].ID, Valid: true}
+	}
+	test.Equal(t, 4, len(ids))
+	test.True(t, slices.IsSortedFunc(ids, func(a, b int64) int { return cmp.Compare(b, a) }))
+}
+
+func testFavorites(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake, celeb := createUser(t, s, "jake"), createUser(t, s, "celeb")
+	article := createArticle(t, s, celeb, "celeb-go")
+	other := createArticle(t, s, celeb, "celeb-sqlite")
+
+	for _, user := range []store.User{jake, celeb} {
+		favorite := store.CreateFavoriteParams{UserID: user.ID, ArticleID: article.ID}
+		test.Nil(t, s.CreateFavorite(ctx, favorite))
+		test.Nil(t, s.CreateFavorite(ctx, favorite)) // favoriting twice is not an error
+	}
+
+	count, err := s.GetFavoritesCount(ctx, article.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(2), count)
+	// Favorites move the Last-Modified of the article, and the article shows its author's
+	row, err := s.GetArticleBySlug(ctx, "celeb-go")
+	test.Nil(t, err)
+	test.True(t, row.TouchedAt.Valid)
+	test.Equal(t, celeb.UpdatedAt, row.AuthorUpdatedAt)
+	test.Equal(t, false, row.AuthorTouchedAt.Valid)
+	untouched, err := s.GetArticleBySlug(ctx, "celeb-sqlite")
+	test.Nil(t, err)
+	test.Equal(t, false, untouched.TouchedAt.Valid)
+	favorited, err := s.IsFavorited(ctx, store.IsFavoritedParams{UserID: jake.ID, ArticleID: article.ID})
+	test.Nil(t, err)
+	test.True(t, favorited)
+
+	rows, err := s.ListArticles(ctx, store.ListArticlesParams{Author: valid("celeb"), Limit: 20})
+	test.Nil(t, err)
+	counts := make(map[string]int64)
+	for _, row := range rows {
+		counts[row.Slug] = row.FavoritesCount
+	}
+	test.DeepEqual(t, map[string]int64{"celeb-go": 2, "celeb-sqlite": 0}, counts)
+	ids, err := s.CheckFavoritedByUser(ctx, store.CheckFavoritedByUserParams{UserID: jake.ID, ArticleIds: []int64{article.ID, other.ID}})
+	test.Nil(t, err)
+	test.DeepEqual(t, []int64{article.ID}, ids)
+
+	test.Nil(t, s.DeleteFavorite(ctx, store.DeleteFavoriteParams{UserID: jake.ID, ArticleID: article.ID}))
+	favorited, err = s.IsFavorited(ctx, store.IsFavoritedParams{UserID: jake.ID, ArticleID: article.ID})
+	test.Nil(t, err)
+	test.Equal(t, false, favorited)
+	count, err = s.GetFavoritesCount(ctx, article.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), count)
+}
+
+func testFeed(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake, celeb, other := createUser(t, s, "jake"), createUser(t, s, "celeb"), createUser(t, s, "other")
+	createArticle(t, s, celeb, "celeb-go")
+	createArticle(t, s, celeb, "celeb-sqlite")
+	createArticle(t, s, other, "other-go")
+	test.Nil(t, s.CreateFollow(ctx, store.CreateFollowParams{FollowerID: jake.ID, FollowedID: celeb.ID}))
+
+	rows, err := s.ListArticlesFeed(ctx, store.ListArticlesFeedParams{FollowerID: jake.ID, Limit: 20})
+	test.Nil(t, err)
+	test.Equal(t, 2, len(rows))
+	for _, row := range rows {
+		test.Equal(t, "celeb", row.AuthorUsername)
+	}
+	count, err := s.CountArticlesFeed(ctx, jake.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(2), count)
+
+	rows, err = s.ListArticlesFeed(ctx, store.ListArticlesFeedParams{FollowerID: jake.ID, Limit: 1, Offset: 1})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(rows))
+
+	first := rows[0]
+	rows, err = s.ListArticlesFeed(ctx, store.ListArticlesFeedParams{
+		FollowerID:      jake.ID,
+		BeforeCreatedAt: sql.NullTime{Time: first.CreatedAt, Valid: true},
+		BeforeID:        sql.NullInt64{Int64: first.ID, Valid: true},
+		Limit:           20,
+	})
+	test.Nil(t, err)
+	test.Equal(t, 0, len(rows)) // the second of two articles is the last
+
+	count, err = s.CountArticlesFeed(ctx, celeb.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(0), count)
+}
+
+func testFeedItems(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake, celeb, other := createUser(t, s, "jake"), createUser(t, s, "celeb"), createUser(t, s, "other")
+	older := createArticle(t, s, celeb, "celeb-go")
+	createArticle(t, s, other, "other-go")
+
+	// A follow backfills the articles of the followed author, and only of that author
+	test.Nil(t, s.CreateFollow(ctx, store.CreateFollowParams{FollowerID: jake.ID, FollowedID: celeb.ID}))
+	test.Nil(t, s.BackfillFeed(ctx, store.BackfillFeedParams{FollowerID: jake.ID, AuthorID: celeb.ID}))
+	test.Nil(t, s.BackfillFeed(ctx, store.BackfillFeedParams{FollowerID: jake.ID, AuthorID: other.ID}))
+	count, err := s.CountFeedItems(ctx, jake.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), count)
+
+	// Fanning out an article reaches the followers of its author, and running it twice changes nothing
+	newer := createArticle(t, s, celeb, "celeb-sqlite")
+	test.Nil(t, s.FanOutArticle(ctx, newer.ID))
+	test.Nil(t, s.FanOutArticle(ctx, newer.ID))
+	rows, err := s.ListFeedItems(ctx, store.ListFeedItemsParams{UserID: jake.ID, Limit: 20})
+	test.Nil(t, err)
+	test.Equal(t, 2, len(rows))
+	test.Equal(t, newer.ID, rows[0].ID)
+	test.Equal(t, "celeb", rows[0].AuthorUsername)
+	test.Equal(t, older.ID, rows[1].ID)
+
+	// The feed items hold the same feed as the feed query
+	feed, err := s.ListArticlesFeed(ctx, store.ListArticlesFeedParams{FollowerID: jake.ID, Limit: 20})
+	test.Nil(t, err)
+	test.Equal(t, len(feed), len(rows))
+	for i := range feed {
+		test.Equal(t, feed[i].ID, rows[i].ID)
+	}
+
+	rows, err = s.ListFeedItems(ctx, store.ListFeedItemsParams{
+		UserID:          jake.ID,
+		BeforeCreatedAt: sql.NullTime{Time: rows[0].CreatedAt, Valid: true},
+		BeforeID:        sql.NullInt64{Int64: rows[0].ID, Valid: true},
+		Limit:           20,
+	})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(rows))
+	test.Equal(t, older.ID, rows[0].ID)
+
+	// Pruning keeps the items of authors still followed, and removes them once unfollowed
+	test.Nil(t, s.PruneFeed(ctx, store.PruneFeedParams{FollowerID: jake.ID, AuthorID: celeb.ID}))
+	count, err = s.CountFeedItems(ctx, jake.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(2), count)
+	test.Nil(t, s.DeleteFollow(ctx, store.DeleteFollowParams{FollowerID: jake.ID, FollowedID: celeb.ID}))
+	test.Nil(t, s.PruneFeed(ctx, store.PruneFeedParams{FollowerID: jake.ID, AuthorID: celeb.ID}))
+	count, err = s.CountFeedItems(ctx, jake.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(0), count)
+}
+
+func testJobs(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	_, err := s.NextJob(ctx)
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+
+	test.Nil(t, s.CreateJob(ctx, store.CreateJobParams{Kind: "first", Payload: `{"n":1}`}))
+	test.Nil(t, s.CreateJob(ctx, store.CreateJobParams{Kind: "second", Payload: `{"n":2}`}))
+	first, err := s.NextJob(ctx)
+	test.Nil(t, err)
+	test.Equal(t, "first", first.Kind)
+	test.Equal(t, `{"n":1}`, first.Payload)
+	test.Equal(t, int64(0), first.Attempts)
+
+	// A retried job waits for its delay, letting the next one through
+	test.Nil(t, s.RetryJob(ctx, store.RetryJobParams{LastError: valid("boom"), DelaySeconds: 3600, ID: first.ID}))
+	second, err := s.NextJob(ctx)
+	test.Nil(t, err)
+	test.Equal(t, "second", second.Kind)
+	test.Nil(t, s.DeleteJob(ctx, second.ID))
+	_, err = s.NextJob(ctx)
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+
+	// and comes back once due, counting its attempts
+	test.Nil(t, s.RetryJob(ctx, store.RetryJobParams{LastError: valid("boom again"), DelaySeconds: 0, ID: first.ID}))
+	retried, err := s.NextJob(ctx)
+	test.Nil(t, err)
+	test.Equal(t, first.ID, retried.ID)
+	test.Equal(t, int64(2), retried.Attempts)
+	test.Equal(t, valid("boom again"), retried.LastError)
+}
+
+func testIdempotencyKeys(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake, celeb := createUser(t, s, "jake"), createUser(t, s, "celeb")
+	reserve := func(user store.User, key string, ttlSeconds int64) int64 {
+		t.Helper()
+		reserved, err := s.ReserveIdempotencyKey(ctx, store.ReserveIdempotencyKeyParams{
+			UserID: user.ID, IdempotencyKey: key, RequestHash: "hash", TtlSeconds: ttlSeconds,
+		})
+		test.Nil(t, err)
+		return reserved
+	}
+
+	// A key is reserved once per user, running until its response is saved
+	test.Equal(t, int64(1), reserve(jake, "retry", 3600))
+	test.Equal(t, int64(0), reserve(jake, "retry", 3600))
+	test.Equal(t, int64(1), reserve(celeb, "retry", 3600))
+	running, err := s.GetIdempotencyKey(ctx, store.GetIdempotencyKeyParams{UserID: jake.ID, IdempotencyKey: "retry"})
+	test.Nil(t, err)
+	test.Equal(t, "hash", running.RequestHash)
+	test.Equal(t, int64(0), running.Status)
+
+	test.Nil(t, s.SaveIdempotencyResponse(ctx, store.SaveIdempotencyResponseParams{
+		Status: 201, Header: `{"Content-Type":["application/json"]}`, Body: `{"ok":true}`, UserID: jake.ID, IdempotencyKey: "retry",
+	}))
+	saved, err := s.GetIdempotencyKey(ctx, store.GetIdempotencyKeyParams{UserID: jake.ID, IdempotencyKey: "retry"})
+	test.Nil(t, err)
+	test.Equal(t, int64(201), saved.Status)
+	test.Equal(t, `{"ok":true}`, saved.Body)
+	test.Equal(t, int64(0), reserve(jake, "retry", 3600))
+
+	test.Nil(t, s.DeleteIdempotencyKey(ctx, store.DeleteIdempotencyKeyParams{UserID: jake.ID, IdempotencyKey: "retry"}))
+	test.Equal(t, int64(1), reserve(jake, "retry", 3600))
+
+	// An expired key is free to reserve again, until it is deleted
+	test.Equal(t, int64(1), reserve(jake, "expired", 0))
+	test.Equal(t, int64(1), reserve(jake, "expired", 0))
+	deleted, err := s.DeleteExpiredIdempotencyKeys(ctx)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), deleted)
+	_, err = s.GetIdempotencyKey(ctx, store.GetIdempotencyKeyParams{UserID: jake.ID, IdempotencyKey: "expired"})
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+}
+
+func testRateLimits(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	// A bucket of 3 requests, refilled with one every 100ms
+	take := func(key string, nowMs int64) store.TakeRateLimitRow {
+		t.Helper()
+		row, err := s.TakeRateLimit(ctx, store.TakeRateLimitParams{Key: key, NowMs: nowMs, IntervalMs: 100, ToleranceMs: 300})
+		test.Nil(t, err)
+		return row
+	}
+
+	test.Equal(t, store.TakeRateLimitRow{Tat: 1100, Allowed: true}, take("ip:1", 1000))
+	test.Equal(t, store.TakeRateLimitRow{Tat: 1200, Allowed: true}, take("ip:1", 1000))
+	test.Equal(t, store.TakeRateLimitRow{Tat: 1300, Allowed: true}, take("ip:1", 1000))
+	test.Equal(t, store.TakeRateLimitRow{Tat: 1300, Allowed: false}, take("ip:1", 1050))
+	test.Equal(t, store.TakeRateLimitRow{Tat: 1100, Allowed: true}, take("ip:2", 1000))
+	test.Equal(t, store.TakeRateLimitRow{Tat: 1400, Allowed: true}, take("ip:1", 1100))
+	test.Equal(t, store.TakeRateLimitRow{Tat: 2100, Allowed: true}, take("ip:1", 2000))
+
+	// Buckets that have refilled since are deleted
+	deleted, err := s.DeleteExpiredRateLimits(ctx, 1500)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), deleted)
+}
+
+func testLoginEvents(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake := createUser(t, s, "jake")
+	login := func(success bool) {
+		t.Helper()
+		test.Nil(t, s.CreateLoginEvent(ctx, store.CreateLoginEventParams{
+			UserID: sql.NullInt64{Int64: jake.ID, Valid: true}, Email: jake.Email, Ip: "192.0.2.1", UserAgent: "test", Success: success,
+		}))
+	}
+	failures := func(limit int64) int {
+		t.Helper()
+		times, err := s.ListLoginFailures(ctx, store.ListLoginFailuresParams{Email: jake.Email, WindowSeconds: 3600, Limit: limit})
+		test.Nil(t, err)
+		return len(times)
+	}
+
+	login(false)
+	login(false)
+	test.Equal(t, 2, failures(10))
+	test.Equal(t, 1, failures(1))
+	// Failures count from the last successful login
+	login(true)
+	test.Equal(t, 0, failures(10))
+	login(false)
+	test.Equal(t, 1, failures(10))
+	// Failures to emails of no user count too
+	test.Nil(t, s.CreateLoginEvent(ctx, store.CreateLoginEventParams{Email: "nobody@example.com", Ip: "192.0.2.1", UserAgent: "test"}))
+	times, err := s.ListLoginFailures(ctx, store.ListLoginFailuresParams{Email: "nobody@example.com", WindowSeconds: 3600, Limit: 10})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(times))
+	test.True(t, time.Since(times[0]) < time.Minute)
+
+	events, err := s.ListLoginEvents(ctx, store.ListLoginEventsParams{UserID: sql.NullInt64{Int64: jake.ID, Valid: true}, Limit: 3})
+	test.Nil(t, err)
+	test.Equal(t, 3, len(events))
+	test.False(t, events[0].Success)
+	test.True(t, events[1].Success)
+	test.Equal(t, "192.0.2.1", events[0].Ip)
+	test.Equal(t, "test", events[0].UserAgent)
+
+	// Only the latest logins to emails of no user are kept
+	test.Nil(t, s.CreateLoginEvent(ctx, store.CreateLoginEventParams{Email: "someone@example.com", Ip: "192.0.2.1", UserAgent: "test"}))
+	test.Nil(t, s.TrimUnknownLoginEvents(ctx, 1))
+	times, err = s.ListLoginFailures(ctx, store.ListLoginFailuresParams{Email: "nobody@example.com", WindowSeconds: 3600, Limit: 10})
+	test.Nil(t, err)
+	test.Equal(t, 0, len(times))
+	times, err = s.ListLoginFailures(ctx, store.ListLoginFailuresParams{Email: "someone@example.com", WindowSeconds: 3600, Limit: 10})
+	test.Nil(t, err)
+	test.Equal(t, 1, len(times))
+
+	// Logins to emails of no user are kept for less long than those of users
+	unknown, err := s.CountUnknownLoginEvents(ctx)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), unknown)
+	n, err := s.DeleteOldLoginEvents(ctx, store.DeleteOldLoginEventsParams{RetentionSeconds: 3600, UnknownRetentionSeconds: 0})
+	test.Nil(t, err)
+	test.Equal(t, int64(1), n)
+	unknown, err = s.CountUnknownLoginEvents(ctx)
+	test.Nil(t, err)
+	test.Equal(t, int64(0), unknown)
+	n, err = s.DeleteOldLoginEvents(ctx, store.DeleteOldLoginEventsParams{RetentionSeconds: 0, UnknownRetentionSeconds: 0})
+	test.Nil(t, err)
+	test.Equal(t, int64(4), n)
+}
+
+func testComments(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	jake, celeb := createUser(t, s, "jake"), createUser(t, s, "celeb")
+	article := createArticle(t, s, celeb, "celeb-go")
+
+	comment, err := s.CreateComment(ctx, store.CreateCommentParams{Body: "Nice post", ArticleID: article.ID, AuthorID: jake.ID})
+	test.Nil(t, err)
+	test.NotZero(t, comment.ID)
+	_, err = s.CreateComment(ctx, store.CreateCommentParams{Body: "Thanks", ArticleID: article.ID, AuthorID: celeb.ID})
+	test.Nil(t, err)
+
+	withAuthor, err := s.GetCommentWithAuthor(ctx, comment.ID)
+	test.Nil(t, err)
+	test.Equal(t, "Nice post", withAuthor.Body)
+	test.Equal(t, "jake", withAuthor.AuthorUsername)
+
+	byID, err := s.GetCommentByID(ctx, comment.ID)
+	test.Nil(t, err)
+	test.Equal(t, comment, byID)
+
+	comments, err := s.GetCommentsByArticleSlug(ctx, "celeb-go")
+	test.Nil(t, err)
+	test.Equal(t, 2, len(comments))
+	counted, err := s.GetArticleBySlug(ctx, "celeb-go")
+	test.Nil(t, err)
+	test.Equal(t, int64(2), counted.CommentsCount)
+	test.True(t, counted.TouchedAt.Valid)
+
+	test.Nil(t, s.DeleteComment(ctx, comment.ID))
+	_, err = s.GetCommentByID(ctx, comment.ID)
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+	counted, err = s.GetArticleBySlug(ctx, "celeb-go")
+	test.Nil(t, err)
+	test.Equal(t, int64(1), counted.CommentsCount)
+
+	// Deleting the article deletes its comments
+	test.Nil(t, s.DeleteArticle(ctx, article.ID))
+	comments, err = s.GetCommentsByArticleSlug(ctx, "celeb-go")
+	test.Nil(t, err)
+	test.Equal(t, 0, len(comments))
+}
+
+func testSchema(t *testing.T, s store.Store) {
+	ctx := t.Context()
+	test.Nil(t, s.Ping(ctx))
+
+	columns, err := s.Columns(ctx)
+	test.Nil(t, err)
+	for _, table := range []string{"users", "articles", "comments", "jobs", "login_events"} {
+		test.NotZero(t, len(columns[table]))
+	}
+	test.Equal(t, "id", columns["users"][0])
+	test.True(t, slices.Contains(columns["users"], "disabled_at"))
+}
+
+func testTransactions(t *testing.T, s store.Store) {
+	ctx := t.Context()
+
+	tx, err := s.BeginTx(ctx)
+	test.Nil(t, err)
+	_, err = tx.CreateUser(ctx, store.CreateUserParams{Username: "rolledback", Email: "rolledback@example.com", Password: "password"})
+	test.Nil(t, err)
+	_, err = tx.GetUserByUsername(ctx, "rolledback") // a transaction sees its own writes
+	test.Nil(t, err)
+	test.Nil(t, tx.Rollback())
+	_, err = s.GetUserByUsername(ctx, "rolledback")
+	test.True(t, errors.Is(err, sql.ErrNoRows))
+
+	tx, err = s.BeginTx(ctx)
+	test.Nil(t, err)
+	_, err = tx.CreateUser(ctx, store.CreateUserParams{Username: "committed", Email: "committed@example.com", Password: "password"})
+	test.Nil(t, err)
+	test.Nil(t, tx.Commit())
+	_, err = s.GetUserByUsername(ctx, "committed")
+	test.Nil(t, err)
+}
+
+func createUser(t *testing.T, s store.Store, username string) store.User {
+	t.Helper()
+
+	user, err := s.CreateUser(t.Context(), store.CreateUserParams{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: "password",
+	})
+	test.Nil(t, err)
+	return user
+}
+
+func createArticle(t *testing.T, s store.Store, author store.User, slug string, tags ...string) store.Article {
+	t.Helper()
+
+	article, err := s.CreateArticle(t.Context(), store.CreateArticleParams{
+		Slug:        slug,
+		Title:       slug,
+		Description: "description of " + slug,
+		Body:        "body of " + slug,
+		AuthorID:    author.ID,
+	})
+	test.Nil(t, err)
+	for _, name := range tags {
+		tag, err := s.GetOrCreateTag(t.Context(), name)
+		test.Nil(t, err)
+		test.Nil(t, s.AssociateArticleTag(t.Context(), store.AssociateArticleTagParams{ArticleID: article.ID, TagID: tag.ID}))
+	}
+	return article
+}
+
+func valid(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
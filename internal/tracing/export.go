@@ -0,0 +1,205 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// StdoutExporter writes spans as JSON lines, one per span, to a writer such as os.Stdout.
+type StdoutExporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewStdoutExporter returns an exporter that writes spans to w.
+func NewStdoutExporter(w io.Writer) *StdoutExporter {
+	return &StdoutExporter{w: w}
+}
+
+// StdoutSpan is the JSON object that [StdoutExporter] writes for each span.
+type StdoutSpan struct {
+	Name         string         `json:"name"`
+	Kind         string         `json:"kind"`
+	TraceID      string         `json:"traceId"`
+	SpanID       string         `json:"spanId"`
+	ParentSpanID string         `json:"parentSpanId,omitempty"`
+	Start        time.Time      `json:"start"`
+	End          time.Time      `json:"end"`
+	Attributes   map[string]any `json:"attributes,omitempty"`
+	Error        string         `json:"error,omitempty"`
+}
+
+// Export writes spans to the writer of e.
+func (e *StdoutExporter) Export(_ context.Context, spans []SpanData) error {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	for _, span := range spans {
+		out := StdoutSpan{
+			Name:    span.Name,
+			Kind:    span.Kind.String(),
+			TraceID: span.TraceID.String(),
+			SpanID:  span.SpanID.String(),
+			Start:   span.Start,
+			End:     span.End,
+			Error:   span.Error,
+		}
+		if span.ParentSpanID.IsValid() {
+			out.ParentSpanID = span.ParentSpanID.String()
+		}
+		if len(span.Attributes) > 0 {
+			out.Attributes = make(map[string]any, len(span.Attributes))
+			for _, attr := range span.Attributes {
+				out.Attributes[attr.Key] = attr.Value
+			}
+		}
+		if err := enc.Encode(out); err != nil {
+			return err
+		}
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	_, err := e.w.Write(b.Bytes())
+	return err
+}
+
+// OTLPExporter sends spans to an OpenTelemetry collector with the OTLP/HTTP protocol, in its JSON encoding.
+type OTLPExporter struct {
+	url     string
+	service string
+	client  *http.Client
+}
+
+// NewOTLPExporter returns an exporter to the collector at endpoint, such as http://localhost:4318,
+// whose spans are from the service named service. Spans are posted to the /v1/traces path of endpoint.
+func NewOTLPExporter(endpoint, service string, client *http.Client) *OTLPExporter {
+	return &OTLPExporter{url: strings.TrimSuffix(endpoint, "/") + "/v1/traces", service: service, client: client}
+}
+
+// Export posts spans to the collector of e.
+func (e *OTLPExporter) Export(ctx context.Context, spans []SpanData) error {
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close() //nolint:errcheck
+	_, _ = io.Copy(io.Discard, res.Body)
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("export spans to %s: %s", e.url, res.Status)
+	}
+	return nil
+}
+
+// The types below are the messages of the OTLP trace service in their JSON encoding,
+// in which IDs are hex strings and 64-bit integers are decimal strings.
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpKeyValue `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name string `json:"name"`
+	}
+	otlpSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              Kind           `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []otlpKeyValue `json:"attributes,omitempty"`
+		Status            *otlpStatus    `json:"status,omitempty"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+	otlpKeyValue struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue *string  `json:"stringValue,omitempty"`
+		IntValue    *string  `json:"intValue,omitempty"`
+		DoubleValue *float64 `json:"doubleValue,omitempty"`
+		BoolValue   *bool    `json:"boolValue,omitempty"`
+	}
+)
+
+// otlpStatusError is STATUS_CODE_ERROR. Spans without an error leave their status unset.
+const otlpStatusError = 2
+
+func (e *OTLPExporter) request(spans []SpanData) otlpRequest {
+	out := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		out[i] = otlpSpan{
+			TraceID:           span.TraceID.String(),
+			SpanID:            span.SpanID.String(),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+		if span.ParentSpanID.IsValid() {
+			out[i].ParentSpanID = span.ParentSpanID.String()
+		}
+		if span.Error != "" {
+			out[i].Status = &otlpStatus{Code: otlpStatusError, Message: span.Error}
+		}
+	}
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   otlpResource{Attributes: otlpAttributes([]Attr{String("service.name", e.service)})},
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScope{Name: "github.com/raeperd/realworld.go"}, Spans: out}},
+	}}}
+}
+
+func otlpAttributes(attrs []Attr) []otlpKeyValue {
+	out := make([]otlpKeyValue, 0, len(attrs))
+	for _, attr := range attrs {
+		var v otlpValue
+		switch value := attr.Value.(type) {
+		case string:
+			v.StringValue = &value
+		case int64:
+			s := strconv.FormatInt(value, 10)
+			v.IntValue = &s
+		case float64:
+			v.DoubleValue = &value
+		case bool:
+			v.BoolValue = &value
+		default:
+			s := fmt.Sprint(value)
+			v.StringValue = &s
+		}
+		out = append(out, otlpKeyValue{Key: attr.Key, Value: v})
+	}
+	return out
+}
@@ -0,0 +1,403 @@
+// Package tracing records spans of work, such as HTTP requests and the database queries they run,
+// and exports them in batches to an OpenTelemetry collector or as JSON lines.
+// It covers what the server traces, without the dependencies of the OpenTelemetry SDK:
+// spans are started from a [Tracer] or from the span of a context, and propagate across services
+// with the W3C traceparent header.
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// TraceID identifies a trace, the spans of a request across every service it went through.
+type TraceID [16]byte
+
+// IsValid reports whether id is not all zeros, which the W3C Trace Context spec forbids.
+func (id TraceID) IsValid() bool { return id != TraceID{} }
+
+func (id TraceID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanID identifies a span within its trace.
+type SpanID [8]byte
+
+// IsValid reports whether id is not all zeros, which the W3C Trace Context spec forbids.
+func (id SpanID) IsValid() bool { return id != SpanID{} }
+
+func (id SpanID) String() string { return hex.EncodeToString(id[:]) }
+
+// SpanContext is what a span passes on to its children, in this process or in a traceparent header.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+// IsValid reports whether sc has both a trace and a span ID.
+func (sc SpanContext) IsValid() bool { return sc.TraceID.IsValid() && sc.SpanID.IsValid() }
+
+// Traceparent returns sc as the value of a version 00 traceparent header.
+func (sc SpanContext) Traceparent() string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID.String() + "-" + sc.SpanID.String() + "-" + flags
+}
+
+// ParseTraceparent parses the value of a traceparent header, such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01. Versions after 00 are parsed as 00,
+// ignoring what they append, as the spec requires.
+func ParseTraceparent(s string) (SpanContext, bool) {
+	var sc SpanContext
+	if len(s) < 55 || s[2] != '-' || s[35] != '-' || s[52] != '-' {
+		return sc, false
+	}
+	var version, flags [1]byte
+	if _, err := hex.Decode(version[:], []byte(s[:2])); err != nil || version[0] == 0xff {
+		return sc, false
+	}
+	if version[0] == 0 && len(s) != 55 || version[0] != 0 && len(s) > 55 && s[55] != '-' {
+		return sc, false
+	}
+	if !isLowerHex(s[:2]) || !isLowerHex(s[3:35]) || !isLowerHex(s[36:52]) || !isLowerHex(s[53:55]) {
+		return sc, false
+	}
+	_, _ = hex.Decode(sc.TraceID[:], []byte(s[3:35]))
+	_, _ = hex.Decode(sc.SpanID[:], []byte(s[36:52]))
+	_, _ = hex.Decode(flags[:], []byte(s[53:55]))
+	sc.Sampled = flags[0]&1 == 1
+	return sc, sc.IsValid()
+}
+
+func isLowerHex(s string) bool {
+	for i := range len(s) {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Extract returns the span context of the traceparent header of h, if it has a valid one.
+func Extract(h http.Header) (SpanContext, bool) {
+	return ParseTraceparent(h.Get("traceparent"))
+}
+
+// Inject sets the traceparent header of h to the span context of ctx, if it has one,
+// so that a request to another service continues the trace.
+func Inject(ctx context.Context, h http.Header) {
+	if sc := SpanContextFromContext(ctx); sc.IsValid() {
+		h.Set("traceparent", sc.Traceparent())
+	}
+}
+
+type contextKey int
+
+const (
+	spanKey contextKey = iota
+	remoteKey
+)
+
+// ContextWithRemoteParent returns a copy of ctx in which spans started without a parent span
+// continue the trace of sc, as extracted from a request by [Extract].
+func ContextWithRemoteParent(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, remoteKey, sc)
+}
+
+// SpanFromContext returns the span of ctx, or nil if it has none.
+func SpanFromContext(ctx context.Context) *Span {
+	span, _ := ctx.Value(spanKey).(*Span)
+	return span
+}
+
+// SpanContextFromContext returns the span context of the span of ctx, or else of its remote parent.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if span := SpanFromContext(ctx); span != nil {
+		return span.context
+	}
+	sc, _ := ctx.Value(remoteKey).(SpanContext)
+	return sc
+}
+
+// Kind is the role of a span in a trace, as in OpenTelemetry.
+type Kind int
+
+// Kinds of spans, with the values of the OTLP protocol.
+const (
+	KindInternal Kind = 1 // work within the process, such as encoding a response
+	KindServer   Kind = 2 // a request served to a client
+	KindClient   Kind = 3 // a request to another service, such as a database query
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindServer:
+		return "server"
+	case KindClient:
+		return "client"
+	default:
+		return "internal"
+	}
+}
+
+// Attr is a key and value describing a span. Values are strings, int64s, float64s or bools.
+type Attr struct {
+	Key   string
+	Value any
+}
+
+// String returns a string attribute.
+func String(key, value string) Attr { return Attr{Key: key, Value: value} }
+
+// Int returns an integer attribute.
+func Int(key string, value int) Attr { return Attr{Key: key, Value: int64(value)} }
+
+// Bool returns a boolean attribute.
+func Bool(key string, value bool) Attr { return Attr{Key: key, Value: value} }
+
+// SpanData is an ended span, as exporters receive it.
+type SpanData struct {
+	Name         string
+	Kind         Kind
+	TraceID      TraceID
+	SpanID       SpanID
+	ParentSpanID SpanID // invalid for the root span of a trace
+	Start        time.Time
+	End          time.Time
+	Attributes   []Attr
+	Error        string // the error that failed the span, or empty if it did not fail
+}
+
+// Exporter sends batches of ended spans somewhere, such as an OpenTelemetry collector.
+type Exporter interface {
+	Export(ctx context.Context, spans []SpanData) error
+}
+
+// maxBatch is the number of pending spans that makes [Tracer.Run] export before its interval,
+// and maxPending the number beyond which spans are dropped, when the exporter can't keep up.
+const (
+	maxBatch   = 512
+	maxPending = 4 * maxBatch
+)
+
+// Tracer starts spans and keeps them once ended, until [Tracer.Run] or [Tracer.Flush] export them.
+// A nil *Tracer is valid and traces nothing.
+type Tracer struct {
+	exporter Exporter
+	log      *slog.Logger
+
+	mu      sync.Mutex
+	pending []SpanData
+	dropped int
+	full    chan struct{}
+}
+
+// NewTracer returns a tracer that exports its spans to exporter, logging export errors to log.
+func NewTracer(exporter Exporter, log *slog.Logger) *Tracer {
+	return &Tracer{exporter: exporter, log: log, full: make(chan struct{}, 1)}
+}
+
+// Start starts a span of kind named name, as a child of the span of ctx or of its remote parent,
+// or else as the root of a new trace. It returns a copy of ctx with the span, which must be ended.
+// Spans of a trace whose remote parent was not sampled are not recorded, but still propagate.
+func (t *Tracer) Start(ctx context.Context, name string, kind Kind, attrs ...Attr) (context.Context, *Span) {
+	if t == nil {
+		return ctx, nil
+	}
+	span := &Span{tracer: t, name: name, kind: kind, start: time.Now(), attrs: attrs}
+	if parent := SpanContextFromContext(ctx); parent.IsValid() {
+		span.context = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		span.parent = parent.SpanID
+	} else {
+		span.context = SpanContext{TraceID: newTraceID(), Sampled: true}
+	}
+	span.context.SpanID = newSpanID()
+	return context.WithValue(ctx, spanKey, span), span
+}
+
+// Start starts a span of kind [KindInternal] as a child of the span of ctx, with the tracer of that span.
+// Without a span in ctx, nothing is traced and the returned span is nil, which is valid.
+func Start(ctx context.Context, name string, attrs ...Attr) (context.Context, *Span) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return ctx, nil
+	}
+	return parent.tracer.Start(ctx, name, KindInternal, attrs...)
+}
+
+// Record records a span of kind that already ran from start until now, as a child of the span of ctx,
+// such as a database query observed after the fact. Without a span in ctx, it records nothing.
+func Record(ctx context.Context, name string, kind Kind, start time.Time, err error, attrs ...Attr) {
+	parent := SpanFromContext(ctx)
+	if parent == nil {
+		return
+	}
+	_, span := parent.tracer.Start(ctx, name, kind, attrs...)
+	span.start = start
+	span.SetError(err)
+	span.End()
+}
+
+func (t *Tracer) add(data SpanData) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.pending) >= maxPending {
+		t.dropped++
+		return
+	}
+	t.pending = append(t.pending, data)
+	if len(t.pending) == maxBatch {
+		select {
+		case t.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Run exports the pending spans every interval, or sooner when a batch is full, until ctx is done.
+// Call [Tracer.Flush] after it returns to export what is left.
+func (t *Tracer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-t.full:
+		}
+		if err := t.Flush(ctx); err != nil {
+			t.log.WarnContext(ctx, "failed to export spans", slog.String("error", err.Error()))
+		}
+	}
+}
+
+// Flush exports the pending spans in batches. Spans of a batch that fails to export are lost.
+func (t *Tracer) Flush(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	pending, dropped := t.pending, t.dropped
+	t.pending, t.dropped = nil, 0
+	t.mu.Unlock()
+
+	if dropped > 0 {
+		t.log.WarnContext(ctx, "dropped spans the exporter could not keep up with", slog.Int("spans", dropped))
+	}
+	for len(pending) > 0 {
+		n := min(len(pending), maxBatch)
+		if err := t.exporter.Export(ctx, pending[:n]); err != nil {
+			return err
+		}
+		pending = pending[n:]
+	}
+	return nil
+}
+
+// Span is a unit of work in a trace, from [Tracer.Start] to [Span.End].
+// A nil *Span is valid and records nothing, for code that runs with tracing disabled.
+type Span struct {
+	tracer  *Tracer
+	context SpanContext
+	parent  SpanID
+
+	mu    sync.Mutex
+	name  string
+	kind  Kind
+	start time.Time
+	attrs []Attr
+	err   string
+	ended bool
+}
+
+// Context returns the span context of s, passed on to its children.
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.context
+}
+
+// SetName renames s, for spans whose name is only known once the work is done, such as the route of a request.
+func (s *Span) SetName(name string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.name = name
+}
+
+// SetAttributes adds attrs to s.
+func (s *Span) SetAttributes(attrs ...Attr) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks s as failed by err, unless err is nil.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err.Error()
+}
+
+// End ends s and hands it to its tracer for export, if it is sampled. Only the first call has an effect.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	end := time.Now()
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	data := SpanData{
+		Name:         s.name,
+		Kind:         s.kind,
+		TraceID:      s.context.TraceID,
+		SpanID:       s.context.SpanID,
+		ParentSpanID: s.parent,
+		Start:        s.start,
+		End:          end,
+		Attributes:   s.attrs,
+		Error:        s.err,
+	}
+	s.mu.Unlock()
+	if s.context.Sampled {
+		s.tracer.add(data)
+	}
+}
+
+func newTraceID() TraceID {
+	var id TraceID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:8], rand.Uint64())
+		binary.BigEndian.PutUint64(id[8:], rand.Uint64())
+	}
+	return id
+}
+
+func newSpanID() SpanID {
+	var id SpanID
+	for !id.IsValid() {
+		binary.BigEndian.PutUint64(id[:], rand.Uint64())
+	}
+	return id
+}
@@ -0,0 +1,181 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		header  string
+		ok      bool
+		sampled bool
+	}{
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00", true, false},
+		{"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", true, true},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-future", false, false},
+		{"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", false, false},
+		{"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01", false, false},
+		{"00-00000000000000000000000000000000-00f067aa0ba902b7-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01", false, false},
+		{"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7", false, false},
+		{"", false, false},
+	} {
+		t.Run(tc.header, func(t *testing.T) {
+			sc, ok := ParseTraceparent(tc.header)
+			test.Equal(t, tc.ok, ok)
+			if tc.ok {
+				test.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sc.TraceID.String())
+				test.Equal(t, "00f067aa0ba902b7", sc.SpanID.String())
+				test.Equal(t, tc.sampled, sc.Sampled)
+			}
+		})
+	}
+
+	sc := SpanContext{TraceID: newTraceID(), SpanID: newSpanID(), Sampled: true}
+	parsed, ok := ParseTraceparent(sc.Traceparent())
+	test.True(t, ok)
+	test.Equal(t, sc, parsed)
+}
+
+func TestTracer(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	tracer := NewTracer(NewStdoutExporter(&out), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	remote, _ := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	ctx, server := tracer.Start(ContextWithRemoteParent(t.Context(), remote), "GET", KindServer)
+	childCtx, child := Start(ctx, "encode", Int("articles", 2))
+	Record(childCtx, "GetArticles", KindClient, time.Now().Add(-time.Millisecond), errors.New("no such table"))
+	child.End()
+	child.End() // only the first End counts
+	server.SetName("GET /api/articles")
+	server.SetAttributes(Bool("cached", false))
+	server.End()
+
+	// Without a span in the context, nothing is traced
+	_, orphan := Start(t.Context(), "orphan")
+	test.Zero(t, orphan)
+	orphan.End()
+	Record(t.Context(), "orphan", KindClient, time.Now(), nil)
+
+	test.Nil(t, tracer.Flush(t.Context()))
+	var spans []StdoutSpan
+	dec := json.NewDecoder(&out)
+	for dec.More() {
+		var span StdoutSpan
+		test.Nil(t, dec.Decode(&span))
+		spans = append(spans, span)
+	}
+	test.Equal(t, 3, len(spans))
+
+	query, encode, request := spans[0], spans[1], spans[2]
+	test.Equal(t, "GET /api/articles", request.Name)
+	test.Equal(t, "server", request.Kind)
+	test.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", request.TraceID)
+	test.Equal(t, "00f067aa0ba902b7", request.ParentSpanID)
+	test.DeepEqual(t, map[string]any{"cached": false}, request.Attributes)
+
+	test.Equal(t, "encode", encode.Name)
+	test.Equal(t, "internal", encode.Kind)
+	test.Equal(t, request.TraceID, encode.TraceID)
+	test.Equal(t, request.SpanID, encode.ParentSpanID)
+	test.DeepEqual(t, map[string]any{"articles": float64(2)}, encode.Attributes)
+
+	test.Equal(t, "GetArticles", query.Name)
+	test.Equal(t, "client", query.Kind)
+	test.Equal(t, encode.SpanID, query.ParentSpanID)
+	test.Equal(t, "no such table", query.Error)
+	test.True(t, query.End.Sub(query.Start) >= time.Millisecond)
+
+	// Spans of a trace that the caller did not sample propagate, but are not exported
+	unsampled := remote
+	unsampled.Sampled = false
+	ctx, span := tracer.Start(ContextWithRemoteParent(t.Context(), unsampled), "GET", KindServer)
+	test.Equal(t, remote.TraceID, SpanContextFromContext(ctx).TraceID)
+	header := http.Header{}
+	Inject(ctx, header)
+	test.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-"+span.Context().SpanID.String()+"-00", header.Get("traceparent"))
+	span.End()
+	test.Nil(t, tracer.Flush(t.Context()))
+	test.Equal(t, 0, out.Len())
+}
+
+func TestOTLPExporter(t *testing.T) {
+	t.Parallel()
+
+	var body map[string]any
+	collector := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		test.Equal(t, "/v1/traces", r.URL.Path)
+		test.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		test.Nil(t, json.NewDecoder(r.Body).Decode(&body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(collector.Close)
+
+	start := time.Unix(1700000000, 5)
+	exporter := NewOTLPExporter(collector.URL+"/", "realworld", collector.Client())
+	test.Nil(t, exporter.Export(t.Context(), []SpanData{{
+		Name:         "GET /api/tags",
+		Kind:         KindServer,
+		TraceID:      TraceID{1},
+		SpanID:       SpanID{2},
+		ParentSpanID: SpanID{3},
+		Start:        start,
+		End:          start.Add(time.Second),
+		Attributes:   []Attr{String("http.route", "/api/tags"), Int("http.response.status_code", 500)},
+		Error:        "Internal Server Error",
+	}}))
+
+	got, err := json.Marshal(body)
+	test.Nil(t, err)
+	test.Equal(t, `{"resourceSpans":[{"resource":{"attributes":[{"key":"service.name","value":{"stringValue":"realworld"}}]},`+
+		`"scopeSpans":[{"scope":{"name":"github.com/raeperd/realworld.go"},"spans":[{`+
+		`"attributes":[{"key":"http.route","value":{"stringValue":"/api/tags"}},{"key":"http.response.status_code","value":{"intValue":"500"}}],`+
+		`"endTimeUnixNano":"1700000001000000005","kind":2,"name":"GET /api/tags","parentSpanId":"0300000000000000",`+
+		`"spanId":"0200000000000000","startTimeUnixNano":"1700000000000000005",`+
+		`"status":{"code":2,"message":"Internal Server Error"},"traceId":"01000000000000000000000000000000"}]}]}]}`, string(got))
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(failing.Close)
+	err = NewOTLPExporter(failing.URL, "realworld", failing.Client()).Export(t.Context(), nil)
+	test.NotNil(t, err)
+	test.Contains(t, err.Error(), "503")
+}
+
+func TestLogHandler(t *testing.T) {
+	t.Parallel()
+
+	var out strings.Builder
+	log := slog.New(LogHandler(slog.NewJSONHandler(&out, nil))).With("app", "realworld")
+	tracer := NewTracer(NewStdoutExporter(io.Discard), log)
+
+	ctx, span := tracer.Start(context.Background(), "GET", KindServer)
+	log.InfoContext(ctx, "traced")
+	log.Info("untraced")
+	span.End()
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	test.Equal(t, 2, len(lines))
+	test.Contains(t, lines[0], `"trace_id":"`+span.Context().TraceID.String()+`"`)
+	test.Contains(t, lines[0], `"span_id":"`+span.Context().SpanID.String()+`"`)
+	test.Contains(t, lines[0], `"app":"realworld"`)
+	test.True(t, !strings.Contains(lines[1], "trace_id"))
+}
@@ -0,0 +1,119 @@
+// Package validate provides reusable rules for validating request fields.
+// Each violation is reported as an error whose message starts with the field name,
+// e.g. "email must be a valid email address", so it can be returned to clients as is.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"unicode/utf8"
+)
+
+// Rule checks a single value and returns a message describing the violation,
+// or an empty string if the value is valid.
+type Rule func(value string) string
+
+// Field applies rules to value in order and returns an error for the first violation.
+// It returns nil if every rule passes.
+func Field(name, value string, rules ...Rule) error {
+	for _, rule := range rules {
+		if msg := rule(value); msg != "" {
+			return fmt.Errorf("%s %s", name, msg)
+		}
+	}
+	return nil
+}
+
+// OptionalField is like [Field] but skips validation when value is empty.
+// Use it for partial updates where an empty value means the field was not provided.
+func OptionalField(name, value string, rules ...Rule) error {
+	if value == "" {
+		return nil
+	}
+	return Field(name, value, rules...)
+}
+
+// List checks that values has at most maxItems elements and applies rules to each element.
+// It returns an error for the first violation, or nil if the list is valid.
+func List(name string, values []string, maxItems int, rules ...Rule) error {
+	if len(values) > maxItems {
+		return fmt.Errorf("%s must have at most %d items", name, maxItems)
+	}
+	for i, value := range values {
+		if err := Field(fmt.Sprintf("%s[%d]", name, i), value, rules...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Errors returns the non-nil errors from errs.
+// It returns nil if all errors are nil so that callers can check len(errs) > 0.
+func Errors(errs ...error) []error {
+	var result []error
+	for _, err := range errs {
+		if err != nil {
+			result = append(result, err)
+		}
+	}
+	return result
+}
+
+// Required reports an empty value.
+func Required(value string) string {
+	if value == "" {
+		return "is required"
+	}
+	return ""
+}
+
+// MinLength reports a value shorter than n characters.
+func MinLength(n int) Rule {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) < n {
+			return fmt.Sprintf("must be at least %d characters", n)
+		}
+		return ""
+	}
+}
+
+// MaxLength reports a value longer than n characters.
+func MaxLength(n int) Rule {
+	return func(value string) string {
+		if utf8.RuneCountInString(value) > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		return ""
+	}
+}
+
+// Pattern reports a value that does not match re, using message as the violation.
+func Pattern(re *regexp.Regexp, message string) Rule {
+	return func(value string) string {
+		if !re.MatchString(value) {
+			return message
+		}
+		return ""
+	}
+}
+
+// Email reports a value that is not a bare email address such as "jake@jake.jake".
+// Display names like "Jake <jake@jake.jake>" are rejected.
+func Email(value string) string {
+	addr, err := mail.ParseAddress(value)
+	if err != nil || addr.Address != value {
+		return "must be a valid email address"
+	}
+	return ""
+}
+
+// URL reports a value that is not an absolute http or https URL.
+func URL(value string) string {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "must be a valid http or https URL"
+	}
+	return ""
+}
@@ -0,0 +1,77 @@
+package validate_test
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/internal/validate"
+)
+
+func TestField(t *testing.T) {
+	t.Parallel()
+
+	testcases := map[string]struct {
+		value   string
+		rules   []validate.Rule
+		wantErr string
+	}{
+		"required empty":          {value: "", rules: []validate.Rule{validate.Required}, wantErr: "field is required"},
+		"required present":        {value: "x", rules: []validate.Rule{validate.Required}},
+		"min length short":        {value: "ab", rules: []validate.Rule{validate.MinLength(3)}, wantErr: "field must be at least 3 characters"},
+		"max length long":         {value: "abcd", rules: []validate.Rule{validate.MaxLength(3)}, wantErr: "field must be at most 3 characters"},
+		"max length counts runes": {value: "한국어", rules: []validate.Rule{validate.MaxLength(3)}},
+		"email valid":             {value: "jake@jake.jake", rules: []validate.Rule{validate.Email}},
+		"email invalid":           {value: "jake", rules: []validate.Rule{validate.Email}, wantErr: "field must be a valid email address"},
+		"email display name":      {value: "Jake <jake@jake.jake>", rules: []validate.Rule{validate.Email}, wantErr: "field must be a valid email address"},
+		"url valid":               {value: "https://example.com/a.png", rules: []validate.Rule{validate.URL}},
+		"url relative":            {value: "/a.png", rules: []validate.Rule{validate.URL}, wantErr: "field must be a valid http or https URL"},
+		"url scheme":              {value: "javascript:alert(1)", rules: []validate.Rule{validate.URL}, wantErr: "field must be a valid http or https URL"},
+		"pattern mismatch":        {value: "a b", rules: []validate.Rule{validate.Pattern(regexp.MustCompile(`^\S+$`), "must not contain spaces")}, wantErr: "field must not contain spaces"},
+		"first violation wins":    {value: "", rules: []validate.Rule{validate.Required, validate.MinLength(3)}, wantErr: "field is required"},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			err := validate.Field("field", tc.value, tc.rules...)
+			if tc.wantErr == "" {
+				test.Nil(t, err)
+				return
+			}
+			test.NotNil(t, err)
+			test.Equal(t, tc.wantErr, err.Error())
+		})
+	}
+}
+
+func TestOptionalField_SkipsEmptyValue(t *testing.T) {
+	t.Parallel()
+
+	test.Nil(t, validate.OptionalField("bio", "", validate.Required))
+	test.NotNil(t, validate.OptionalField("image", "not a url", validate.URL))
+}
+
+func TestList(t *testing.T) {
+	t.Parallel()
+
+	test.Nil(t, validate.List("tagList", []string{"go", "sqlite"}, 2, validate.Required))
+
+	err := validate.List("tagList", []string{"a", "b", "c"}, 2)
+	test.NotNil(t, err)
+	test.Equal(t, "tagList must have at most 2 items", err.Error())
+
+	err = validate.List("tagList", []string{"go", strings.Repeat("x", 5)}, 2, validate.MaxLength(4))
+	test.NotNil(t, err)
+	test.Equal(t, "tagList[1] must be at most 4 characters", err.Error())
+}
+
+func TestErrors_DropsNil(t *testing.T) {
+	t.Parallel()
+
+	test.Equal(t, 0, len(validate.Errors(nil, nil)))
+	test.Equal(t, 1, len(validate.Errors(nil, validate.Field("email", "", validate.Required))))
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+// Job kinds. Each takes a [feedJob] payload.
+const (
+	jobFeedFanOut   = "feed.fanout"   // adds an article to the feeds of the followers of its author
+	jobFeedBackfill = "feed.backfill" // adds the articles of an author to the feed of a new follower
+	jobFeedPrune    = "feed.prune"    // removes the articles of an author from the feed of a former follower
+)
+
+// feedJob is the payload of the feed jobs.
+type feedJob struct {
+	ArticleID  int64 `json:"articleId,omitempty"`
+	FollowerID int64 `json:"followerId,omitempty"`
+	AuthorID   int64 `json:"authorId,omitempty"`
+}
+
+// jobPollInterval is how often the server looks for due jobs it was not notified of,
+// such as the retries of failed jobs and the jobs left over from a previous run.
+const jobPollInterval = 5 * time.Second
+
+// jobStallTimeout is how long the runner may go without looking for jobs before [jobRunner.check] fails,
+// such as when a job hangs.
+const jobStallTimeout = time.Minute
+
+// maxJobDelay caps the backoff between the attempts of a failing job.
+const maxJobDelay = time.Hour
+
+// enqueueJob queues a job of kind with payload encoded as JSON.
+// Queued in the transaction of the write that needs it, a job runs if and only if that write is committed.
+func enqueueJob(ctx context.Context, q store.Querier, kind string, payload any) error {
+	b, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	return q.CreateJob(ctx, store.CreateJobParams{Kind: kind, Payload: string(b)})
+}
+
+// jobRunner runs the jobs queued in the jobs table one at a time, oldest first.
+// Jobs survive restarts since they live in the database, and a failing job is retried with exponential backoff
+// until it succeeds: jobs must be idempotent, as one may run again after a crash between running and deleting it.
+type jobRunner struct {
+	db   store.Store
+	log  *slog.Logger
+	poll time.Duration
+	wake chan struct{}
+
+	polled atomic.Int64 // Unix nanoseconds of the last time the runner looked for a due job, 0 until it runs
+}
+
+// newJobRunner returns a runner for the jobs in db that looks for due jobs every poll, or sooner when notified.
+func newJobRunner(db store.Store, log *slog.Logger, poll time.Duration) *jobRunner {
+	return &jobRunner{db: db, log: log, poll: poll, wake: make(chan struct{}, 1)}
+}
+
+// notify wakes the runner up to run a job just committed, instead of leaving it to the next poll.
+// It never blocks, and is a no-op on a nil runner.
+func (r *jobRunner) notify() {
+	if r == nil {
+		return
+	}
+	select {
+	case r.wake <- struct{}{}:
+	default:
+	}
+}
+
+// run runs due jobs until ctx is canceled.
+func (r *jobRunner) run(ctx context.Context) {
+	for {
+		for r.runNext(ctx) {
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.wake:
+		case <-time.After(r.poll):
+		}
+	}
+}
+
+// runNext runs the next due job and reports whether there was one.
+// A failed job is rescheduled and counts as run, so the jobs behind it are not held up.
+func (r *jobRunner) runNext(ctx context.Context) bool {
+	r.polled.Store(time.Now().UnixNano())
+	job, err := r.db.NextJob(ctx)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) && ctx.Err() == nil {
+			r.log.ErrorContext(ctx, "next job", slog.String("error", err.Error()))
+		}
+		return false
+	}
+
+	if err := r.runJob(ctx, job); err != nil {
+		delay := min(time.Second<<min(job.Attempts, 12), maxJobDelay)
+		r.log.WarnContext(ctx, "job failed",
+			slog.Int64("id", job.ID), slog.String("kind", job.Kind), slog.Int64("attempts", job.Attempts+1),
+			slog.Duration("retry_in", delay), slog.String("error", err.Error()))
+		if err := r.db.RetryJob(ctx, store.RetryJobParams{
+			LastError:    sql.NullString{String: err.Error(), Valid: true},
+			DelaySeconds: int64(delay / time.Second),
+			ID:           job.ID,
+		}); err != nil {
+			r.log.ErrorContext(ctx, "retry job", slog.Int64("id", job.ID), slog.String("error", err.Error()))
+			return false
+		}
+		return true
+	}
+
+	if err := r.db.DeleteJob(ctx, job.ID); err != nil {
+		r.log.ErrorContext(ctx, "delete job", slog.Int64("id", job.ID), slog.String("error", err.Error()))
+		return false
+	}
+	return true
+}
+
+// check returns an error unless the runner looked for due jobs within [jobStallTimeout] before now.
+// A nil runner has nothing to check.
+func (r *jobRunner) check(now time.Time) error {
+	if r == nil {
+		return nil
+	}
+	polled := r.polled.Load()
+	if polled == 0 {
+		return errors.New("job runner not started")
+	}
+	if since := now.Sub(time.Unix(0, polled)); since > jobStallTimeout {
+		return fmt.Errorf("job runner stalled: no jobs looked for in %s", since.Round(time.Second))
+	}
+	return nil
+}
+
+func (r *jobRunner) runJob(ctx context.Context, job store.Job) error {
+	var payload feedJob
+	if err := json.Unmarshal([]byte(job.Payload), &payload); err != nil {
+		return fmt.Errorf("decode payload: %w", err)
+	}
+	switch job.Kind {
+	case jobFeedFanOut:
+		return r.db.FanOutArticle(ctx, payload.ArticleID)
+	case jobFeedBackfill:
+		return r.db.BackfillFeed(ctx, store.BackfillFeedParams{FollowerID: payload.FollowerID, AuthorID: payload.AuthorID})
+	case jobFeedPrune:
+		return r.db.PruneFeed(ctx, store.PruneFeedParams{FollowerID: payload.FollowerID, AuthorID: payload.AuthorID})
+	default:
+		return fmt.Errorf("unknown job kind %q", job.Kind)
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+func TestJobRunner_RunNext(t *testing.T) {
+	t.Parallel()
+
+	sqlDB, err := openDB(t.Context(), filepath.Join(t.TempDir(), "jobs.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	db := sqlite.NewStore(sqlDB)
+	runner := newJobRunner(db, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Hour)
+
+	jake, err := db.CreateUser(t.Context(), store.CreateUserParams{Username: "jake", Email: "jake@example.com", Password: "password"})
+	test.Nil(t, err)
+	celeb, err := db.CreateUser(t.Context(), store.CreateUserParams{Username: "celeb", Email: "celeb@example.com", Password: "password"})
+	test.Nil(t, err)
+	test.Nil(t, db.CreateFollow(t.Context(), store.CreateFollowParams{FollowerID: jake.ID, FollowedID: celeb.ID}))
+	article, err := db.CreateArticle(t.Context(), store.CreateArticleParams{Slug: "celeb-go", Title: "Go", Description: "Go", Body: "Go", AuthorID: celeb.ID})
+	test.Nil(t, err)
+
+	// A job that succeeds is deleted
+	test.Nil(t, enqueueJob(t.Context(), db, jobFeedFanOut, feedJob{ArticleID: article.ID}))
+	test.Equal(t, true, runner.runNext(t.Context()))
+	count, err := db.CountFeedItems(t.Context(), jake.ID)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), count)
+	test.Equal(t, false, runner.runNext(t.Context()))
+
+	// A job that fails is kept with its error, to be retried after a backoff
+	test.Nil(t, enqueueJob(t.Context(), db, "feed.unknown", feedJob{}))
+	test.Equal(t, true, runner.runNext(t.Context()))
+	test.Equal(t, false, runner.runNext(t.Context()))
+	var attempts int64 // read directly, since only the runner reads jobs through the store
+	var lastError string
+	test.Nil(t, sqlDB.QueryRowContext(t.Context(), "SELECT attempts, last_error FROM jobs").Scan(&attempts, &lastError))
+	test.Equal(t, int64(1), attempts)
+	test.Equal(t, `unknown job kind "feed.unknown"`, lastError)
+}
+
+func TestJobRunner_Notify(t *testing.T) {
+	t.Parallel()
+
+	var nilRunner *jobRunner
+	nilRunner.notify() // handlers call notify without a runner in tests
+
+	runner := newJobRunner(nil, nil, time.Hour)
+	runner.notify()
+	runner.notify() // never blocks, even when the runner is busy
+	test.Equal(t, 1, len(runner.wake))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// listenFDsStart is the first file descriptor passed by systemd socket activation, after stdin, stdout and stderr.
+const listenFDsStart = 3
+
+// parseListenAddr parses a listen address into the network and address of [net.Listen]: unix:/path/to.sock for
+// a Unix domain socket, and tcp:host:port or just host:port for TCP.
+func parseListenAddr(addr string) (network, address string, err error) {
+	network, address = "tcp", addr
+	if n, a, ok := strings.Cut(addr, ":"); ok && (n == "unix" || n == "tcp") {
+		network, address = n, a
+	}
+	switch network {
+	case "unix":
+		if address == "" {
+			return "", "", fmt.Errorf("%q has no socket path", addr)
+		}
+	case "tcp":
+		if _, _, err := net.SplitHostPort(address); err != nil {
+			return "", "", err
+		}
+	}
+	return network, address, nil
+}
+
+// listen listens on the address of [parseListenAddr]. A Unix socket gets mode as its permissions,
+// after removing the socket file a previous run left behind.
+func listen(addr string, mode fs.FileMode) (net.Listener, error) {
+	network, address, err := parseListenAddr(addr)
+	if err != nil {
+		return nil, err
+	}
+	if network != "unix" {
+		return net.Listen(network, address)
+	}
+
+	if info, err := os.Stat(address); err == nil {
+		if info.Mode().Type() != fs.ModeSocket {
+			return nil, fmt.Errorf("listen %s: file exists and is not a socket", address)
+		}
+		if err := os.Remove(address); err != nil {
+			return nil, err
+		}
+	}
+	listener, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(address, mode); err != nil {
+		_ = listener.Close()
+		return nil, err
+	}
+	return listener, nil
+}
+
+// activatedListeners returns the listeners passed by systemd socket activation, named by LISTEN_FDNAMES,
+// or nil when LISTEN_PID is not the process. Their file descriptors are numbered from start.
+// Listeners survive restarts of the server, so systemd queues the connections that come in meanwhile.
+func activatedListeners(lookupEnv func(string) (string, bool), start int) ([]namedListener, error) {
+	pid, _ := lookupEnv("LISTEN_PID")
+	if pid != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	fds, _ := lookupEnv("LISTEN_FDS")
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("LISTEN_FDS: invalid number of file descriptors %q", fds)
+	}
+	names, _ := lookupEnv("LISTEN_FDNAMES")
+	nameList := strings.Split(names, ":")
+
+	listeners := make([]namedListener, 0, n)
+	for i := range n {
+		fd := start + i
+		name := ""
+		if i < len(nameList) {
+			name = nameList[i]
+		}
+		file := os.NewFile(uintptr(fd), name)
+		listener, err := net.FileListener(file) // duplicates the file descriptor, closed on exec
+		_ = file.Close()
+		if err != nil {
+			for _, l := range listeners {
+				_ = l.Close()
+			}
+			return nil, fmt.Errorf("LISTEN_FDS: file descriptor %d: %w", fd, err)
+		}
+		listeners = append(listeners, namedListener{Listener: listener, name: name})
+	}
+	return listeners, nil
+}
+
+// namedListener is a listener from socket activation with its name in LISTEN_FDNAMES,
+// such as the FileDescriptorName= of its systemd socket unit.
+type namedListener struct {
+	net.Listener
+	name string
+}
+
+// pickListener returns the listener named name: "api", "debug" or "redirect". The API listener falls back to
+// the first listener named otherwise, so a single socket unit needs no name.
+func pickListener(listeners []namedListener, name string) (net.Listener, bool) {
+	for _, l := range listeners {
+		if l.name == name {
+			return l.Listener, true
+		}
+	}
+	for _, l := range listeners {
+		if name == "api" && l.name != "debug" && l.name != "redirect" {
+			return l.Listener, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+	"syscall"
+	"testing"
+
+	"github.com/raeperd/test"
+)
+
+func TestActivatedListeners(t *testing.T) {
+	t.Parallel()
+
+	// Socket activation passes listening sockets as consecutive file descriptors
+	var fds []int
+	var addrs []string
+	for range 2 {
+		listener, err := net.Listen("tcp", "localhost:0")
+		test.Nil(t, err)
+		t.Cleanup(func() { _ = listener.Close() })
+		fds, addrs = append(fds, dupFrom(t, listener, 100)), append(addrs, listener.Addr().String())
+	}
+	if fds[1] != fds[0]+1 {
+		t.Skip("file descriptors are not consecutive")
+	}
+
+	env := map[string]string{"LISTEN_PID": strconv.Itoa(os.Getpid()), "LISTEN_FDS": "2", "LISTEN_FDNAMES": "debug:web"}
+	lookupEnv := func(key string) (string, bool) { v, ok := env[key]; return v, ok }
+	listeners, err := activatedListeners(lookupEnv, fds[0])
+	test.Nil(t, err)
+	test.Equal(t, 2, len(listeners))
+	t.Cleanup(func() {
+		for _, l := range listeners {
+			_ = l.Close()
+		}
+	})
+
+	debug, ok := pickListener(listeners, "debug")
+	test.True(t, ok)
+	test.Equal(t, addrs[0], debug.Addr().String())
+	api, ok := pickListener(listeners, "api")
+	test.True(t, ok)
+	test.Equal(t, addrs[1], api.Addr().String())
+	_, ok = pickListener(listeners, "redirect")
+	test.False(t, ok)
+
+	// Sockets passed to another process are left alone
+	env["LISTEN_PID"] = "1"
+	listeners, err = activatedListeners(lookupEnv, fds[0])
+	test.Nil(t, err)
+	test.Equal(t, 0, len(listeners))
+
+	env["LISTEN_PID"], env["LISTEN_FDS"] = strconv.Itoa(os.Getpid()), "none"
+	_, err = activatedListeners(lookupEnv, fds[0])
+	test.NotNil(t, err)
+}
+
+// dupFrom duplicates the file descriptor of listener to the lowest free one from min, as socket activation passes it.
+func dupFrom(t *testing.T, listener net.Listener, min int) int {
+	t.Helper()
+
+	file, err := listener.(*net.TCPListener).File()
+	test.Nil(t, err)
+	defer file.Close() //nolint:errcheck
+	fd, _, errno := syscall.Syscall(syscall.SYS_FCNTL, file.Fd(), syscall.F_DUPFD_CLOEXEC, uintptr(min))
+	if errno != 0 {
+		t.Fatal(errno)
+	}
+	return int(fd)
+}
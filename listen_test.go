@@ -0,0 +1,85 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/raeperd/test"
+)
+
+func TestParseListenAddr(t *testing.T) {
+	t.Parallel()
+
+	for addr, want := range map[string][2]string{
+		"unix:/run/realworld.sock": {"unix", "/run/realworld.sock"},
+		"tcp:localhost:8080":       {"tcp", "localhost:8080"},
+		"tcp::8080":                {"tcp", ":8080"},
+		"localhost:6060":           {"tcp", "localhost:6060"},
+		":80":                      {"tcp", ":80"},
+	} {
+		network, address, err := parseListenAddr(addr)
+		test.Nil(t, err)
+		test.Equal(t, want, [2]string{network, address})
+	}
+
+	for _, addr := range []string{"unix:", "tcp:localhost", "8080", "udp:localhost:8080"} {
+		_, _, err := parseListenAddr(addr)
+		test.NotNil(t, err)
+	}
+}
+
+func TestListen_Unix(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	socket := filepath.Join(dir, "api.sock")
+	listener, err := listen("unix:"+socket, 0o600)
+	test.Nil(t, err)
+	info, err := os.Stat(socket)
+	test.Nil(t, err)
+	test.Equal(t, fs.ModeSocket, info.Mode().Type())
+	test.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+
+	// A socket left behind by a server that didn't close it is replaced
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	test.Nil(t, listener.Close())
+	listener, err = listen("unix:"+socket, 0o660)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = listener.Close() })
+
+	// Other files are not
+	file := filepath.Join(dir, "file")
+	test.Nil(t, os.WriteFile(file, nil, 0o600))
+	_, err = listen("unix:"+file, 0o660)
+	test.NotNil(t, err)
+}
+
+func TestRun_Listen(t *testing.T) {
+	t.Parallel()
+
+	socket := filepath.Join(t.TempDir(), "api.sock")
+	unixClient := &http.Client{Transport: &http.Transport{
+		Dial: func(string, string) (net.Conn, error) { return net.Dial("unix", socket) },
+	}}
+	startServer(t, unixClient, "http://realworld/health", "-listen", "unix:"+socket, "-socket-mode", "0600")
+
+	info, err := os.Stat(socket)
+	test.Nil(t, err)
+	test.Equal(t, fs.FileMode(0o600), info.Mode().Perm())
+
+	for name, args := range map[string][]string{
+		"listen":      {"-listen", "unix:"},
+		"socket mode": {"-socket-mode", "rw-rw----"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := run(t.Context(), io.Discard, append([]string{"test", "-jwt-secret", "test-secret"}, args...), "vtest")
+			test.NotNil(t, err)
+			test.Contains(t, err.Error(), args[0])
+		})
+	}
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+// Failed logins to an account delay its next attempts, so passwords can't be guessed by the thousand even from many IPs.
+// Failures count from the last successful login, within loginFailureWindow.
+const (
+	freeLoginFailures    = 3                // failures before the next attempt is delayed
+	lockoutLoginFailures = 10               // failures that lock the account for loginLockout
+	loginLockout         = 15 * time.Minute // delay of a locked account
+	loginFailureWindow   = time.Hour        // failures older than this are forgotten
+	maxLoginEvents       = 50               // logins listed by GET /api/user/security/logins
+)
+
+// Logins are kept for users to review for loginAuditPeriod after they stop counting as failures.
+// Logins to emails of no user are never listed, so they are kept only while they count, and only the latest
+// maxUnknownLoginEvents of them, so that guessing emails can't grow the table without bound.
+const (
+	loginAuditPeriod      = 90 * 24 * time.Hour
+	maxUnknownLoginEvents = 10000
+)
+
+// loginDelay returns how long after the last of failures in a row the next login is allowed: not at all
+// until freeLoginFailures, then doubling from a second, up to the loginLockout of lockoutLoginFailures.
+func loginDelay(failures int) time.Duration {
+	if failures < freeLoginFailures {
+		return 0
+	}
+	if failures >= lockoutLoginFailures {
+		return loginLockout
+	}
+	return time.Second << (failures - freeLoginFailures)
+}
+
+// loginRetryAfter returns how long from now the next login is delayed by failures, latest first as listed by
+// ListLoginFailures, or 0 if it is allowed.
+func loginRetryAfter(failures []time.Time, now time.Time) time.Duration {
+	if len(failures) == 0 {
+		return 0
+	}
+	return max(failures[0].Add(loginDelay(len(failures))).Sub(now), 0)
+}
+
+func handleGetUserSecurityLogins(db store.Store) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		userID, ok := r.Context().Value(userIDKey).(int64)
+		if !ok {
+			encodeErrorResponse(r.Context(), http.StatusUnauthorized, []error{errors.New("unauthorized")}, w)
+			return
+		}
+
+		events, err := db.ListLoginEvents(r.Context(), store.ListLoginEventsParams{
+			UserID: sql.NullInt64{Int64: userID, Valid: true},
+			Limit:  maxLoginEvents,
+		})
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
+		logins := make([]loginResponseBody, len(events))
+		for i, event := range events {
+			logins[i] = loginResponseBody{
+				IP:        event.Ip,
+				UserAgent: event.UserAgent,
+				Success:   event.Success,
+				CreatedAt: event.CreatedAt,
+			}
+		}
+		encodeResponse(r.Context(), http.StatusOK, loginsResponseBody{Logins: logins}, w)
+	}
+}
+
+// pruneLoginEvents deletes the logins that are kept no longer every interval until ctx is done.
+func pruneLoginEvents(ctx context.Context, db store.Store, interval time.Duration, log *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := db.DeleteOldLoginEvents(ctx, store.DeleteOldLoginEventsParams{
+				RetentionSeconds:        int64((loginFailureWindow + loginAuditPeriod).Seconds()),
+				UnknownRetentionSeconds: int64(loginFailureWindow.Seconds()),
+			})
+			if err != nil {
+				log.ErrorContext(ctx, "failed to prune login events", slog.String("error", err.Error()))
+			} else if n > 0 {
+				log.InfoContext(ctx, "pruned login events", slog.Int64("count", n))
+			}
+		}
+	}
+}
+
+type loginsResponseBody struct {
+	Logins []loginResponseBody `json:"logins"`
+}
+
+type loginResponseBody struct {
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Success   bool      `json:"success"`
+	CreatedAt time.Time `json:"createdAt"`
+}
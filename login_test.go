@@ -0,0 +1,142 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+func TestLoginDelay(t *testing.T) {
+	t.Parallel()
+
+	for failures, want := range map[int]time.Duration{
+		0:  0,
+		2:  0,
+		3:  time.Second,
+		4:  2 * time.Second,
+		9:  64 * time.Second,
+		10: loginLockout,
+		50: loginLockout,
+	} {
+		test.Equal(t, want, loginDelay(failures))
+	}
+
+	now := time.Now()
+	test.Equal(t, time.Duration(0), loginRetryAfter(nil, now))
+	test.Equal(t, time.Duration(0), loginRetryAfter([]time.Time{now, now, now}, now.Add(time.Second)))
+	test.Equal(t, 500*time.Millisecond, loginRetryAfter([]time.Time{now, now, now}, now.Add(500*time.Millisecond)))
+}
+
+func TestPostUsersLogin_Throttled(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "login.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	server := httptest.NewServer(route(slog.New(slog.NewTextHandler(io.Discard, nil)), "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret"}))
+	t.Cleanup(server.Close)
+	_, err = client.New(server.URL).Register(t.Context(), client.NewUser{Username: "locked", Email: "locked@example.com", Password: "testpass123"})
+	test.Nil(t, err)
+
+	login := func(email, password string) *http.Response {
+		body := fmt.Sprintf(`{"user":{"email":%q,"password":%q}}`, email, password)
+		res, err := server.Client().Post(server.URL+"/api/users/login", "application/json", strings.NewReader(body))
+		test.Nil(t, err)
+		_ = res.Body.Close()
+		return res
+	}
+	fail := func(email string, n int) {
+		for range n {
+			test.Nil(t, db.CreateLoginEvent(t.Context(), store.CreateLoginEventParams{Email: email, Ip: "192.0.2.1", UserAgent: "guesser"}))
+		}
+	}
+
+	// Failures delay the next attempt, even with the right password
+	fail("locked@example.com", 5)
+	res := login("locked@example.com", "testpass123")
+	test.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	retry, err := strconv.Atoi(res.Header.Get("Retry-After"))
+	test.Nil(t, err)
+	test.True(t, retry > 0 && retry <= 4)
+
+	// Until the account is locked
+	fail("locked@example.com", 5)
+	res = login("locked@example.com", "testpass123")
+	test.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	retry, err = strconv.Atoi(res.Header.Get("Retry-After"))
+	test.Nil(t, err)
+	test.True(t, retry > 14*60 && retry <= 15*60)
+
+	// Emails of no user are throttled the same, so they can't be told apart
+	fail("nobody@example.com", 10)
+	test.Equal(t, http.StatusTooManyRequests, login("nobody@example.com", "testpass123").StatusCode)
+	test.Equal(t, http.StatusUnauthorized, login("other@example.com", "testpass123").StatusCode)
+}
+
+func TestPostUsersLogin_UnknownEmailsCapped(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "login.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	server := httptest.NewServer(route(slog.New(slog.NewTextHandler(io.Discard, nil)), "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret"}))
+	t.Cleanup(server.Close)
+
+	tx, err := db.BeginTx(t.Context())
+	test.Nil(t, err)
+	for i := range maxUnknownLoginEvents {
+		email := fmt.Sprintf("guess%d@example.com", i)
+		test.Nil(t, tx.CreateLoginEvent(t.Context(), store.CreateLoginEventParams{Email: email, Ip: "192.0.2.1", UserAgent: "guesser"}))
+	}
+	test.Nil(t, tx.Commit())
+
+	// Failures past the cap are recorded in place of the oldest, so the email is throttled as an account would be
+	c := client.New(server.URL)
+	for range freeLoginFailures {
+		_, err = c.Login(t.Context(), "nobody@example.com", "testpass123")
+		test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+	}
+	_, err = c.Login(t.Context(), "nobody@example.com", "testpass123")
+	test.Equal(t, http.StatusTooManyRequests, client.StatusCode(err))
+
+	n, err := db.CountUnknownLoginEvents(t.Context())
+	test.Nil(t, err)
+	test.Equal(t, int64(maxUnknownLoginEvents), n)
+	oldest, err := db.ListLoginFailures(t.Context(), store.ListLoginFailuresParams{Email: "guess0@example.com", WindowSeconds: 3600, Limit: 10})
+	test.Nil(t, err)
+	test.Equal(t, 0, len(oldest))
+}
+
+func TestGetUserSecurityLogins(t *testing.T) {
+	t.Parallel()
+
+	c, user := registerUser(t, "logins")
+	_, err := newClient().Login(t.Context(), user.Email, "wrongpass123")
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+	_, err = c.Login(t.Context(), user.Email, "testpass123")
+	test.Nil(t, err)
+
+	logins, err := c.GetLogins(t.Context())
+	test.Nil(t, err)
+	test.Equal(t, 2, len(logins))
+	test.True(t, logins[0].Success)
+	test.False(t, logins[1].Success)
+	test.NotZero(t, logins[0].IP)
+	test.Equal(t, "Go-http-client/1.1", logins[0].UserAgent)
+	test.True(t, time.Since(logins[0].CreatedAt) < time.Minute)
+
+	_, err = newClient().GetLogins(t.Context())
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
+}
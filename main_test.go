@@ -3,8 +3,10 @@ package main
 import (
 	"bytes"
 	"context"
+	"database/sql"
 	"encoding/json"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"log/slog"
@@ -12,12 +14,17 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/client"
+	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
 )
 
 // TestMain starts the server and runs all the tests.
@@ -25,33 +32,37 @@ import (
 func TestMain(m *testing.M) {
 	flag.Parse() // NOTE: this is needed to parse args from go test command
 
-	// Create a temporary file for the test database
-	tmpFile, err := os.CreateTemp("", "test-*.db")
+	// Create a temporary directory for the test database and the socket the server listens on,
+	// so that no port has to be picked
+	dir, err := os.MkdirTemp("", "realworld-test-*")
 	if err != nil {
-		log.Fatalf("failed to create temp file: %v", err)
+		log.Fatalf("failed to create temp dir: %v", err)
 	}
-	dbPath := tmpFile.Name()
-	tmpFile.Close() //nolint:errcheck
-
-	port := func() string { // Get a free port to run the server
-		listener, err := net.Listen("tcp", ":0")
-		if err != nil {
-			log.Fatalf("failed to listen: %v", err)
+	dbPath, socket := filepath.Join(dir, "test.db"), filepath.Join(dir, "api.sock")
+
+	// Requests to the endpoint host go to the socket, and others, such as to servers started by tests, as usual
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		if addr == endpointHost+":80" {
+			network, addr = "unix", socket
 		}
-		defer listener.Close() //nolint:errcheck
-		addr := listener.Addr().(*net.TCPAddr)
-		return strconv.Itoa(addr.Port)
-	}()
+		return (&net.Dialer{}).DialContext(ctx, network, addr)
+	}
+
+	// Record every exchange with the server to validate them against api/openapi.yaml after tests
+	recorder := &exchangeRecorder{next: transport}
+	http.DefaultTransport = recorder
 
 	ctx, cancel := context.WithCancel(context.Background())
 	go func() { // Start the server in a goroutine
-		if err := run(ctx, os.Stdout, []string{"test", "--port", port, "--jwt-secret", "test-secret", "--db", dbPath}, "vtest"); err != nil {
+		if err := run(ctx, os.Stdout, []string{"test", "--listen", "unix:" + socket, "--jwt-secret", "test-secret", "--db", dbPath, "--rate-limit", "none", "--dev"}, "vtest"); err != nil {
 			cancel()
 			log.Fatal(err)
 		}
 	}()
 
-	endpoint = "http://localhost:" + port
+	endpoint = "http://" + endpointHost
 
 	start := time.Now() // wait for server to be healthy before tests.
 	for time.Since(start) < 3*time.Second {
@@ -63,11 +74,26 @@ func TestMain(m *testing.M) {
 	}
 
 	exitCode := m.Run()
+	if exitCode == 0 {
+		spec, err := parseOpenAPISpec(openAPI)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if errs := recorder.validate(spec); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintf(os.Stderr, "openapi conformance: %v\n", err)
+			}
+			exitCode = 1
+		}
+	}
 	cancel()
-	os.Remove(dbPath) //nolint:errcheck
+	os.RemoveAll(dir) //nolint:errcheck
 	os.Exit(exitCode)
 }
 
+// endpointHost is the host of endpoint, which the default transport dials on the socket of the TestMain server.
+const endpointHost = "realworld.test"
+
 // endpoint holds the server endpoint started by TestMain, not intended to be updated.
 var endpoint string
 
@@ -238,12 +264,15 @@ func TestRecoveryMiddleware(t *testing.T) {
 func TestCORSPreflightRequest(t *testing.T) {
 	t.Parallel()
 
-	res := httpOptions(t, "/api/users")
+	res := httpOptions(t, "/api/users", "Origin", "https://app.example.com",
+		"Access-Control-Request-Method", "POST", "Access-Control-Request-Headers", "Content-Type, Authorization")
 	t.Cleanup(func() { _ = res.Body.Close() })
 
-	test.Equal(t, http.StatusOK, res.StatusCode)
+	test.Equal(t, http.StatusNoContent, res.StatusCode)
 	test.Equal(t, "*", res.Header.Get("Access-Control-Allow-Origin"))
-	// TODO: Add Access-Control-Allow-Methods, Access-Control-Allow-Headers, Access-Control-Max-Age
+	test.Equal(t, "POST", res.Header.Get("Access-Control-Allow-Methods"))
+	test.Equal(t, "content-type, authorization", res.Header.Get("Access-Control-Allow-Headers"))
+	test.Equal(t, "600", res.Header.Get("Access-Control-Max-Age"))
 }
 
 // TestCORSActualRequests tests CORS headers are present on actual requests
@@ -269,14 +298,258 @@ func TestCORSActualRequests(t *testing.T) {
 	})
 }
 
-func httpOptions(t *testing.T, path string) *http.Response {
+func httpOptions(t *testing.T, path string, header ...string) *http.Response {
 	t.Helper()
 
 	req, err := http.NewRequest(http.MethodOptions, endpoint+path, nil)
 	test.Nil(t, err)
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
 
 	res, err := http.DefaultClient.Do(req)
 	test.Nil(t, err)
 
 	return res
 }
+
+// newClient returns an API client for the test server.
+func newClient(opts ...client.Option) *client.Client {
+	return client.New(endpoint, opts...)
+}
+
+// startServer runs a server of its own with args until the test ends, for tests of flags the [TestMain] server lacks.
+// It returns once url, such as the /health of the server, answers through httpClient.
+func startServer(t *testing.T, httpClient *http.Client, url string, args ...string) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	done := make(chan error, 1)
+	go func() {
+		done <- run(ctx, io.Discard, append([]string{"test", "-jwt-secret", "test-secret", "-rate-limit", "none", "-drain-delay", "0"}, args...), "vtest")
+	}()
+	t.Cleanup(func() {
+		cancel()
+		test.Nil(t, <-done)
+	})
+
+	for start := time.Now(); time.Since(start) < 3*time.Second; time.Sleep(50 * time.Millisecond) {
+		select {
+		case err := <-done:
+			done <- err // for the cleanup
+			t.Fatalf("server stopped: %v", err)
+		default:
+		}
+		if res, err := httpClient.Get(url); err == nil {
+			_ = res.Body.Close()
+			return
+		}
+	}
+	t.Fatalf("server did not answer %s", url)
+}
+
+// TestOpenReadDB tests the read pool sees committed writes and refuses to write itself.
+func TestOpenReadDB(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "read.db")
+	db, err := openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	readDB, err := openReadDB(t.Context(), db, dbPath)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = readDB.Close() })
+
+	var mode string
+	test.Nil(t, db.QueryRowContext(t.Context(), "PRAGMA journal_mode").Scan(&mode))
+	test.Equal(t, "wal", mode)
+
+	_, err = db.ExecContext(t.Context(), "INSERT INTO tags (name) VALUES ('go')")
+	test.Nil(t, err)
+	var count int
+	test.Nil(t, readDB.QueryRowContext(t.Context(), "SELECT COUNT(*) FROM tags").Scan(&count))
+	test.Equal(t, 1, count)
+
+	_, err = readDB.ExecContext(t.Context(), "INSERT INTO tags (name) VALUES ('sqlite')")
+	test.NotNil(t, err)
+
+	// An in-memory database cannot be shared between pools
+	memDB, err := openDB(t.Context(), "")
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = memDB.Close() })
+	memReadDB, err := openReadDB(t.Context(), memDB, "")
+	test.Nil(t, err)
+	test.Equal(t, memDB, memReadDB)
+}
+
+func TestOpenDB_AddsCounterColumns(t *testing.T) {
+	t.Parallel()
+
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+	db, err := openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	for _, stmt := range []string{
+		"INSERT INTO users (username, email, password) VALUES ('jake', 'jake@example.com', 'x'), ('celeb', 'celeb@example.com', 'x')",
+		"INSERT INTO follows (follower_id, followed_id) VALUES (1, 2)",
+		"INSERT INTO articles (slug, title, description, body, author_id) VALUES ('celeb-go', 'Go', 'Go', 'Go', 2)",
+		"INSERT INTO favorites (user_id, article_id) VALUES (1, 1), (2, 1)",
+		"INSERT INTO comments (body, article_id, author_id) VALUES ('Nice', 1, 1)",
+		// Back to the schema before the counters
+		"DROP TRIGGER follows_count_insert", "DROP TRIGGER follows_count_delete",
+		"DROP TRIGGER favorites_count_insert", "DROP TRIGGER favorites_count_delete",
+		"DROP TRIGGER comments_count_insert", "DROP TRIGGER comments_count_delete",
+		"ALTER TABLE users DROP COLUMN followers_count",
+		"ALTER TABLE articles DROP COLUMN favorites_count",
+		"ALTER TABLE articles DROP COLUMN comments_count",
+	} {
+		_, err := db.ExecContext(t.Context(), stmt)
+		test.Nil(t, err)
+	}
+	test.Nil(t, db.Close())
+
+	db, err = openDB(t.Context(), dbPath)
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	queries := sqlite.New(db)
+	article, err := queries.GetArticleBySlug(t.Context(), "celeb-go")
+	test.Nil(t, err)
+	test.Equal(t, int64(2), article.FavoritesCount)
+	test.Equal(t, int64(1), article.CommentsCount)
+	user, err := queries.GetUserByUsername(t.Context(), "celeb")
+	test.Nil(t, err)
+	test.Equal(t, int64(1), user.FollowersCount)
+
+	// The triggers are back too
+	test.Nil(t, queries.DeleteFavorite(t.Context(), sqlite.DeleteFavoriteParams{UserID: 1, ArticleID: 1}))
+	favorites, err := queries.GetFavoritesCount(t.Context(), 1)
+	test.Nil(t, err)
+	test.Equal(t, int64(1), favorites)
+}
+
+func TestOpenDB_MigratesFirstSchema(t *testing.T) {
+	t.Parallel()
+
+	schema, err := os.ReadFile(filepath.Join("testdata", "schema-v0.sql"))
+	test.Nil(t, err)
+	dbPath := filepath.Join(t.TempDir(), "v0.db")
+	db, err := sql.Open("sqlite", dbPath)
+	test.Nil(t, err)
+	_, err = db.ExecContext(t.Context(), string(schema))
+	test.Nil(t, err)
+	_, err = db.ExecContext(t.Context(), "INSERT INTO users (username, email, password) VALUES ('jake', 'jake@example.com', 'jakejake')")
+	test.Nil(t, err)
+	test.Nil(t, db.Close())
+
+	// Admin commands and the API query every column of users
+	out, err := admin(t, dbPath, "db", "check")
+	test.Nil(t, err)
+	test.Equal(t, "ok\n", out)
+	_, err = admin(t, dbPath, "user", "reset-password", "-password", "resetpass", "jake")
+	test.Nil(t, err)
+
+	c := serveDB(t, dbPath)
+	_, err = c.Login(t.Context(), "jake@example.com", "resetpass")
+	test.Nil(t, err)
+	profile, err := c.GetProfile(t.Context(), "jake")
+	test.Nil(t, err)
+	test.Equal(t, "jake", profile.Username)
+}
+
+// BenchmarkConcurrentReads measures GET /api/articles throughput while another client keeps writing.
+// "single pool" serves reads from the writer connection as before, "read pool" from [openReadDB].
+//
+//	go test -run '^$' -bench ConcurrentReads -cpu 8
+func BenchmarkConcurrentReads(b *testing.B) {
+	for _, name := range []string{"single pool", "read pool"} {
+		b.Run(name, func(b *testing.B) {
+			dbPath := filepath.Join(b.TempDir(), "bench.db")
+			db, err := openDB(b.Context(), dbPath)
+			test.Nil(b, err)
+			b.Cleanup(func() { _ = db.Close() })
+			readDB := db
+			if name == "read pool" {
+				readDB, err = openReadDB(b.Context(), db, dbPath)
+				test.Nil(b, err)
+				b.Cleanup(func() { _ = readDB.Close() })
+			}
+
+			server := httptest.NewServer(route(slog.New(slog.NewTextHandler(io.Discard, nil)), "vbench", routeDeps{db: sqlite.NewStore(db), readDB: sqlite.NewStore(readDB), jwtSecret: "test-secret"}))
+			b.Cleanup(server.Close)
+			writer := client.New(server.URL, client.WithHTTPClient(server.Client()))
+			_, err = writer.Register(b.Context(), client.NewUser{Username: "bench", Email: "bench@example.com", Password: "benchpass"})
+			test.Nil(b, err)
+			for i := range 20 {
+				_, err := writer.CreateArticle(b.Context(), client.NewArticle{Title: fmt.Sprintf("Bench %d", i), Description: "d", Body: "b", TagList: []string{"bench"}})
+				test.Nil(b, err)
+			}
+
+			// Keep the writer connection busy for the whole run
+			ctx, stop := context.WithCancel(b.Context())
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				for ctx.Err() == nil {
+					_, _ = writer.Favorite(ctx, "bench-0")
+					_, _ = writer.Unfavorite(ctx, "bench-0")
+				}
+			}()
+			b.Cleanup(func() { stop(); <-done })
+
+			reader := client.New(server.URL, client.WithHTTPClient(server.Client()))
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := reader.GetArticles(ctx, client.ArticlesOptions{Tag: "bench"}); err != nil {
+						b.Error(err)
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkFeed compares the two ways to read a home feed of 20 articles, for a user following 100 of 1000 authors
+// with 20 articles each: "pull" joins follows and articles at read time with ListArticlesFeed,
+// "push" reads the feed_items fanned out ahead of time with ListFeedItems, as GET /api/articles/feed does.
+//
+//	go test -run '^$' -bench Feed
+func BenchmarkFeed(b *testing.B) {
+	db, err := openDB(b.Context(), filepath.Join(b.TempDir(), "bench.db"))
+	test.Nil(b, err)
+	b.Cleanup(func() { _ = db.Close() })
+	for _, stmt := range []string{
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 1000)
+		INSERT INTO users (username, email, password) SELECT 'user' || i, 'user' || i || '@example.com', 'x' FROM n`,
+		`WITH RECURSIVE n(i) AS (SELECT 1 UNION ALL SELECT i + 1 FROM n WHERE i < 20000)
+		INSERT INTO articles (slug, title, description, body, author_id, created_at)
+		SELECT 'article-' || i, 'Article', 'd', 'b', i % 1000 + 1, DATETIME('2025-01-01', '+' || i || ' minutes') FROM n`,
+		// user1 follows every tenth author, and every author has a handful of followers
+		`INSERT INTO follows (follower_id, followed_id) SELECT 1, id FROM users WHERE id % 10 = 0`,
+		`INSERT INTO follows (follower_id, followed_id) SELECT u.id, f.id FROM users u JOIN users f ON f.id = (u.id * 7) % 1000 + 1
+		WHERE u.id != 1 AND u.id != f.id`,
+		`INSERT INTO feed_items (user_id, article_id, author_id, created_at)
+		SELECT f.follower_id, a.id, a.author_id, a.created_at FROM follows f JOIN articles a ON a.author_id = f.followed_id`,
+		`ANALYZE`,
+	} {
+		_, err := db.ExecContext(b.Context(), stmt)
+		test.Nil(b, err)
+	}
+	st := sqlite.NewStore(db)
+
+	b.Run("pull", func(b *testing.B) {
+		for b.Loop() {
+			rows, err := st.ListArticlesFeed(b.Context(), store.ListArticlesFeedParams{FollowerID: 1, Limit: 20})
+			if err != nil || len(rows) != 20 {
+				b.Fatal(len(rows), err)
+			}
+		}
+	})
+	b.Run("push", func(b *testing.B) {
+		for b.Loop() {
+			rows, err := st.ListFeedItems(b.Context(), store.ListFeedItemsParams{UserID: 1, Limit: 20})
+			if err != nil || len(rows) != 20 {
+				b.Fatal(len(rows), err)
+			}
+		}
+	})
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/metrics"
+)
+
+// registry holds the metrics of the server process, served at /metrics in the Prometheus text format.
+// Like expvar, it is shared by every server in the process.
+var registry = metrics.NewRegistry()
+
+var (
+	httpRequests = registry.Counter("http_requests_total",
+		"HTTP requests by route pattern and status.", "route", "status")
+	httpRequestDuration = registry.Histogram("http_request_duration_seconds",
+		"Time to serve HTTP requests by route pattern and status.", metrics.DefaultBuckets, "route", "status")
+	dbQueryDuration = registry.Histogram("db_query_duration_seconds",
+		"Time to run database queries by their name in query.sql.", dbQueryBuckets, "query")
+	dbQueryErrors = registry.Counter("db_query_errors_total",
+		"Database queries that failed, by their name in query.sql.", "query")
+
+	usersRegistered = registry.Counter("realworld_users_registered_total", "Users registered through the API.")
+	loginsFailed    = registry.Counter("realworld_logins_failed_total", "Logins refused for wrong credentials or a disabled user.")
+	loginsThrottled = registry.Counter("realworld_logins_throttled_total", "Logins refused without checking credentials after too many failures.")
+	articlesCreated = registry.Counter("realworld_articles_created_total", "Articles created through the API.")
+	commentsCreated = registry.Counter("realworld_comments_created_total", "Comments created through the API.")
+)
+
+// dbQueryBuckets are finer than [metrics.DefaultBuckets], since most queries take well under a millisecond.
+var dbQueryBuckets = []float64{.0001, .00025, .0005, .001, .0025, .005, .01, .025, .05, .1, .25, 1}
+
+// instrument is a middleware that counts and times requests by the pattern of their route in [route],
+// see [routePattern], and by status. Requests that match no route count as "unmatched".
+func instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		wr := responseRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(&wr, r)
+
+		pattern := routePattern(r)
+		if pattern == "" {
+			pattern = "unmatched"
+		}
+		status := wr.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		httpRequests.Inc(pattern, strconv.Itoa(status))
+		httpRequestDuration.Observe(time.Since(start).Seconds(), pattern, strconv.Itoa(status))
+	})
+}
+
+// observeQuery is the [store.QueryHook] of the stores of [openStore], which times each query.
+func observeQuery(_ context.Context, name string, start time.Time, err error) {
+	dbQueryDuration.Observe(time.Since(start).Seconds(), name)
+	if err != nil {
+		dbQueryErrors.Inc(name)
+	}
+}
+
+// statser is a store that reports the stats of its connection pool, as both backends do.
+type statser interface {
+	Stats() sql.DBStats
+}
+
+// pools are the connection pools reported by the db_pool metrics, by name.
+var pools sync.Map // string -> statser
+
+// observePool reports the connection pool of s, if it has one, as pool in the db_pool metrics.
+func observePool(pool string, s any) {
+	if s, ok := s.(statser); ok {
+		pools.Store(pool, s)
+	}
+}
+
+func init() {
+	poolStat := func(stat func(sql.DBStats) float64) func(sample func(float64, ...string)) {
+		return func(sample func(float64, ...string)) {
+			var names []string
+			pools.Range(func(pool, _ any) bool {
+				names = append(names, pool.(string))
+				return true
+			})
+			slices.Sort(names)
+			for _, name := range names {
+				if s, ok := pools.Load(name); ok {
+					sample(stat(s.(statser).Stats()), name)
+				}
+			}
+		}
+	}
+	labels := []string{"pool"}
+	registry.GaugeFunc("db_pool_max_open_connections", "Maximum number of open connections of the pool.", labels,
+		poolStat(func(s sql.DBStats) float64 { return float64(s.MaxOpenConnections) }))
+	registry.GaugeFunc("db_pool_open_connections", "Open connections of the pool, in use or idle.", labels,
+		poolStat(func(s sql.DBStats) float64 { return float64(s.OpenConnections) }))
+	registry.GaugeFunc("db_pool_in_use_connections", "Connections of the pool in use.", labels,
+		poolStat(func(s sql.DBStats) float64 { return float64(s.InUse) }))
+	registry.GaugeFunc("db_pool_idle_connections", "Idle connections of the pool.", labels,
+		poolStat(func(s sql.DBStats) float64 { return float64(s.Idle) }))
+	registry.CounterFunc("db_pool_wait_total", "Times a query waited for a connection of the pool.", labels,
+		poolStat(func(s sql.DBStats) float64 { return float64(s.WaitCount) }))
+	registry.CounterFunc("db_pool_wait_seconds_total", "Time queries waited for a connection of the pool.", labels,
+		poolStat(func(s sql.DBStats) float64 { return s.WaitDuration.Seconds() }))
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/raeperd/test"
+)
+
+func TestGetMetrics(t *testing.T) {
+	t.Parallel()
+
+	_, user := registerUser(t, "metrics")
+	_, err := newClient().Login(t.Context(), user.Email, "wrongpass123")
+	test.NotNil(t, err)
+	_, err = newClient().GetTags(t.Context())
+	test.Nil(t, err)
+
+	res, err := http.Get(endpoint + "/metrics")
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = res.Body.Close() })
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	test.Equal(t, "text/plain; version=0.0.4; charset=utf-8", res.Header.Get("Content-Type"))
+	body, err := io.ReadAll(res.Body)
+	test.Nil(t, err)
+
+	for _, want := range []string{
+		`http_requests_total{route="GET /api/tags",status="200"} `,
+		`http_requests_total{route="POST /api/users/login",status="401"} `,
+		`http_request_duration_seconds_bucket{route="POST /api/users",status="201",le="+Inf"} `,
+		`db_query_duration_seconds_count{query="GetAllTags"} `,
+		`db_pool_open_connections{pool="writer"} `,
+		`db_pool_open_connections{pool="reader"} `,
+		"realworld_users_registered_total ",
+		"realworld_logins_failed_total ",
+	} {
+		test.Contains(t, string(body), want)
+	}
+}
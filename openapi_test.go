@@ -0,0 +1,526 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/raeperd/test"
+	"gopkg.in/yaml.v3"
+)
+
+// apiBasePath is the server URL path of api/openapi.yaml. Documented paths are relative to it.
+const apiBasePath = "/api"
+
+// undocumentedRoutes are operational routes registered in [route] that live outside of [apiBasePath]
+// and are intentionally not part of api/openapi.yaml.
+var undocumentedRoutes = []string{
+	"GET /health",
+	"GET /livez",
+	"GET /readyz",
+	"GET /openapi.yaml",
+	"/debug/",
+	"GET /metrics",
+	"POST /admin/backups",
+}
+
+// TestOpenAPI_RoutesDocumented checks that every route registered in [route] is documented in api/openapi.yaml
+// and that every documented operation is registered in [route].
+func TestOpenAPI_RoutesDocumented(t *testing.T) {
+	t.Parallel()
+
+	spec := loadOpenAPISpec(t)
+	patterns := routePatterns(t)
+	test.True(t, len(patterns) > 0)
+
+	registered := make(map[string]bool)
+	for _, pattern := range patterns {
+		if slices.Contains(undocumentedRoutes, pattern) {
+			continue
+		}
+		method, path, ok := strings.Cut(pattern, " ")
+		if !ok || !strings.HasPrefix(path, apiBasePath+"/") {
+			t.Errorf("route %q must have a method and start with %s, or be listed in undocumentedRoutes", pattern, apiBasePath)
+			continue
+		}
+		path = strings.TrimPrefix(path, apiBasePath)
+		registered[method+" "+path] = true
+		if spec.operation(method, path) == nil {
+			t.Errorf("route %q is not documented in api/openapi.yaml", pattern)
+		}
+	}
+
+	for path, item := range spec.paths() {
+		for method := range item {
+			if !slices.Contains(openAPIMethods, method) {
+				continue
+			}
+			if op := strings.ToUpper(method) + " " + path; !registered[op] {
+				t.Errorf("operation %q is documented in api/openapi.yaml but not registered in route()", op)
+			}
+		}
+	}
+}
+
+// TestOpenAPI_ValidateExchange checks the validator itself so that a broken validator can't hide drift.
+func TestOpenAPI_ValidateExchange(t *testing.T) {
+	t.Parallel()
+
+	spec := loadOpenAPISpec(t)
+	testcases := map[string]struct {
+		exchange exchange
+		wantErr  string
+	}{
+		"valid tags response": {
+			exchange: exchange{Method: http.MethodGet, Path: "/api/tags", Status: http.StatusOK, ContentType: "application/json", ResponseBody: []byte(`{"tags":["go"]}`)},
+		},
+		"missing required property": {
+			exchange: exchange{Method: http.MethodGet, Path: "/api/tags", Status: http.StatusOK, ContentType: "application/json", ResponseBody: []byte(`{}`)},
+			wantErr:  `response.tags is required`,
+		},
+		"undocumented property": {
+			exchange: exchange{Method: http.MethodGet, Path: "/api/tags", Status: http.StatusOK, ContentType: "application/json", ResponseBody: []byte(`{"tags":[],"extra":1}`)},
+			wantErr:  `response.extra is not documented`,
+		},
+		"wrong type": {
+			exchange: exchange{Method: http.MethodGet, Path: "/api/tags", Status: http.StatusOK, ContentType: "application/json", ResponseBody: []byte(`{"tags":[1]}`)},
+			wantErr:  `response.tags[0] must be string`,
+		},
+		"undocumented status": {
+			exchange: exchange{Method: http.MethodGet, Path: "/api/tags", Status: http.StatusTeapot},
+			wantErr:  `status 418 is not documented`,
+		},
+		"undocumented path": {
+			exchange: exchange{Method: http.MethodGet, Path: "/api/nowhere", Status: http.StatusOK},
+			wantErr:  `is not documented`,
+		},
+		"undocumented query parameter": {
+			exchange: exchange{Method: http.MethodGet, Path: "/api/tags", Query: "page=1", Status: http.StatusOK, ContentType: "application/json", ResponseBody: []byte(`{"tags":[]}`)},
+			wantErr:  `query parameter "page" is not documented`,
+		},
+		"invalid request body on success": {
+			exchange: exchange{Method: http.MethodPost, Path: "/api/users/login", RequestBody: []byte(`{"user":{"email":"a@b.c"}}`), Status: http.StatusOK, ContentType: "application/json", ResponseBody: []byte(`{"user":{"email":"a@b.c","token":"t","username":"u","bio":"","image":""}}`)},
+			wantErr:  `request.user.password is required`,
+		},
+	}
+
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			errs := spec.validateExchange(tc.exchange)
+			if tc.wantErr == "" {
+				test.Equal(t, 0, len(errs))
+				return
+			}
+			test.Equal(t, 1, len(errs))
+			test.Contains(t, errs[0].Error(), tc.wantErr)
+		})
+	}
+}
+
+// routePatterns returns the patterns passed to mux.Handle and mux.HandleFunc in [route].
+// It reads main.go instead of calling route() because [http.ServeMux] can't list its patterns.
+func routePatterns(t *testing.T) []string {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+	test.Nil(t, err)
+
+	var patterns []string
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Name.Name != "route" {
+			continue
+		}
+		ast.Inspect(fn.Body, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if !ok || len(call.Args) == 0 {
+				return true
+			}
+			sel, ok := call.Fun.(*ast.SelectorExpr)
+			if !ok || (sel.Sel.Name != "Handle" && sel.Sel.Name != "HandleFunc") {
+				return true
+			}
+			if x, ok := sel.X.(*ast.Ident); !ok || x.Name != "mux" {
+				return true
+			}
+			lit, ok := call.Args[0].(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING {
+				t.Errorf("route pattern at %v must be a string literal", lit)
+				return true
+			}
+			pattern, err := strconv.Unquote(lit.Value)
+			test.Nil(t, err)
+			patterns = append(patterns, pattern)
+			return true
+		})
+	}
+	return patterns
+}
+
+// openAPIMethods are the operation keys of a path item in OpenAPI 3.0.
+var openAPIMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPISpec is api/openapi.yaml decoded into generic maps, with just enough helpers to validate exchanges.
+type openAPISpec map[string]any
+
+func loadOpenAPISpec(t *testing.T) openAPISpec {
+	t.Helper()
+	spec, err := parseOpenAPISpec(openAPI)
+	test.Nil(t, err)
+	return spec
+}
+
+func parseOpenAPISpec(data []byte) (openAPISpec, error) {
+	// NOTE: decode into a plain map, since yaml decodes nested mappings into the type of the outer map
+	var spec map[string]any
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("parse api/openapi.yaml: %w", err)
+	}
+	return spec, nil
+}
+
+func (s openAPISpec) paths() map[string]map[string]any {
+	paths := make(map[string]map[string]any)
+	for path, item := range asMap(s["paths"]) {
+		paths[path] = asMap(item)
+	}
+	return paths
+}
+
+// operation returns the operation for method and a documented path template such as /articles/{slug}.
+func (s openAPISpec) operation(method, template string) map[string]any {
+	item, ok := s.paths()[template]
+	if !ok {
+		return nil
+	}
+	return asMap(item[strings.ToLower(method)])
+}
+
+// matchPath returns the documented path template matching a concrete path.
+// Templates with more literal segments win, so /articles/feed is preferred over /articles/{slug}.
+func (s openAPISpec) matchPath(path string) (string, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	best, bestScore := "", -1
+	for template := range s.paths() {
+		parts := strings.Split(strings.Trim(template, "/"), "/")
+		if len(parts) != len(segments) {
+			continue
+		}
+		score := 0
+		for i, part := range parts {
+			if strings.HasPrefix(part, "{") && strings.HasSuffix(part, "}") {
+				continue
+			}
+			if part != segments[i] {
+				score = -1
+				break
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = template, score
+		}
+	}
+	return best, bestScore >= 0
+}
+
+// resolve follows a local $ref such as '#/components/schemas/Profile'.
+func (s openAPISpec) resolve(node map[string]any) map[string]any {
+	for node != nil {
+		ref, ok := node["$ref"].(string)
+		if !ok {
+			return node
+		}
+		var current any = map[string]any(s)
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			current = asMap(current)[key]
+		}
+		node = asMap(current)
+	}
+	return nil
+}
+
+// validateExchange checks a recorded request and response against the documented operation.
+// Request bodies and query parameters are only checked for successful responses,
+// since tests intentionally send invalid requests to exercise error paths.
+func (s openAPISpec) validateExchange(ex exchange) []error {
+	name := ex.Method + " " + ex.Path
+	template, ok := s.matchPath(strings.TrimPrefix(ex.Path, apiBasePath))
+	if !ok {
+		return []error{fmt.Errorf("%s: path is not documented", name)}
+	}
+	op := s.operation(ex.Method, template)
+	if op == nil {
+		return []error{fmt.Errorf("%s: method is not documented for %s", name, template)}
+	}
+
+	var errs []error
+	if ex.Status < 300 {
+		errs = append(errs, s.validateQuery(op, ex.Query)...)
+		if body := s.resolve(asMap(op["requestBody"])); body != nil && len(ex.RequestBody) > 0 {
+			errs = append(errs, s.validateBody(body, "request", ex.RequestBody)...)
+		}
+	}
+
+	responses := asMap(op["responses"])
+	response, ok := responses[strconv.Itoa(ex.Status)]
+	if !ok {
+		response, ok = responses["default"]
+	}
+	if !ok {
+		errs = append(errs, fmt.Errorf("status %d is not documented", ex.Status))
+	} else if len(ex.ResponseBody) > 0 {
+		if !strings.HasPrefix(ex.ContentType, "application/json") && s.jsonSchema(s.resolve(asMap(response))) != nil {
+			errs = append(errs, fmt.Errorf("response content type %q is not application/json", ex.ContentType))
+		} else {
+			errs = append(errs, s.validateBody(s.resolve(asMap(response)), "response", ex.ResponseBody)...)
+		}
+	}
+
+	for i, err := range errs {
+		errs[i] = fmt.Errorf("%s -> %d: %w", name, ex.Status, err)
+	}
+	return errs
+}
+
+func (s openAPISpec) validateQuery(op map[string]any, rawQuery string) []error {
+	query, err := url.ParseQuery(rawQuery)
+	if err != nil {
+		return []error{fmt.Errorf("query: %w", err)}
+	}
+	var errs []error
+	for key, values := range query {
+		var schema map[string]any
+		for _, p := range asSlice(op["parameters"]) {
+			param := s.resolve(asMap(p))
+			if param["in"] == "query" && param["name"] == key {
+				schema = s.resolve(asMap(param["schema"]))
+			}
+		}
+		if schema == nil {
+			errs = append(errs, fmt.Errorf("query parameter %q is not documented", key))
+			continue
+		}
+		for _, value := range values {
+			var v any = value
+			if schema["type"] == "integer" || schema["type"] == "boolean" {
+				if err := json.Unmarshal([]byte(value), &v); err != nil {
+					errs = append(errs, fmt.Errorf("query parameter %q must be %s", key, schema["type"]))
+					continue
+				}
+			}
+			errs = append(errs, s.validateValue(schema, "query."+key, v)...)
+		}
+	}
+	return errs
+}
+
+// jsonSchema returns the application/json schema of a request body or response object, if any.
+func (s openAPISpec) jsonSchema(bodyOrResponse map[string]any) map[string]any {
+	media := asMap(asMap(bodyOrResponse["content"])["application/json"])
+	return s.resolve(asMap(media["schema"]))
+}
+
+func (s openAPISpec) validateBody(bodyOrResponse map[string]any, name string, body []byte) []error {
+	schema := s.jsonSchema(bodyOrResponse)
+	if schema == nil {
+		return nil
+	}
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []error{fmt.Errorf("%s is not valid JSON: %w", name, err)}
+	}
+	return s.validateValue(schema, name, value)
+}
+
+// validateValue checks a decoded JSON value against the subset of OpenAPI 3.0 schema keywords used in api/openapi.yaml.
+// Unlike OpenAPI defaults, properties that are not documented are reported so that new fields can't drift from the spec.
+func (s openAPISpec) validateValue(schema map[string]any, name string, value any) []error {
+	schema = s.resolve(schema)
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return []error{fmt.Errorf("%s must not be null", name)}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return []error{fmt.Errorf("%s must be object", name)}
+		}
+		var errs []error
+		for _, key := range asSlice(schema["required"]) {
+			if _, ok := obj[key.(string)]; !ok {
+				errs = append(errs, fmt.Errorf("%s.%s is required", name, key))
+			}
+		}
+		properties := asMap(schema["properties"])
+		for key, v := range obj {
+			property, ok := properties[key]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s.%s is not documented", name, key))
+				continue
+			}
+			errs = append(errs, s.validateValue(asMap(property), name+"."+key, v)...)
+		}
+		return errs
+	case "array":
+		arr, ok := value.([]any)
+		if !ok {
+			return []error{fmt.Errorf("%s must be array", name)}
+		}
+		var errs []error
+		if maxItems, ok := schema["maxItems"].(int); ok && len(arr) > maxItems {
+			errs = append(errs, fmt.Errorf("%s must have at most %d items", name, maxItems))
+		}
+		for i, v := range arr {
+			errs = append(errs, s.validateValue(asMap(schema["items"]), fmt.Sprintf("%s[%d]", name, i), v)...)
+		}
+		return errs
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return []error{fmt.Errorf("%s must be string", name)}
+		}
+		return validateString(schema, name, str)
+	case "integer":
+		num, ok := value.(float64)
+		if !ok || num != math.Trunc(num) {
+			return []error{fmt.Errorf("%s must be integer", name)}
+		}
+		if minimum, ok := schema["minimum"].(int); ok && num < float64(minimum) {
+			return []error{fmt.Errorf("%s must be at least %d", name, minimum)}
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			return []error{fmt.Errorf("%s must be number", name)}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return []error{fmt.Errorf("%s must be boolean", name)}
+		}
+	}
+	return nil
+}
+
+func validateString(schema map[string]any, name, str string) []error {
+	var errs []error
+	if minLength, ok := schema["minLength"].(int); ok && utf8.RuneCountInString(str) < minLength {
+		errs = append(errs, fmt.Errorf("%s must be at least %d characters", name, minLength))
+	}
+	if maxLength, ok := schema["maxLength"].(int); ok && utf8.RuneCountInString(str) > maxLength {
+		errs = append(errs, fmt.Errorf("%s must be at most %d characters", name, maxLength))
+	}
+	if pattern, ok := schema["pattern"].(string); ok && !regexp.MustCompile(pattern).MatchString(str) {
+		errs = append(errs, fmt.Errorf("%s must match %s", name, pattern))
+	}
+	if schema["format"] == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			errs = append(errs, fmt.Errorf("%s must be date-time: %w", name, err))
+		}
+	}
+	return errs
+}
+
+func asMap(v any) map[string]any {
+	m, _ := v.(map[string]any)
+	return m
+}
+
+func asSlice(v any) []any {
+	s, _ := v.([]any)
+	return s
+}
+
+// exchange is a request and response pair recorded by [exchangeRecorder].
+type exchange struct {
+	Method       string
+	Path         string
+	Query        string
+	RequestBody  []byte
+	Status       int
+	ContentType  string
+	ResponseBody []byte
+}
+
+// exchangeRecorder is an [http.RoundTripper] that records every exchange made by the integration tests,
+// so that [TestMain] can validate them against api/openapi.yaml once all tests have run.
+type exchangeRecorder struct {
+	next http.RoundTripper
+
+	mu        sync.Mutex
+	exchanges []exchange
+}
+
+// RoundTrip implements the [http.RoundTripper] interface.
+func (rec *exchangeRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		_ = req.Body.Close()
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	res, err := rec.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	_ = res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	rec.exchanges = append(rec.exchanges, exchange{
+		Method:       req.Method,
+		Path:         req.URL.Path,
+		Query:        req.URL.RawQuery,
+		RequestBody:  reqBody,
+		Status:       res.StatusCode,
+		ContentType:  res.Header.Get("Content-Type"),
+		ResponseBody: resBody,
+	})
+	return res, nil
+}
+
+// validate checks every recorded exchange under [apiBasePath] against spec.
+// CORS preflight requests are skipped since they are handled by middleware rather than documented operations.
+func (rec *exchangeRecorder) validate(spec openAPISpec) []error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	var errs []error
+	for _, ex := range rec.exchanges {
+		if ex.Method == http.MethodOptions || !strings.HasPrefix(ex.Path, apiBasePath+"/") {
+			continue
+		}
+		errs = append(errs, spec.validateExchange(ex)...)
+	}
+	return errs
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// articlesPage is the page of an article list requested with the limit, offset, cursor and count query parameters.
+// Lists are ordered newest first by (created_at, id), so a cursor names the last article of the previous page
+// and the next page starts right after it, however many articles were created in the meantime.
+type articlesPage struct {
+	limit  int64
+	offset int64
+	cursor *articlesCursor
+	count  bool
+}
+
+// parseArticlesPage parses the pagination query parameters.
+// Invalid limit, offset and count values fall back to their defaults, but an invalid cursor is an error:
+// silently starting over from the first page would show the client articles it has already seen.
+func parseArticlesPage(query url.Values) (articlesPage, error) {
+	page := articlesPage{limit: 20, count: true}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		if parsedLimit, err := parseInt64(limitStr); err == nil && parsedLimit > 0 {
+			page.limit = parsedLimit
+		}
+	}
+
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		if parsedOffset, err := parseInt64(offsetStr); err == nil && parsedOffset >= 0 {
+			page.offset = parsedOffset
+		}
+	}
+
+	if countStr := query.Get("count"); countStr != "" {
+		if count, err := strconv.ParseBool(countStr); err == nil {
+			page.count = count
+		}
+	}
+
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		cursor, err := parseArticlesCursor(cursorStr)
+		if err != nil {
+			return articlesPage{}, err
+		}
+		if page.offset != 0 {
+			return articlesPage{}, errors.New("cursor cannot be combined with offset")
+		}
+		page.cursor = &cursor
+	}
+	return page, nil
+}
+
+// beforeCreatedAt and beforeID are the keyset arguments of the list queries, NULL without a cursor.
+func (p articlesPage) beforeCreatedAt() sql.NullTime {
+	if p.cursor == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: p.cursor.createdAt, Valid: true}
+}
+
+func (p articlesPage) beforeID() sql.NullInt64 {
+	if p.cursor == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: p.cursor.id, Valid: true}
+}
+
+// next trims rows, queried with a limit of p.limit+1, to the page and returns the cursor for the page after it.
+// The cursor is empty on the last page.
+func next[T any](p articlesPage, rows []T, cursor func(T) articlesCursor) ([]T, string) {
+	if int64(len(rows)) <= p.limit {
+		return rows, ""
+	}
+	rows = rows[:p.limit]
+	return rows, cursor(rows[len(rows)-1]).String()
+}
+
+// articlesCursor is the position of an article in a list. It is opaque to clients.
+type articlesCursor struct {
+	createdAt time.Time
+	id        int64
+}
+
+func (c articlesCursor) String() string {
+	return base64.RawURLEncoding.EncodeToString(fmt.Appendf(nil, "%d:%d", c.createdAt.UnixNano(), c.id))
+}
+
+func parseArticlesCursor(s string) (articlesCursor, error) {
+	errInvalid := errors.New("invalid cursor")
+
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return articlesCursor{}, errInvalid
+	}
+	var nanos, id int64
+	if n, err := fmt.Sscanf(string(b), "%d:%d", &nanos, &id); err != nil || n != 2 {
+		return articlesCursor{}, errInvalid
+	}
+	return articlesCursor{createdAt: time.Unix(0, nanos).UTC(), id: id}, nil
+}
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+// readyzTimeout bounds the checks of one /readyz request, so a hung database fails the probe instead of hanging it.
+const readyzTimeout = 2 * time.Second
+
+// readiness tells load balancers whether the server should get traffic, see [handleGetReadyz].
+// A nil readiness is always ready.
+type readiness struct {
+	db       store.Store
+	tables   []schemaTable // tables of the schema, which the database must have with all their columns
+	jobs     *jobRunner
+	draining atomic.Bool
+}
+
+// newReadiness returns the readiness of a server on db, which must have the tables created by schema, running jobs.
+func newReadiness(db store.Store, schema string, jobs *jobRunner) *readiness {
+	return &readiness{db: db, tables: schemaTables(schema), jobs: jobs}
+}
+
+// schemaTable is a table created by a schema file.
+type schemaTable struct {
+	name    string
+	columns []string
+}
+
+// schemaTablePattern matches the tables created by a schema file, with their definitions one per line.
+var schemaTablePattern = regexp.MustCompile(`(?is)CREATE TABLE (?:IF NOT EXISTS )?(\w+) \((.*?)\);`)
+
+// schemaColumnPattern matches the name of a column at the start of a line of a table definition.
+var schemaColumnPattern = regexp.MustCompile(`^\s*(\w+)\s`)
+
+// schemaTables returns the tables created by schema with their columns, in order.
+// Lines of constraints, such as PRIMARY KEY and FOREIGN KEY, are not columns.
+func schemaTables(schema string) []schemaTable {
+	var tables []schemaTable
+	for _, match := range schemaTablePattern.FindAllStringSubmatch(schema, -1) {
+		table := schemaTable{name: match[1]}
+		for line := range strings.SplitSeq(match[2], "\n") {
+			column := schemaColumnPattern.FindStringSubmatch(line)
+			if column == nil {
+				continue
+			}
+			switch strings.ToUpper(column[1]) {
+			case "PRIMARY", "FOREIGN", "UNIQUE", "CHECK", "CONSTRAINT":
+				continue
+			}
+			table.columns = append(table.columns, column[1])
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+// drain makes the server unready, so load balancers stop sending it requests before it shuts down.
+func (r *readiness) drain() {
+	if r != nil {
+		r.draining.Store(true)
+	}
+}
+
+// check runs the checks of the server, and returns the error of each by name with the status of the server:
+// "ok", "unavailable" when a check failed, or "draining" without running them, as the server is going away anyway.
+func (r *readiness) check(ctx context.Context) (map[string]error, string) {
+	if r == nil {
+		return nil, "ok"
+	}
+	if r.draining.Load() {
+		return nil, "draining"
+	}
+
+	checks := map[string]error{"database": r.db.Ping(ctx), "jobs": r.jobs.check(time.Now())}
+	if checks["database"] == nil {
+		checks["schema"] = r.checkSchema(ctx)
+	}
+	for _, err := range checks {
+		if err != nil {
+			return checks, "unavailable"
+		}
+	}
+	return checks, "ok"
+}
+
+// checkSchema returns an error unless the database has every table and column of the schema,
+// such as after restoring a backup taken before a table or column was added.
+func (r *readiness) checkSchema(ctx context.Context) error {
+	columns, err := r.db.Columns(ctx)
+	if err != nil {
+		return err
+	}
+	var missingTables, missingColumns []string
+	for _, table := range r.tables {
+		existing, ok := columns[table.name]
+		if !ok {
+			missingTables = append(missingTables, table.name)
+			continue
+		}
+		for _, column := range table.columns {
+			if !slices.Contains(existing, column) {
+				missingColumns = append(missingColumns, table.name+"."+column)
+			}
+		}
+	}
+	var missing []string
+	if len(missingTables) > 0 {
+		missing = append(missing, "tables "+strings.Join(missingTables, ", "))
+	}
+	if len(missingColumns) > 0 {
+		missing = append(missing, "columns "+strings.Join(missingColumns, ", "))
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing %s", strings.Join(missing, "; "))
+	}
+	return nil
+}
+
+// handleGetLivez returns an [http.HandlerFunc] that responds while the process can serve requests at all.
+// Unlike /readyz it checks no dependencies, so an orchestrator restarts the server only when restarting helps.
+func handleGetLivez() http.HandlerFunc {
+	return func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"status":"ok"}` + "\n"))
+	}
+}
+
+// handleGetReadyz returns an [http.HandlerFunc] that responds with 200 when the server is ready for traffic,
+// and 503 Service Unavailable when the database is unreachable or lacks tables or columns, the job runner is stalled,
+// or the server is shutting down.
+func handleGetReadyz(ready *readiness) http.HandlerFunc {
+	type responseBody struct {
+		Status string            `json:"status"`
+		Checks map[string]string `json:"checks,omitempty"`
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), readyzTimeout)
+		defer cancel()
+
+		checks, status := ready.check(ctx)
+		res := responseBody{Status: status, Checks: make(map[string]string, len(checks))}
+		for name, err := range checks {
+			res.Checks[name] = "ok"
+			if err != nil {
+				res.Checks[name] = err.Error()
+			}
+		}
+		code := http.StatusOK
+		if status != "ok" {
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		w.WriteHeader(code)
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+
+	"github.com/raeperd/realworld.go/internal/sqlite"
+)
+
+type readyzResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+func TestGetLivezReadyz(t *testing.T) {
+	t.Parallel()
+
+	res, err := http.Get(endpoint + "/livez")
+	test.Nil(t, err)
+	_ = res.Body.Close()
+	test.Equal(t, http.StatusOK, res.StatusCode)
+
+	res, err = http.Get(endpoint + "/readyz")
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = res.Body.Close() })
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	var body readyzResponse
+	test.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	test.Equal(t, "ok", body.Status)
+	test.DeepEqual(t, map[string]string{"database": "ok", "schema": "ok", "jobs": "ok"}, body.Checks)
+}
+
+func TestHandleGetReadyz(t *testing.T) {
+	t.Parallel()
+
+	sqlDB, err := openDB(t.Context(), "")
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = sqlDB.Close() })
+	db := sqlite.NewStore(sqlDB)
+	jobs := newJobRunner(db, slog.New(slog.NewTextHandler(io.Discard, nil)), time.Hour)
+
+	readyz := func(ready *readiness) (int, readyzResponse) {
+		rec := httptest.NewRecorder()
+		handleGetReadyz(ready).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body readyzResponse
+		test.Nil(t, json.NewDecoder(rec.Body).Decode(&body))
+		return rec.Code, body
+	}
+
+	// The job runner must have looked for jobs recently
+	ready := newReadiness(db, ddl, jobs)
+	code, body := readyz(ready)
+	test.Equal(t, http.StatusServiceUnavailable, code)
+	test.Equal(t, "unavailable", body.Status)
+	test.Equal(t, "job runner not started", body.Checks["jobs"])
+	test.Equal(t, "ok", body.Checks["schema"])
+
+	test.Equal(t, false, jobs.runNext(t.Context()))
+	code, body = readyz(ready)
+	test.Equal(t, http.StatusOK, code)
+	test.Equal(t, "ok", body.Status)
+
+	jobs.polled.Store(time.Now().Add(-2 * jobStallTimeout).UnixNano())
+	_, body = readyz(ready)
+	test.Contains(t, body.Checks["jobs"], "job runner stalled")
+	jobs.polled.Store(time.Now().UnixNano())
+
+	// The database must have every table of the schema
+	code, body = readyz(newReadiness(db, ddl+"\nCREATE TABLE IF NOT EXISTS reports (id INTEGER PRIMARY KEY);", jobs))
+	test.Equal(t, http.StatusServiceUnavailable, code)
+	test.Equal(t, "missing tables reports", body.Checks["schema"])
+
+	// And every column, which a database restored from before users could be disabled lacks
+	oldDB, err := openDB(t.Context(), filepath.Join(t.TempDir(), "old.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = oldDB.Close() })
+	_, err = oldDB.ExecContext(t.Context(), "ALTER TABLE users DROP COLUMN disabled_at")
+	test.Nil(t, err)
+	code, body = readyz(newReadiness(sqlite.NewStore(oldDB), ddl, jobs))
+	test.Equal(t, http.StatusServiceUnavailable, code)
+	test.Equal(t, "missing columns users.disabled_at", body.Checks["schema"])
+
+	// Draining fails readiness whatever the checks, so load balancers stop sending requests before shutdown
+	ready.drain()
+	code, body = readyz(ready)
+	test.Equal(t, http.StatusServiceUnavailable, code)
+	test.Equal(t, "draining", body.Status)
+
+	// A closed database fails the database check
+	test.Nil(t, sqlDB.Close())
+	code, body = readyz(newReadiness(db, ddl, jobs))
+	test.Equal(t, http.StatusServiceUnavailable, code)
+	test.Contains(t, body.Checks["database"], "closed")
+
+	code, body = readyz(nil)
+	test.Equal(t, http.StatusOK, code)
+	test.Equal(t, "ok", body.Status)
+}
+
+func TestSchemaTables(t *testing.T) {
+	t.Parallel()
+
+	for _, schema := range []string{ddl, postgresDDL} {
+		tables := schemaTables(schema)
+		test.Equal(t, "users", tables[0].name)
+		test.DeepEqual(t, []string{
+			"id", "username", "email", "password", "bio", "image", "created_at", "updated_at", "disabled_at", "followers_count", "touched_at",
+		}, tables[0].columns)
+		test.DeepEqual(t, []string{"follower_id", "followed_id", "created_at"}, tables[1].columns)
+		test.True(t, slices.ContainsFunc(tables, func(table schemaTable) bool { return table.name == "login_events" }))
+	}
+}
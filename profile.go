@@ -5,22 +5,21 @@ import (
 	"errors"
 	"net/http"
 
-	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
 )
 
-func handleGetProfilesUsername(db *sql.DB) http.HandlerFunc {
+func handleGetProfilesUsername(db store.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		username := r.PathValue("username")
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-		user, err := queries.GetUserByUsername(r.Context(), username)
+		user, err := tx.GetUserByUsername(r.Context(), username)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("profile not found")}, w)
@@ -33,7 +32,8 @@ func handleGetProfilesUsername(db *sql.DB) http.HandlerFunc {
 		// Check if authenticated user is following this profile
 		following := false
 		if viewerID, ok := r.Context().Value(userIDKey).(int64); ok {
-			isFollowing, err := queries.IsFollowing(r.Context(), sqlite.IsFollowingParams{
+			var err error
+			following, err = tx.IsFollowing(r.Context(), store.IsFollowingParams{
 				FollowerID: viewerID,
 				FollowedID: user.ID,
 			})
@@ -41,7 +41,6 @@ func handleGetProfilesUsername(db *sql.DB) http.HandlerFunc {
 				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 				return
 			}
-			following = isFollowing > 0
 		}
 
 		if err := tx.Commit(); err != nil {
@@ -49,14 +48,17 @@ func handleGetProfilesUsername(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		encodeResponse(r.Context(), http.StatusOK, profileGetResponseWrapper{
-			Profile: profileGetResponseBody{
-				Username:  user.Username,
-				Bio:       user.Bio.String,
-				Image:     user.Image.String,
-				Following: following,
-			},
-		}, w)
+		profile := profileGetResponseBody{
+			Username:       user.Username,
+			Bio:            user.Bio.String,
+			Image:          user.Image.String,
+			Following:      following,
+			FollowersCount: user.FollowersCount,
+		}
+		if notModified(w, r, etag(profile), lastModified(user.UpdatedAt, user.TouchedAt.Time)) {
+			return
+		}
+		encodeResponse(r.Context(), http.StatusOK, profileGetResponseWrapper{Profile: profile}, w)
 	}
 }
 
@@ -65,14 +67,15 @@ type profileGetResponseWrapper struct {
 }
 
 type profileGetResponseBody struct {
-	Username  string `json:"username"`
-	Bio       string `json:"bio"`
-	Image     string `json:"image"`
-	Following bool   `json:"following"`
+	Username       string `json:"username"`
+	Bio            string `json:"bio"`
+	Image          string `json:"image"`
+	Following      bool   `json:"following"`
+	FollowersCount int64  `json:"followersCount"`
 }
 
 //nolint:dupl // Follow and unfollow handlers have intentional structural similarity
-func handlePostProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
+func handlePostProfilesUsernameFollow(db store.Store, jobs *jobRunner) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		username := r.PathValue("username")
 		followerID, ok := r.Context().Value(userIDKey).(int64)
@@ -81,17 +84,15 @@ func handlePostProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-
 		// Get user to follow
-		followedUser, err := queries.GetUserByUsername(r.Context(), username)
+		followedUser, err := tx.GetUserByUsername(r.Context(), username)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("profile not found")}, w)
@@ -108,7 +109,7 @@ func handlePostProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Create follow relationship
-		err = queries.CreateFollow(r.Context(), sqlite.CreateFollowParams{
+		err = tx.CreateFollow(r.Context(), store.CreateFollowParams{
 			FollowerID: followerID,
 			FollowedID: followedUser.ID,
 		})
@@ -117,24 +118,39 @@ func handlePostProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		// Read the user again for the followers count the trigger just updated
+		followedUser, err = tx.GetUserByID(r.Context(), followedUser.ID)
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
+		// Add the followed user's articles to the follower's feed once the follow is committed
+		if err := enqueueJob(r.Context(), tx, jobFeedBackfill, feedJob{FollowerID: followerID, AuthorID: followedUser.ID}); err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
 		if err := tx.Commit(); err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		jobs.notify()
 
 		encodeResponse(r.Context(), http.StatusOK, profileGetResponseWrapper{
 			Profile: profileGetResponseBody{
-				Username:  followedUser.Username,
-				Bio:       followedUser.Bio.String,
-				Image:     followedUser.Image.String,
-				Following: true,
+				Username:       followedUser.Username,
+				Bio:            followedUser.Bio.String,
+				Image:          followedUser.Image.String,
+				Following:      true,
+				FollowersCount: followedUser.FollowersCount,
 			},
 		}, w)
 	}
 }
 
 //nolint:dupl // Follow and unfollow handlers have intentional structural similarity
-func handleDeleteProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
+func handleDeleteProfilesUsernameFollow(db store.Store, jobs *jobRunner) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		username := r.PathValue("username")
 		followerID, ok := r.Context().Value(userIDKey).(int64)
@@ -143,17 +159,15 @@ func handleDeleteProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-
 		// Get user to unfollow
-		followedUser, err := queries.GetUserByUsername(r.Context(), username)
+		followedUser, err := tx.GetUserByUsername(r.Context(), username)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusNotFound, []error{errors.New("profile not found")}, w)
@@ -170,7 +184,7 @@ func handleDeleteProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
 		}
 
 		// Delete follow relationship
-		err = queries.DeleteFollow(r.Context(), sqlite.DeleteFollowParams{
+		err = tx.DeleteFollow(r.Context(), store.DeleteFollowParams{
 			FollowerID: followerID,
 			FollowedID: followedUser.ID,
 		})
@@ -179,17 +193,32 @@ func handleDeleteProfilesUsernameFollow(db *sql.DB) http.HandlerFunc {
 			return
 		}
 
+		// Read the user again for the followers count the trigger just updated
+		followedUser, err = tx.GetUserByID(r.Context(), followedUser.ID)
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
+		// Remove the unfollowed user's articles from the follower's feed once the unfollow is committed
+		if err := enqueueJob(r.Context(), tx, jobFeedPrune, feedJob{FollowerID: followerID, AuthorID: followedUser.ID}); err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
 		if err := tx.Commit(); err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		jobs.notify()
 
 		encodeResponse(r.Context(), http.StatusOK, profileGetResponseWrapper{
 			Profile: profileGetResponseBody{
-				Username:  followedUser.Username,
-				Bio:       followedUser.Bio.String,
-				Image:     followedUser.Image.String,
-				Following: false,
+				Username:       followedUser.Username,
+				Bio:            followedUser.Bio.String,
+				Image:          followedUser.Image.String,
+				Following:      false,
+				FollowersCount: followedUser.FollowersCount,
 			},
 		}, w)
 	}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/store"
+)
+
+// rateLimitPolicy allows limit requests per window to each client of a group of routes, in bursts of up to limit.
+type rateLimitPolicy struct {
+	name   string // keys the buckets of the policy apart from those of other policies
+	limit  int
+	window time.Duration
+}
+
+// Rate limits of the routes in [route]. Login and registration are strict, as they are what passwords are
+// guessed and accounts are spammed with, while reads are loose enough for any client that paginates.
+var (
+	authRateLimit  = rateLimitPolicy{name: "auth", limit: 10, window: time.Minute}
+	writeRateLimit = rateLimitPolicy{name: "write", limit: 60, window: time.Minute}
+	readRateLimit  = rateLimitPolicy{name: "read", limit: 600, window: time.Minute}
+)
+
+// rateLimitBuckets keep a token bucket for each key, as the theoretical arrival time (tat) of the generic cell
+// rate algorithm: each request moves tat interval later, from now at the earliest, unless that would take it
+// more than tolerance ahead of now. The bucket is full once tat is past.
+type rateLimitBuckets interface {
+	take(ctx context.Context, key string, now time.Time, interval, tolerance time.Duration) (tat time.Time, allowed bool, err error)
+	deleteFull(ctx context.Context, now time.Time) (int64, error)
+}
+
+// rateLimiter limits requests to routes by their policy, for each client by user ID, or else by IP.
+// A nil *rateLimiter limits nothing.
+type rateLimiter struct {
+	buckets        rateLimitBuckets
+	trustedProxies []netip.Prefix
+	log            *slog.Logger
+	now            func() time.Time
+}
+
+// newRateLimiter returns a limiter with buckets in memory, or in db to share them between processes.
+// The IP of a client is the first untrusted address of X-Forwarded-For, from the right,
+// when the request comes from one of trustedProxies.
+func newRateLimiter(db store.Store, trustedProxies []netip.Prefix, log *slog.Logger) *rateLimiter {
+	var buckets rateLimitBuckets = &memoryBuckets{tats: make(map[string]time.Time)}
+	if db != nil {
+		buckets = dbBuckets{db: db}
+	}
+	return &rateLimiter{buckets: buckets, trustedProxies: trustedProxies, log: log, now: time.Now}
+}
+
+// limit is a middleware that takes a request of the client from its bucket of policy, or responds with 429 Too Many
+// Requests and a Retry-After header if it is empty. Every response tells the state of the bucket in RateLimit headers.
+// It must run after [authenticate] or [authenticateOptional] to limit authenticated clients by user.
+// Requests are allowed when the buckets can't be read, so an unavailable database does not block the API.
+func (l *rateLimiter) limit(next http.Handler, policy rateLimitPolicy) http.Handler {
+	if l == nil {
+		return next
+	}
+	interval := policy.window / time.Duration(policy.limit)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := policy.name + ":ip:" + clientIP(r, l.trustedProxies)
+		if userID, ok := r.Context().Value(userIDKey).(int64); ok {
+			key = policy.name + ":user:" + strconv.FormatInt(userID, 10)
+		}
+		now := l.now()
+		tat, allowed, err := l.buckets.take(r.Context(), key, now, interval, policy.window)
+		if err != nil {
+			requestLogger(r.Context(), l.log).ErrorContext(r.Context(), "failed to take rate limit", slog.String("error", err.Error()))
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ahead := tat.Sub(now)
+		remaining := 0
+		if allowed {
+			remaining = int((policy.window - ahead) / interval)
+		}
+		w.Header().Set("RateLimit-Limit", strconv.Itoa(policy.limit))
+		w.Header().Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		w.Header().Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(ahead)))
+		w.Header().Set("RateLimit-Policy", fmt.Sprintf("%d;w=%d", policy.limit, ceilSeconds(policy.window)))
+		if !allowed {
+			// The next request is allowed once tat is no more than interval short of the tolerance ahead
+			retry := ceilSeconds(ahead + interval - policy.window)
+			w.Header().Set("Retry-After", strconv.Itoa(retry))
+			encodeErrorResponse(r.Context(), http.StatusTooManyRequests, []error{fmt.Errorf("too many requests, retry in %d seconds", retry)}, w)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// prune deletes full buckets every interval until ctx is done, as they are the same as no bucket.
+func (l *rateLimiter) prune(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := l.buckets.deleteFull(ctx, l.now()); err != nil {
+				l.log.ErrorContext(ctx, "failed to prune rate limits", slog.String("error", err.Error()))
+			}
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// clientIP returns the IP of the client of r: its remote address, or the X-Forwarded-For address that the
+// last of the trusted proxies it went through received it from.
+// Peers of a Unix socket have no address, and are trusted as the local proxy that the socket permissions let in.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	unixPeer := err != nil && (host == "" || host == "@")
+	if !unixPeer && (err != nil || !trusted(addr, trustedProxies)) {
+		return host
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(forwarded[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap()
+		if !trusted(addr, trustedProxies) {
+			break
+		}
+	}
+	if !addr.IsValid() {
+		return host
+	}
+	return addr.String()
+}
+
+func trusted(addr netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(addr.Unmap()) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePrefixes parses a comma-separated list of IPs and CIDR prefixes, such as 10.0.0.0/8,127.0.0.1.
+func parsePrefixes(s string) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	for field := range strings.SplitSeq(s, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		if addr, err := netip.ParseAddr(field); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid IP or CIDR prefix %q", field)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// memoryBuckets are the buckets of a single process.
+type memoryBuckets struct {
+	mu   sync.Mutex
+	tats map[string]time.Time
+}
+
+func (m *memoryBuckets) take(_ context.Context, key string, now time.Time, interval, tolerance time.Duration) (time.Time, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	tat := m.tats[key]
+	if tat.Before(now) {
+		tat = now
+	}
+	next := tat.Add(interval)
+	if next.Sub(now) > tolerance {
+		return m.tats[key], false, nil
+	}
+	m.tats[key] = next
+	return next, true, nil
+}
+
+func (m *memoryBuckets) deleteFull(_ context.Context, now time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var deleted int64
+	for key, tat := range m.tats {
+		if !tat.After(now) {
+			delete(m.tats, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// dbBuckets are the buckets of every process with the same database, in the rate_limits table.
+type dbBuckets struct {
+	db store.Store
+}
+
+func (d dbBuckets) take(ctx context.Context, key string, now time.Time, interval, tolerance time.Duration) (time.Time, bool, error) {
+	row, err := d.db.TakeRateLimit(ctx, store.TakeRateLimitParams{
+		Key:         key,
+		NowMs:       now.UnixMilli(),
+		IntervalMs:  interval.Milliseconds(),
+		ToleranceMs: tolerance.Milliseconds(),
+	})
+	return time.UnixMilli(row.Tat), row.Allowed, err
+}
+
+func (d dbBuckets) deleteFull(ctx context.Context, now time.Time) (int64, error) {
+	return d.db.DeleteExpiredRateLimits(ctx, now.UnixMilli())
+}
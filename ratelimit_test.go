@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "ratelimit.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for name, limits := range map[string]*rateLimiter{
+		"memory": newRateLimiter(nil, nil, log),
+		"db":     newRateLimiter(db, nil, log),
+	} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Unix(1_700_000_000, 0)
+			limits.now = func() time.Time { return now }
+			policy := rateLimitPolicy{name: "test", limit: 3, window: 3 * time.Second}
+			handler := limits.limit(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			}), policy)
+			serve := func(remoteAddr string, userID int64) *httptest.ResponseRecorder {
+				req := httptest.NewRequest(http.MethodPost, "/api/users/login", nil)
+				req.RemoteAddr = remoteAddr
+				if userID != 0 {
+					req = req.WithContext(context.WithValue(req.Context(), userIDKey, userID))
+				}
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+				return rec
+			}
+
+			// A full bucket allows a burst of limit requests
+			for remaining := 2; remaining >= 0; remaining-- {
+				rec := serve("192.0.2.1:1234", 0)
+				test.Equal(t, http.StatusNoContent, rec.Code)
+				test.Equal(t, "3", rec.Header().Get("RateLimit-Limit"))
+				test.Equal(t, "3;w=3", rec.Header().Get("RateLimit-Policy"))
+				test.Equal(t, strconv.Itoa(remaining), rec.Header().Get("RateLimit-Remaining"))
+			}
+			rec := serve("192.0.2.1:5678", 0)
+			test.Equal(t, http.StatusTooManyRequests, rec.Code)
+			test.Equal(t, "1", rec.Header().Get("Retry-After"))
+			test.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+			test.Equal(t, "3", rec.Header().Get("RateLimit-Reset"))
+
+			// Other clients and users have buckets of their own
+			test.Equal(t, http.StatusNoContent, serve("192.0.2.2:1234", 0).Code)
+			test.Equal(t, http.StatusNoContent, serve("192.0.2.1:1234", 1).Code)
+
+			// The bucket refills one request per interval
+			now = now.Add(time.Second)
+			test.Equal(t, http.StatusNoContent, serve("192.0.2.1:1234", 0).Code)
+			test.Equal(t, http.StatusTooManyRequests, serve("192.0.2.1:1234", 0).Code)
+
+			// Full buckets are pruned
+			now = now.Add(time.Minute)
+			deleted, err := limits.buckets.deleteFull(t.Context(), now)
+			test.Nil(t, err)
+			test.Equal(t, int64(3), deleted)
+		})
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Parallel()
+
+	proxies, err := parsePrefixes("10.0.0.0/8, 127.0.0.1")
+	test.Nil(t, err)
+	test.DeepEqual(t, []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("127.0.0.1/32")}, proxies)
+	_, err = parsePrefixes("10.0.0.0/8,proxy")
+	test.NotNil(t, err)
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  []string
+		want       string
+	}{
+		{name: "direct", remoteAddr: "192.0.2.1:1234", want: "192.0.2.1"},
+		{name: "untrusted forwarded", remoteAddr: "192.0.2.1:1234", forwarded: []string{"198.51.100.1"}, want: "192.0.2.1"},
+		{name: "trusted proxy", remoteAddr: "127.0.0.1:1234", forwarded: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "chain of proxies", remoteAddr: "10.0.0.1:1234", forwarded: []string{"203.0.113.9, 198.51.100.1, 10.0.0.2"}, want: "198.51.100.1"},
+		{name: "multiple headers", remoteAddr: "10.0.0.1:1234", forwarded: []string{"203.0.113.9", "198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed after invalid", remoteAddr: "10.0.0.1:1234", forwarded: []string{"203.0.113.9, unknown, 10.0.0.2"}, want: "10.0.0.2"},
+		{name: "trusted without header", remoteAddr: "10.0.0.1:1234", want: "10.0.0.1"},
+		{name: "unix socket proxy", remoteAddr: "@", forwarded: []string{"203.0.113.9, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "unix socket without header", remoteAddr: "@", want: "@"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+			req.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwarded {
+				req.Header.Add("X-Forwarded-For", value)
+			}
+			test.Equal(t, tc.want, clientIP(req, proxies))
+		})
+	}
+}
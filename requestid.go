@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"log/slog"
+	"net/http"
+)
+
+const requestScopeKey contextKey = "requestScope"
+
+// maxRequestIDLength bounds the X-Request-ID taken from clients, as it is echoed in responses and logs.
+const maxRequestIDLength = 128
+
+// requestScope is what the logs of a request say about it, filled in as the request is served:
+// its ID from the start, its route once the mux matches it and its user once authenticated.
+type requestScope struct {
+	id      string
+	log     *slog.Logger
+	request *http.Request // the request given to the mux, which sets its pattern, see [scopeRoute]
+	userID  int64         // 0 until authenticated
+}
+
+// requestID is a middleware that identifies each request by its X-Request-ID header, or a new random ID,
+// and returns the ID in the X-Request-ID header of the response and in error bodies.
+// The context of the request keeps a logger derived from log, see [requestLogger].
+// It must be the first middleware in the chain, so that the others find the route in [routePattern].
+func requestID(next http.Handler, log *slog.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get("X-Request-ID")
+		if !validRequestID(id) {
+			id = rand.Text()
+		}
+		w.Header().Set("X-Request-ID", id)
+
+		scope := &requestScope{id: id}
+		scope.log = slog.New(requestLogHandler{handler: log.Handler(), scope: scope}).With(slog.String("request_id", id))
+		r = r.WithContext(context.WithValue(r.Context(), requestScopeKey, scope))
+		scope.request = r
+		next.ServeHTTP(w, r)
+	})
+}
+
+// scopeRoute is a middleware that records the request given to next, the mux, in its scope.
+// The mux sets the pattern of the route it matches on that request only, not on the copies
+// that middlewares before it made with another context.
+func scopeRoute(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if scope := scopeOf(r.Context()); scope != nil {
+			scope.request = r
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routePattern returns the pattern of the route in [route] that r matched, or empty if none did (yet).
+func routePattern(r *http.Request) string {
+	if scope := scopeOf(r.Context()); scope != nil {
+		return scope.request.Pattern
+	}
+	return r.Pattern
+}
+
+// validRequestID reports whether id from a client is short and printable enough to be reused.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := range len(id) {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// requestLogger returns the logger of the request of ctx, which adds its request_id, user_id and route
+// to records, or fallback outside of a request.
+func requestLogger(ctx context.Context, fallback *slog.Logger) *slog.Logger {
+	if scope := scopeOf(ctx); scope != nil {
+		return scope.log
+	}
+	return fallback
+}
+
+// requestIDFrom returns the ID of the request of ctx, or empty outside of a request.
+func requestIDFrom(ctx context.Context) string {
+	if scope := scopeOf(ctx); scope != nil {
+		return scope.id
+	}
+	return ""
+}
+
+// setRequestUser records userID as the user of the request of ctx for its logs.
+func setRequestUser(ctx context.Context, userID int64) {
+	if scope := scopeOf(ctx); scope != nil {
+		scope.userID = userID
+	}
+}
+
+// scopeOf returns the scope of the request of ctx, or nil outside of a request.
+func scopeOf(ctx context.Context) *requestScope {
+	scope, _ := ctx.Value(requestScopeKey).(*requestScope)
+	return scope
+}
+
+// requestLogHandler adds the route and user of a request to records, as they are known when logged.
+type requestLogHandler struct {
+	handler slog.Handler
+	scope   *requestScope
+}
+
+func (h requestLogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.handler.Enabled(ctx, level)
+}
+
+func (h requestLogHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.scope.userID != 0 {
+		r.AddAttrs(slog.Int64("user_id", h.scope.userID))
+	}
+	if h.scope.request.Pattern != "" {
+		r.AddAttrs(slog.String("route", h.scope.request.Pattern))
+	}
+	return h.handler.Handle(ctx, r)
+}
+
+func (h requestLogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestLogHandler{handler: h.handler.WithAttrs(attrs), scope: h.scope}
+}
+
+func (h requestLogHandler) WithGroup(name string) slog.Handler {
+	return requestLogHandler{handler: h.handler.WithGroup(name), scope: h.scope}
+}
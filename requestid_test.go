@@ -0,0 +1,114 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raeperd/realworld.go/client"
+	"github.com/raeperd/test"
+)
+
+func TestRequestID(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "requestid.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	var logs strings.Builder
+	server := httptest.NewServer(route(slog.New(slog.NewJSONHandler(&logs, nil)), "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret"}))
+	t.Cleanup(server.Close)
+
+	get := func(path, id, token string) *http.Response {
+		req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+path, nil)
+		test.Nil(t, err)
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Token "+token)
+		}
+		res, err := server.Client().Do(req)
+		test.Nil(t, err)
+		t.Cleanup(func() { _ = res.Body.Close() })
+		return res
+	}
+
+	// A new ID for each request without one, and the ID of the client otherwise, unless unusable
+	first, second := get("/api/tags", "", ""), get("/api/tags", "", "")
+	test.NotZero(t, first.Header.Get("X-Request-ID"))
+	test.NotEqual(t, first.Header.Get("X-Request-ID"), second.Header.Get("X-Request-ID"))
+	test.Equal(t, "client-id-1", get("/api/tags", "client-id-1", "").Header.Get("X-Request-ID"))
+	test.NotEqual(t, "client id", get("/api/tags", "client id", "").Header.Get("X-Request-ID"))
+	test.Equal(t, maxRequestIDLength, len(get("/api/tags", strings.Repeat("a", maxRequestIDLength), "").Header.Get("X-Request-ID")))
+	test.NotEqual(t, maxRequestIDLength+1, len(get("/api/tags", strings.Repeat("a", maxRequestIDLength+1), "").Header.Get("X-Request-ID")))
+
+	// Error bodies carry the ID too
+	res := get("/api/articles/missing", "client-id-2", "")
+	test.Equal(t, http.StatusNotFound, res.StatusCode)
+	var body errorResponseBody
+	test.Nil(t, json.NewDecoder(res.Body).Decode(&body))
+	test.Equal(t, "client-id-2", body.RequestID)
+
+	// The access log of a request has its ID, user and route
+	user, err := client.New(server.URL, client.WithHTTPClient(server.Client())).Register(t.Context(), client.NewUser{
+		Username: "requestid", Email: "requestid@example.com", Password: "testpass123",
+	})
+	test.Nil(t, err)
+	stored, err := db.GetUserByEmail(t.Context(), user.Email)
+	test.Nil(t, err)
+	test.Equal(t, http.StatusOK, get("/api/user", "client-id-3", user.Token).StatusCode)
+	var accessed struct {
+		RequestID string `json:"request_id"`
+		UserID    int64  `json:"user_id"`
+		Route     string `json:"route"`
+	}
+	for line := range strings.Lines(logs.String()) {
+		if strings.Contains(line, `"request_id":"client-id-3"`) {
+			test.Nil(t, json.Unmarshal([]byte(line), &accessed))
+		}
+	}
+	test.Equal(t, "client-id-3", accessed.RequestID)
+	test.Equal(t, stored.ID, accessed.UserID)
+	test.Equal(t, "GET /api/user", accessed.Route)
+}
+
+func TestRequestID_ServerError(t *testing.T) {
+	t.Parallel()
+
+	var logs strings.Builder
+	handler := requestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		setRequestUser(r.Context(), 42)
+		encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{errors.New("database is locked")}, w)
+	}), slog.New(slog.NewJSONHandler(&logs, nil)))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tags", nil)
+	req.Header.Set("X-Request-ID", "failing")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	test.Equal(t, http.StatusInternalServerError, rec.Code)
+	test.Equal(t, "failing", rec.Header().Get("X-Request-ID"))
+	test.Contains(t, rec.Body.String(), `"requestId":"failing"`)
+
+	var record struct {
+		Level     string   `json:"level"`
+		Msg       string   `json:"msg"`
+		RequestID string   `json:"request_id"`
+		UserID    int64    `json:"user_id"`
+		Status    int      `json:"status"`
+		Errors    []string `json:"errors"`
+	}
+	test.Nil(t, json.Unmarshal([]byte(logs.String()), &record))
+	test.Equal(t, "ERROR", record.Level)
+	test.Equal(t, "server error", record.Msg)
+	test.Equal(t, "failing", record.RequestID)
+	test.Equal(t, int64(42), record.UserID)
+	test.Equal(t, http.StatusInternalServerError, record.Status)
+	test.DeepEqual(t, []string{"database is locked"}, record.Errors)
+}
@@ -1,23 +1,22 @@
 package main
 
 import (
-	"database/sql"
 	"net/http"
+	"time"
 
-	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
 )
 
-func handleGetTags(db *sql.DB) http.HandlerFunc {
+func handleGetTags(db store.Store) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-		tags, err := queries.GetAllTags(r.Context())
+		tags, err := tx.GetAllTags(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
@@ -33,6 +32,10 @@ func handleGetTags(db *sql.DB) http.HandlerFunc {
 			tags = []string{}
 		}
 
+		// Removing a tag does not leave a time behind, so the tag list has an ETag but no Last-Modified
+		if notModified(w, r, etag(tags), time.Time{}) {
+			return
+		}
 		encodeResponse(r.Context(), http.StatusOK, tagsResponseBody{Tags: tags}, w)
 	}
 }
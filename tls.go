@@ -0,0 +1,157 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// certCheckInterval is how often handshakes check whether the certificate files changed.
+const certCheckInterval = 5 * time.Second
+
+// certReloader serves the certificate of -tls-cert and -tls-key, and loads it again when either file changes,
+// so renewed certificates are picked up without a restart.
+type certReloader struct {
+	certFile, keyFile string
+	interval          time.Duration // between checks of the files
+	log               *slog.Logger
+
+	cert    atomic.Pointer[tls.Certificate]
+	checked atomic.Int64 // UnixNano of the last check
+
+	mu       sync.Mutex   // held by the check, so handshakes never wait for it
+	modTimes [2]time.Time // of certFile and keyFile when cert was loaded
+}
+
+// newCertReloader returns a reloader for the certificate in certFile and its key in keyFile, loading them once
+// so that a missing or invalid certificate fails at startup.
+func newCertReloader(certFile, keyFile string, log *slog.Logger) (*certReloader, error) {
+	r := &certReloader{certFile: certFile, keyFile: keyFile, interval: certCheckInterval, log: log}
+	modTimes, err := r.stat()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(modTimes); err != nil {
+		return nil, err
+	}
+	r.checked.Store(time.Now().UnixNano())
+	return r, nil
+}
+
+// GetCertificate implements [tls.Config.GetCertificate]. It serves the loaded certificate, and the first
+// handshake after interval checks the files, while the others go on without waiting for it.
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	now := time.Now().UnixNano()
+	if checked := r.checked.Load(); now-checked >= int64(r.interval) && r.checked.CompareAndSwap(checked, now) {
+		r.check()
+	}
+	return r.cert.Load(), nil
+}
+
+// check loads the files again when they changed since they were loaded, and keeps serving the previous
+// certificate if they are not a valid pair, such as when the certificate was written but not its key yet.
+func (r *certReloader) check() {
+	if !r.mu.TryLock() {
+		return // a slow check is still running
+	}
+	defer r.mu.Unlock()
+
+	modTimes, err := r.stat()
+	if err == nil && modTimes != r.modTimes {
+		err = r.load(modTimes)
+	}
+	if err != nil {
+		r.log.Warn("reload certificate", slog.String("cert", r.certFile), slog.String("error", err.Error()))
+	}
+}
+
+// load loads the certificate, remembering modTimes so the files are loaded again only once they change.
+func (r *certReloader) load(modTimes [2]time.Time) error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return err
+	}
+	if r.cert.Swap(&cert) != nil {
+		r.log.Info("reloaded certificate", slog.String("cert", r.certFile))
+	}
+	r.modTimes = modTimes
+	return nil
+}
+
+// stat returns the modification times of the certificate and key files.
+func (r *certReloader) stat() ([2]time.Time, error) {
+	var modTimes [2]time.Time
+	for i, path := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return modTimes, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	return modTimes, nil
+}
+
+// tlsConfig returns the TLS configuration of a listener serving the certificates of certs.
+// With clientCAs, clients must present a certificate signed by one of them, as for the debug listener.
+func tlsConfig(certs *certReloader, clientCAs *x509.CertPool) *tls.Config {
+	config := &tls.Config{GetCertificate: certs.GetCertificate, MinVersion: tls.VersionTLS12}
+	if clientCAs != nil {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = clientCAs
+	}
+	return config
+}
+
+// loadCertPool returns the pool of the PEM certificates in the file at path.
+func loadCertPool(path string) (*x509.CertPool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, errors.New("no PEM certificates found")
+	}
+	return pool, nil
+}
+
+// hsts is a middleware that tells browsers to only use HTTPS for the host for maxAge, with Strict-Transport-Security.
+// A maxAge of zero sends nothing.
+func hsts(next http.Handler, maxAge time.Duration) http.Handler {
+	if maxAge <= 0 {
+		return next
+	}
+	value := fmt.Sprintf("max-age=%d", int(maxAge/time.Second))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Strict-Transport-Security", value)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// redirectHTTPS returns an [http.Handler] that redirects every request to the same URL over HTTPS on port.
+// It answers 308 Permanent Redirect, so clients repeat the method and body of writes instead of switching to GET.
+func redirectHTTPS(port uint) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := r.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		host = strings.Trim(host, "[]")
+		if port != 443 {
+			host = net.JoinHostPort(host, strconv.FormatUint(uint64(port), 10))
+		} else if strings.Contains(host, ":") {
+			host = "[" + host + "]" // an IPv6 address
+		}
+		http.Redirect(w, r, "https://"+host+r.URL.RequestURI(), http.StatusPermanentRedirect)
+	})
+}
@@ -0,0 +1,236 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raeperd/test"
+)
+
+// testCert is a certificate for localhost issued in tests, with its key.
+type testCert struct {
+	cert            *x509.Certificate
+	key             *ecdsa.PrivateKey
+	certPEM, keyPEM []byte
+}
+
+// newTestCert issues a certificate for localhost named name, signed by parent, or a self-signed CA without parent.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	test.Nil(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	test.Nil(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	issuer, issuerKey := template, key
+	if parent == nil {
+		template.IsCA, template.BasicConstraintsValid = true, true
+		template.KeyUsage |= x509.KeyUsageCertSign
+	} else {
+		issuer, issuerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, issuer, &key.PublicKey, issuerKey)
+	test.Nil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	test.Nil(t, err)
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	test.Nil(t, err)
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// write writes the certificate and key to dir as cert.pem and key.pem, dated modTime, and returns their paths.
+func (c *testCert) write(t *testing.T, dir string, modTime time.Time) (string, string) {
+	t.Helper()
+
+	certFile, keyFile := filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	test.Nil(t, os.WriteFile(certFile, c.certPEM, 0o600))
+	test.Nil(t, os.WriteFile(keyFile, c.keyPEM, 0o600))
+	test.Nil(t, os.Chtimes(certFile, modTime, modTime))
+	test.Nil(t, os.Chtimes(keyFile, modTime, modTime))
+	return certFile, keyFile
+}
+
+// tlsClient returns a client trusting the certificates of ca, presenting clientCert when it is not nil.
+func tlsClient(t *testing.T, ca *testCert, clientCert *testCert) *http.Client {
+	t.Helper()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	config := &tls.Config{RootCAs: roots}
+	if clientCert != nil {
+		pair, err := tls.X509KeyPair(clientCert.certPEM, clientCert.keyPEM)
+		test.Nil(t, err)
+		config.Certificates = []tls.Certificate{pair}
+	}
+	transport := &http.Transport{TLSClientConfig: config, ForceAttemptHTTP2: true}
+	t.Cleanup(transport.CloseIdleConnections)
+	return &http.Client{Transport: transport, Timeout: 5 * time.Second}
+}
+
+func TestCertReloader(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	first, second := newTestCert(t, "first", ca), newTestCert(t, "second", ca)
+	now := time.Now()
+	certFile, keyFile := first.write(t, dir, now)
+
+	certs, err := newCertReloader(certFile, keyFile, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	test.Nil(t, err)
+	cert, err := certs.GetCertificate(nil)
+	test.Nil(t, err)
+	test.DeepEqual(t, first.cert.Raw, cert.Certificate[0])
+
+	// Renewed files are served from the first handshake after the check interval
+	second.write(t, dir, now.Add(time.Minute))
+	cert, err = certs.GetCertificate(nil)
+	test.Nil(t, err)
+	test.DeepEqual(t, first.cert.Raw, cert.Certificate[0])
+	certs.interval = 0
+	cert, err = certs.GetCertificate(nil)
+	test.Nil(t, err)
+	test.DeepEqual(t, second.cert.Raw, cert.Certificate[0])
+
+	// A key that doesn't match, as while the files are written one after the other, keeps the certificate served
+	test.Nil(t, os.WriteFile(keyFile, first.keyPEM, 0o600))
+	test.Nil(t, os.Chtimes(keyFile, now.Add(2*time.Minute), now.Add(2*time.Minute)))
+	cert, err = certs.GetCertificate(nil)
+	test.Nil(t, err)
+	test.DeepEqual(t, second.cert.Raw, cert.Certificate[0])
+
+	_, err = newCertReloader(filepath.Join(dir, "missing.pem"), keyFile, slog.Default())
+	test.NotNil(t, err)
+}
+
+func TestRedirectHTTPS(t *testing.T) {
+	t.Parallel()
+
+	for _, tc := range []struct {
+		port           uint
+		host, location string
+	}{
+		{443, "example.com", "https://example.com/api/tags?limit=1"},
+		{443, "example.com:80", "https://example.com/api/tags?limit=1"},
+		{8443, "example.com:8080", "https://example.com:8443/api/tags?limit=1"},
+		{443, "[::1]:80", "https://[::1]/api/tags?limit=1"},
+		{8443, "[::1]", "https://[::1]:8443/api/tags?limit=1"},
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/api/tags?limit=1", nil)
+		req.Host = tc.host
+		rec := httptest.NewRecorder()
+		redirectHTTPS(tc.port).ServeHTTP(rec, req)
+		test.Equal(t, http.StatusPermanentRedirect, rec.Code)
+		test.Equal(t, tc.location, rec.Header().Get("Location"))
+	}
+}
+
+func TestRun_TLS(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	ca := newTestCert(t, "ca", nil)
+	server, client := newTestCert(t, "server", ca), newTestCert(t, "client", ca)
+	certFile, keyFile := server.write(t, dir, time.Now())
+	caFile := filepath.Join(dir, "ca.pem")
+	test.Nil(t, os.WriteFile(caFile, ca.certPEM, 0o600))
+
+	port, debugAddr, redirectAddr := freePort(t), "localhost:"+freePort(t), "localhost:"+freePort(t)
+	api := "https://localhost:" + port
+	startServer(t, tlsClient(t, ca, nil), api+"/health", "-port", port, "-tls-cert", certFile, "-tls-key", keyFile,
+		"-hsts-max-age", "1h", "-debug-addr", debugAddr, "-debug-client-ca", caFile, "-http-redirect-addr", redirectAddr)
+
+	// The API speaks HTTP/2 over TLS, and tells browsers to stick to HTTPS
+	res, err := tlsClient(t, ca, nil).Get(api + "/health")
+	test.Nil(t, err)
+	_ = res.Body.Close()
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	test.Equal(t, 2, res.ProtoMajor)
+	test.Equal(t, "max-age=3600", res.Header.Get("Strict-Transport-Security"))
+
+	// The debug listener only lets in clients with a certificate of -debug-client-ca
+	_, err = tlsClient(t, ca, nil).Get("https://" + debugAddr + "/metrics")
+	test.NotNil(t, err)
+	res, err = tlsClient(t, ca, client).Get("https://" + debugAddr + "/metrics")
+	test.Nil(t, err)
+	_ = res.Body.Close()
+	test.Equal(t, http.StatusOK, res.StatusCode)
+
+	// Plain HTTP is redirected to the API
+	redirects := &http.Client{
+		Transport:     &http.Transport{},
+		CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+	}
+	res, err = redirects.Get("http://" + redirectAddr + "/api/tags")
+	test.Nil(t, err)
+	_ = res.Body.Close()
+	test.Equal(t, http.StatusPermanentRedirect, res.StatusCode)
+	test.Equal(t, api+"/api/tags", res.Header.Get("Location"))
+}
+
+func TestRun_H2C(t *testing.T) {
+	t.Parallel()
+
+	protocols := new(http.Protocols)
+	protocols.SetUnencryptedHTTP2(true)
+	h2c := &http.Client{Transport: &http.Transport{Protocols: protocols}, Timeout: 5 * time.Second}
+
+	port := freePort(t)
+	api := "http://localhost:" + port
+	startServer(t, h2c, api+"/health", "-port", port, "-h2c")
+
+	res, err := h2c.Get(api + "/health")
+	test.Nil(t, err)
+	_ = res.Body.Close()
+	test.Equal(t, http.StatusOK, res.StatusCode)
+	test.Equal(t, 2, res.ProtoMajor)
+	test.Equal(t, "", res.Header.Get("Strict-Transport-Security"))
+}
+
+func TestRun_TLSConfig(t *testing.T) {
+	t.Parallel()
+
+	for name, args := range map[string][]string{
+		"cert without key": {"-tls-cert", "cert.pem"},
+		"missing cert":     {"-tls-cert", "missing.pem", "-tls-key", "missing.pem"},
+		"redirect":         {"-http-redirect-addr", ":80"},
+		"client CA":        {"-debug-client-ca", "ca.pem", "-debug-addr", "localhost:6060"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			err := run(t.Context(), io.Discard, append([]string{"test", "-jwt-secret", "test-secret"}, args...), "vtest")
+			test.NotNil(t, err)
+			test.Contains(t, err.Error(), args[0])
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/raeperd/realworld.go/internal/store"
+	"github.com/raeperd/realworld.go/internal/tracing"
+)
+
+// newTracer returns the tracer of the exporter named exporter: none, stdout to write spans to w,
+// or otlp to send them to the OpenTelemetry collector at otlpEndpoint. It returns nil for none.
+func newTracer(exporter, otlpEndpoint string, w io.Writer, log *slog.Logger) (*tracing.Tracer, error) {
+	switch exporter {
+	case "none":
+		return nil, nil
+	case "stdout":
+		return tracing.NewTracer(tracing.NewStdoutExporter(w), log), nil
+	case "otlp":
+		client := &http.Client{Timeout: 10 * time.Second}
+		return tracing.NewTracer(tracing.NewOTLPExporter(otlpEndpoint, "realworld", client), log), nil
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q, want none, stdout or otlp", exporter)
+	}
+}
+
+// traceRequests is a middleware that traces each request in a server span, continuing the trace of its
+// traceparent header if it has one. The span is named after the pattern of the route in [route] once served,
+// and the handlers, their queries and their logs add to it through the context of the request.
+func traceRequests(next http.Handler, tracer *tracing.Tracer) http.Handler {
+	if tracer == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		if parent, ok := tracing.Extract(r.Header); ok {
+			ctx = tracing.ContextWithRemoteParent(ctx, parent)
+		}
+		ctx, span := tracer.Start(ctx, r.Method, tracing.KindServer,
+			tracing.String("http.request.method", r.Method),
+			tracing.String("url.path", r.URL.Path))
+		defer span.End()
+		wr := responseRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(&wr, r.WithContext(ctx))
+
+		if pattern := routePattern(r); pattern != "" {
+			_, path, ok := strings.Cut(pattern, " ")
+			if !ok {
+				path = pattern
+			}
+			span.SetName(r.Method + " " + path)
+			span.SetAttributes(tracing.String("http.route", path))
+		}
+		status := wr.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(tracing.Int("http.response.status_code", status))
+		if status >= 500 {
+			span.SetError(fmt.Errorf("%d %s", status, http.StatusText(status)))
+		}
+	})
+}
+
+// traceQuery returns the [store.QueryHook] that records each query of the database system as a span
+// of the request that ran it, named after the query in query.sql.
+func traceQuery(system string) store.QueryHook {
+	return func(ctx context.Context, name string, start time.Time, err error) {
+		tracing.Record(ctx, name, tracing.KindClient, start, err,
+			tracing.String("db.system.name", system),
+			tracing.String("db.operation.name", name))
+	}
+}
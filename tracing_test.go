@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/raeperd/realworld.go/internal/tracing"
+	"github.com/raeperd/test"
+)
+
+func TestTraceRequests(t *testing.T) {
+	t.Parallel()
+
+	db, readDB, err := openStore(t.Context(), "sqlite", filepath.Join(t.TempDir(), "tracing.db"))
+	test.Nil(t, err)
+	t.Cleanup(func() { _ = db.Close(); _ = readDB.Close() })
+	var logs, spans bytes.Buffer
+	log := slog.New(tracing.LogHandler(slog.NewJSONHandler(&logs, nil)))
+	tracer := tracing.NewTracer(tracing.NewStdoutExporter(&spans), log)
+	server := httptest.NewServer(route(log, "vtest", routeDeps{db: db, readDB: readDB, jwtSecret: "test-secret", tracer: tracer}))
+	t.Cleanup(server.Close)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, server.URL+"/api/articles?count=true", nil)
+	test.Nil(t, err)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	res, err := server.Client().Do(req)
+	test.Nil(t, err)
+	_ = res.Body.Close()
+	test.Equal(t, http.StatusOK, res.StatusCode)
+
+	test.Nil(t, tracer.Flush(t.Context()))
+	byName := make(map[string]tracing.StdoutSpan)
+	dec := json.NewDecoder(&spans)
+	for dec.More() {
+		var span tracing.StdoutSpan
+		test.Nil(t, dec.Decode(&span))
+		test.Equal(t, traceID, span.TraceID)
+		byName[span.Name] = span
+	}
+
+	request := byName["GET /api/articles"]
+	test.Equal(t, "server", request.Kind)
+	test.Equal(t, "00f067aa0ba902b7", request.ParentSpanID)
+	test.Equal[any](t, "/api/articles", request.Attributes["http.route"])
+	test.Equal[any](t, float64(http.StatusOK), request.Attributes["http.response.status_code"])
+
+	for _, name := range []string{"ListArticles", "CountArticles", "enrich articles", "encode response"} {
+		test.Equal(t, request.SpanID, byName[name].ParentSpanID)
+	}
+	test.Equal(t, "client", byName["CountArticles"].Kind)
+	test.Equal[any](t, "sqlite", byName["CountArticles"].Attributes["db.system.name"])
+
+	// The access log of the request is logged within its span
+	var accessed map[string]any
+	for line := range strings.Lines(logs.String()) {
+		if strings.Contains(line, `"msg":"accessed"`) {
+			test.Nil(t, json.Unmarshal([]byte(line), &accessed))
+		}
+	}
+	test.Equal[any](t, traceID, accessed["trace_id"])
+	test.Equal[any](t, request.SpanID, accessed["span_id"])
+}
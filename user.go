@@ -8,9 +8,15 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"time"
 
 	"github.com/raeperd/realworld.go/internal/auth"
-	"github.com/raeperd/realworld.go/internal/sqlite"
+	"github.com/raeperd/realworld.go/internal/store"
+	"github.com/raeperd/realworld.go/internal/tracing"
+	"github.com/raeperd/realworld.go/internal/validate"
 )
 
 // contextKey is a type for context keys to avoid collisions
@@ -18,7 +24,7 @@ type contextKey string
 
 const userIDKey contextKey = "userID"
 
-func handlePostUsers(db *sql.DB, jwtSecret string) func(w http.ResponseWriter, r *http.Request) {
+func handlePostUsers(db store.Store, jwtSecret string) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var request userPostRequestBody
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -32,15 +38,14 @@ func handlePostUsers(db *sql.DB, jwtSecret string) func(w http.ResponseWriter, r
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-		user, err := queries.GetUserByEmail(r.Context(), request.User.Email)
+		user, err := tx.GetUserByEmail(r.Context(), request.User.Email)
 		if err == nil {
 			// User found, return conflict
 			encodeErrorResponse(r.Context(), http.StatusConflict, []error{fmt.Errorf("user with email %s already exists", request.User.Email)}, w)
@@ -53,7 +58,7 @@ func handlePostUsers(db *sql.DB, jwtSecret string) func(w http.ResponseWriter, r
 		}
 		// User not found (sql.ErrNoRows), proceed to create
 
-		user, err = queries.CreateUser(r.Context(), sqlite.CreateUserParams{
+		user, err = tx.CreateUser(r.Context(), store.CreateUserParams{
 			Username: request.User.Username,
 			Email:    request.User.Email,
 			Password: request.User.Password,
@@ -66,6 +71,7 @@ func handlePostUsers(db *sql.DB, jwtSecret string) func(w http.ResponseWriter, r
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		usersRegistered.Inc()
 
 		// Generate JWT token
 		token, err := auth.GenerateToken(user.ID, user.Username, jwtSecret)
@@ -74,17 +80,19 @@ func handlePostUsers(db *sql.DB, jwtSecret string) func(w http.ResponseWriter, r
 			return
 		}
 
-		encodeResponse(r.Context(), http.StatusCreated, userPostResponseBody{
-			Email:    user.Email,
-			Token:    token,
-			Username: user.Username,
-			Bio:      user.Bio.String,
-			Image:    user.Image.String,
+		encodeResponse(r.Context(), http.StatusCreated, userResponseWrapper{
+			User: userPostResponseBody{
+				Email:    user.Email,
+				Token:    token,
+				Username: user.Username,
+				Bio:      user.Bio.String,
+				Image:    user.Image.String,
+			},
 		}, w)
 	}
 }
 
-func handlePostUsersLogin(db *sql.DB, jwtSecret string) func(w http.ResponseWriter, r *http.Request) {
+func handlePostUsersLogin(db store.Store, jwtSecret string, trustedProxies []netip.Prefix) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		var request userLoginRequestBody
 		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
@@ -98,29 +106,82 @@ func handlePostUsersLogin(db *sql.DB, jwtSecret string) func(w http.ResponseWrit
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-		user, err := queries.GetUserByEmail(r.Context(), request.User.Email)
+		// Delay attempts after failed ones, whether or not there is a user with the email, see [loginDelay]
+		failures, err := tx.ListLoginFailures(r.Context(), store.ListLoginFailuresParams{
+			Email:         request.User.Email,
+			WindowSeconds: int64(loginFailureWindow / time.Second),
+			Limit:         lockoutLoginFailures,
+		})
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+		if retry := loginRetryAfter(failures, time.Now()); retry > 0 {
+			loginsThrottled.Inc()
+			w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(retry)))
+			err := fmt.Errorf("too many failed logins, retry in %d seconds", ceilSeconds(retry))
+			if len(failures) >= lockoutLoginFailures {
+				err = fmt.Errorf("account is locked after too many failed logins, retry in %d seconds", ceilSeconds(retry))
+			}
+			encodeErrorResponse(r.Context(), http.StatusTooManyRequests, []error{err}, w)
+			return
+		}
+
+		event := store.CreateLoginEventParams{
+			Email:     request.User.Email,
+			Ip:        clientIP(r, trustedProxies),
+			UserAgent: r.UserAgent(),
+		}
+		// fail records a failed login, committed so that it delays the next attempts, and responds with reason
+		fail := func(reason error) {
+			loginsFailed.Inc()
+			if err := tx.CreateLoginEvent(r.Context(), event); err != nil {
+				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+				return
+			}
+			// Every failure is recorded and throttled, and the oldest logins to emails of no user make room for it
+			if !event.UserID.Valid {
+				if err := tx.TrimUnknownLoginEvents(r.Context(), maxUnknownLoginEvents); err != nil {
+					encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+					return
+				}
+			}
+			if err := tx.Commit(); err != nil {
+				encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+				return
+			}
+			encodeErrorResponse(r.Context(), http.StatusUnauthorized, []error{reason}, w)
+		}
+
+		user, err := tx.GetUserByEmail(r.Context(), request.User.Email)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				// User not found - return 401 with generic message
-				encodeErrorResponse(r.Context(), http.StatusUnauthorized, []error{errors.New("invalid credentials")}, w)
+				fail(errors.New("invalid credentials"))
 				return
 			}
 			// Database error
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
+		event.UserID = sql.NullInt64{Int64: user.ID, Valid: true}
 
 		// Verify password (plain text comparison for now)
 		if user.Password != request.User.Password {
-			encodeErrorResponse(r.Context(), http.StatusUnauthorized, []error{errors.New("invalid credentials")}, w)
+			fail(errors.New("invalid credentials"))
+			return
+		}
+
+		// Disabled by an operator with the "user disable" admin command
+		if user.DisabledAt.Valid {
+			fail(errors.New("user is disabled"))
 			return
 		}
 
@@ -131,17 +192,24 @@ func handlePostUsersLogin(db *sql.DB, jwtSecret string) func(w http.ResponseWrit
 			return
 		}
 
+		event.Success = true
+		if err := tx.CreateLoginEvent(r.Context(), event); err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
 		if err := tx.Commit(); err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 
-		encodeResponse(r.Context(), http.StatusOK, userPostResponseBody{
-			Email:    user.Email,
-			Token:    token,
-			Username: user.Username,
-			Bio:      user.Bio.String,
-			Image:    user.Image.String,
+		encodeResponse(r.Context(), http.StatusOK, userResponseWrapper{
+			User: userPostResponseBody{
+				Email:    user.Email,
+				Token:    token,
+				Username: user.Username,
+				Bio:      user.Bio.String,
+				Image:    user.Image.String,
+			},
 		}, w)
 	}
 }
@@ -153,24 +221,31 @@ func encodeErrorResponse(ctx context.Context, status int, errs []error, w http.R
 		}{
 			Body: make([]string, len(errs)),
 		},
+		RequestID: requestIDFrom(ctx),
 	}
 	for i, err := range errs {
 		errResp.Errors.Body[i] = err.Error()
 	}
+	if status >= http.StatusInternalServerError {
+		requestLogger(ctx, slog.Default()).ErrorContext(ctx, "server error",
+			slog.Int("status", status), slog.Any("errors", errResp.Errors.Body))
+	}
 
 	encodeResponse(ctx, status, errResp, w)
 }
 
 func encodeResponse[T responseBody](ctx context.Context, status int, body T, w http.ResponseWriter) {
+	ctx, span := tracing.Start(ctx, "encode response")
+	defer span.End()
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if err := json.NewEncoder(w).Encode(body); err != nil {
-		slog.ErrorContext(ctx, "failed to encode response body", slog.String("error", err.Error()))
+		requestLogger(ctx, slog.Default()).ErrorContext(ctx, "failed to encode response body", slog.String("error", err.Error()))
 	}
 }
 
 type responseBody interface {
-	userPostResponseBody | errorResponseBody | profileGetResponseWrapper | tagsResponseBody | articleResponseBody | articlesResponseBody | commentResponseBody | commentsResponseBody
+	userResponseWrapper | errorResponseBody | profileGetResponseWrapper | tagsResponseBody | articleResponseBody | articlesResponseBody | commentResponseBody | commentsResponseBody | backupResponseBody | loginsResponseBody
 }
 
 type userPostRequestBody struct {
@@ -182,17 +257,39 @@ type userPostRequestBody struct {
 }
 
 func (u userPostRequestBody) Validate() []error {
-	var errs []error
-	if u.User.Email == "" {
-		errs = append(errs, errors.New("email is required"))
-	}
-	if u.User.Password == "" {
-		errs = append(errs, errors.New("password is required"))
-	}
-	if u.User.Username == "" {
-		errs = append(errs, errors.New("username is required"))
+	return validate.Errors(
+		validate.Field("email", u.User.Email, emailRules...),
+		validate.Field("password", u.User.Password, passwordRules...),
+		validate.Field("username", u.User.Username, usernameRules...),
+	)
+}
+
+// Limits for user fields, also documented in api/openapi.yaml.
+const (
+	minUsernameLength = 3
+	maxUsernameLength = 64
+	maxEmailLength    = 254
+	minPasswordLength = 8
+	maxPasswordLength = 72
+	maxBioLength      = 1000
+	maxImageLength    = 2048
+)
+
+var (
+	usernamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+	usernameRules = []validate.Rule{
+		validate.Required,
+		validate.MinLength(minUsernameLength),
+		validate.MaxLength(maxUsernameLength),
+		validate.Pattern(usernamePattern, "may only contain letters, digits, '_', '.' and '-'"),
 	}
-	return errs
+	emailRules    = []validate.Rule{validate.Required, validate.MaxLength(maxEmailLength), validate.Email}
+	passwordRules = []validate.Rule{validate.Required, validate.MinLength(minPasswordLength), validate.MaxLength(maxPasswordLength)}
+)
+
+type userResponseWrapper struct {
+	User userPostResponseBody `json:"user"`
 }
 
 type userPostResponseBody struct {
@@ -207,6 +304,7 @@ type errorResponseBody struct {
 	Errors struct {
 		Body []string `json:"body"`
 	} `json:"errors"`
+	RequestID string `json:"requestId,omitempty"`
 }
 
 type userLoginRequestBody struct {
@@ -216,18 +314,15 @@ type userLoginRequestBody struct {
 	} `json:"user"`
 }
 
+// Validate only checks presence, so that accounts created under older policies can still log in.
 func (u userLoginRequestBody) Validate() []error {
-	var errs []error
-	if u.User.Email == "" {
-		errs = append(errs, errors.New("email is required"))
-	}
-	if u.User.Password == "" {
-		errs = append(errs, errors.New("password is required"))
-	}
-	return errs
+	return validate.Errors(
+		validate.Field("email", u.User.Email, validate.Required),
+		validate.Field("password", u.User.Password, validate.Required),
+	)
 }
 
-func handleGetUser(db *sql.DB, jwtSecret string) http.HandlerFunc {
+func handleGetUser(db store.Store, jwtSecret string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Extract user ID from context (set by authenticate middleware)
 		userID, ok := r.Context().Value(userIDKey).(int64)
@@ -236,15 +331,14 @@ func handleGetUser(db *sql.DB, jwtSecret string) http.HandlerFunc {
 			return
 		}
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-		user, err := queries.GetUserByID(r.Context(), userID)
+		user, err := tx.GetUserByID(r.Context(), userID)
 		if err != nil {
 			if errors.Is(err, sql.ErrNoRows) {
 				encodeErrorResponse(r.Context(), http.StatusUnauthorized, []error{errors.New("user not found")}, w)
@@ -266,17 +360,19 @@ func handleGetUser(db *sql.DB, jwtSecret string) http.HandlerFunc {
 			return
 		}
 
-		encodeResponse(r.Context(), http.StatusOK, userPostResponseBody{
-			Email:    user.Email,
-			Token:    token,
-			Username: user.Username,
-			Bio:      user.Bio.String,
-			Image:    user.Image.String,
+		encodeResponse(r.Context(), http.StatusOK, userResponseWrapper{
+			User: userPostResponseBody{
+				Email:    user.Email,
+				Token:    token,
+				Username: user.Username,
+				Bio:      user.Bio.String,
+				Image:    user.Image.String,
+			},
 		}, w)
 	}
 }
 
-func handlePutUser(db *sql.DB, jwtSecret string) http.HandlerFunc {
+func handlePutUser(db store.Store, jwtSecret string, responses *responseCache) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Extract user ID from context (set by authenticate middleware)
 		userID, ok := r.Context().Value(userIDKey).(int64)
@@ -292,15 +388,26 @@ func handlePutUser(db *sql.DB, jwtSecret string) http.HandlerFunc {
 		}
 		defer func() { _ = r.Body.Close() }()
 
-		tx, err := db.BeginTx(r.Context(), nil)
+		if errs := request.Validate(); len(errs) > 0 {
+			encodeErrorResponse(r.Context(), http.StatusUnprocessableEntity, errs, w)
+			return
+		}
+
+		tx, err := db.BeginTx(r.Context())
 		if err != nil {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
 		defer func() { _ = tx.Rollback() }()
 
-		queries := sqlite.New(tx)
-		user, err := queries.UpdateUser(r.Context(), sqlite.UpdateUserParams{
+		// Get the username before the update, which the cached responses showing the user were tagged with
+		previous, err := tx.GetUserByID(r.Context(), userID)
+		if err != nil {
+			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
+			return
+		}
+
+		user, err := tx.UpdateUser(r.Context(), store.UpdateUserParams{
 			ID:       userID,
 			Email:    sql.NullString{String: request.User.Email, Valid: request.User.Email != ""},
 			Username: sql.NullString{String: request.User.Username, Valid: request.User.Username != ""},
@@ -324,13 +431,16 @@ func handlePutUser(db *sql.DB, jwtSecret string) http.HandlerFunc {
 			encodeErrorResponse(r.Context(), http.StatusInternalServerError, []error{err}, w)
 			return
 		}
-
-		encodeResponse(r.Context(), http.StatusOK, userPostResponseBody{
-			Email:    user.Email,
-			Token:    token,
-			Username: user.Username,
-			Bio:      user.Bio.String,
-			Image:    user.Image.String,
+		responses.Invalidate(userTag(previous.Username))
+
+		encodeResponse(r.Context(), http.StatusOK, userResponseWrapper{
+			User: userPostResponseBody{
+				Email:    user.Email,
+				Token:    token,
+				Username: user.Username,
+				Bio:      user.Bio.String,
+				Image:    user.Image.String,
+			},
 		}, w)
 	}
 }
@@ -344,3 +454,14 @@ type userPutRequestBody struct {
 		Image    string `json:"image,omitempty"`
 	} `json:"user"`
 }
+
+// Validate checks only the fields that are present, since empty fields are left unchanged.
+func (u userPutRequestBody) Validate() []error {
+	return validate.Errors(
+		validate.OptionalField("email", u.User.Email, emailRules...),
+		validate.OptionalField("username", u.User.Username, usernameRules...),
+		validate.OptionalField("password", u.User.Password, passwordRules...),
+		validate.OptionalField("bio", u.User.Bio, validate.MaxLength(maxBioLength)),
+		validate.OptionalField("image", u.User.Image, validate.MaxLength(maxImageLength), validate.URL),
+	)
+}
@@ -1,8 +1,6 @@
 package main
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -10,13 +8,14 @@ import (
 
 	"github.com/raeperd/test"
 
+	"github.com/raeperd/realworld.go/client"
 	"github.com/raeperd/realworld.go/internal/auth"
 )
 
 func TestPostUsers_Validation(t *testing.T) {
 	t.Parallel()
 
-	testcases := map[string]UserPostRequestBody{
+	testcases := map[string]client.NewUser{
 		"username required": {
 			Username: "",
 			Email:    "test@test.com",
@@ -32,16 +31,34 @@ func TestPostUsers_Validation(t *testing.T) {
 			Email:    "test@test.com",
 			Password: "",
 		},
+		"email invalid": {
+			Username: "test",
+			Email:    "not-an-email",
+			Password: "testpass123",
+		},
+		"password too short": {
+			Username: "test",
+			Email:    "test@test.com",
+			Password: "short",
+		},
+		"username too short": {
+			Username: "ab",
+			Email:    "test@test.com",
+			Password: "testpass123",
+		},
+		"username invalid characters": {
+			Username: "white space",
+			Email:    "test@test.com",
+			Password: "testpass123",
+		},
 	}
 
 	for name, tc := range testcases {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			res := httpPostUsers(t, tc)
-			test.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
-
-			t.Cleanup(func() { _ = res.Body.Close() })
+			_, err := newClient().Register(t.Context(), tc)
+			test.Equal(t, http.StatusUnprocessableEntity, client.StatusCode(err))
 		})
 	}
 }
@@ -51,23 +68,18 @@ func TestPostUsers_CreateUser(t *testing.T) {
 
 	// Generate unique username/email per test run to avoid conflicts
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	req := UserPostRequestBody{
+	req := client.NewUser{
 		Username: "create_test_user_" + unique,
 		Email:    fmt.Sprintf("create_test_%s@example.com", unique),
 		Password: "testpass",
 	}
-	res := httpPostUsers(t, req)
-	test.Equal(t, http.StatusCreated, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	var response UserResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
+	response, err := newClient().Register(t.Context(), req)
+	test.Nil(t, err)
 	test.Equal(t, req.Username, response.Username)
 	test.Equal(t, req.Email, response.Email)
 
-	res = httpPostUsers(t, req) // return conflict when user already exists
-	test.Equal(t, http.StatusConflict, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err = newClient().Register(t.Context(), req) // return conflict when user already exists
+	test.Equal(t, http.StatusConflict, client.StatusCode(err))
 }
 
 func TestPostUsers_ReturnsValidJWT(t *testing.T) {
@@ -75,19 +87,15 @@ func TestPostUsers_ReturnsValidJWT(t *testing.T) {
 
 	// Given - generate unique username/email per test run to avoid conflicts
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
-	req := UserPostRequestBody{
+	req := client.NewUser{
 		Username: "jwt_test_user_" + unique,
 		Email:    fmt.Sprintf("jwt_test_%s@example.org", unique),
 		Password: "testpass",
 	}
 
 	// When
-	res := httpPostUsers(t, req)
-	test.Equal(t, http.StatusCreated, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
-
-	var response UserResponseBody
-	test.Nil(t, json.NewDecoder(res.Body).Decode(&response))
+	response, err := newClient().Register(t.Context(), req)
+	test.Nil(t, err)
 
 	// Then - token should not be the placeholder
 	test.NotEqual(t, "token", response.Token)
@@ -102,34 +110,20 @@ func TestPostUsers_ReturnsValidJWT(t *testing.T) {
 	test.True(t, claims.UserID > 0)
 }
 
-func httpPostUsers(t *testing.T, request UserPostRequestBody) *http.Response {
+// registerUser registers a user whose username and email start with prefix and end with a unique suffix.
+// It returns a client authenticated as the new user.
+func registerUser(t *testing.T, prefix string) (*client.Client, client.User) {
 	t.Helper()
 
-	body, err := json.Marshal(UserWrapper[UserPostRequestBody]{User: request})
-	test.Nil(t, err)
-
-	res, err := http.Post(endpoint+"/api/users", "application/json", bytes.NewBuffer(body))
+	unique := fmt.Sprintf("%d", time.Now().UnixNano())
+	c := newClient()
+	user, err := c.Register(t.Context(), client.NewUser{
+		Username: prefix + "_" + unique,
+		Email:    fmt.Sprintf("%s_%s@example.com", prefix, unique),
+		Password: "testpass123",
+	})
 	test.Nil(t, err)
-
-	return res
-}
-
-type UserWrapper[T UserPostRequestBody | UserPutRequestBody | UserResponseBody] struct {
-	User T `json:"user"`
-}
-
-type UserPostRequestBody struct {
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
-
-type UserResponseBody struct {
-	Email    string `json:"email"`
-	Token    string `json:"token"`
-	Username string `json:"username"`
-	Bio      string `json:"bio"`
-	Image    string `json:"image"`
+	return c, user
 }
 
 func TestPostUsersLogin_Validation(t *testing.T) {
@@ -153,10 +147,8 @@ func TestPostUsersLogin_Validation(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			t.Parallel()
 
-			res := httpPostUsersLogin(t, tc.email, tc.password)
-			test.Equal(t, http.StatusUnprocessableEntity, res.StatusCode)
-
-			t.Cleanup(func() { _ = res.Body.Close() })
+			_, err := newClient().Login(t.Context(), tc.email, tc.password)
+			test.Equal(t, http.StatusUnprocessableEntity, client.StatusCode(err))
 		})
 	}
 }
@@ -168,9 +160,8 @@ func TestPostUsersLogin_UserNotFound(t *testing.T) {
 	unique := fmt.Sprintf("%d", time.Now().UnixNano())
 	email := fmt.Sprintf("nonexistent_%s@example.com", unique)
 
-	res := httpPostUsersLogin(t, email, "anypassword")
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient().Login(t.Context(), email, "anypassword")
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestPostUsersLogin_WrongPassword(t *testing.T) {
@@ -181,20 +172,17 @@ func TestPostUsersLogin_WrongPassword(t *testing.T) {
 	email := fmt.Sprintf("wrongpw_test_%s@example.com", unique)
 	correctPassword := "correctpass123"
 
-	regReq := UserPostRequestBody{
+	_, err := newClient().Register(t.Context(), client.NewUser{
 		Username: "wrongpw_user_" + unique,
 		Email:    email,
 		Password: correctPassword,
-	}
-	regRes := httpPostUsers(t, regReq)
-	test.Equal(t, http.StatusCreated, regRes.StatusCode)
-	t.Cleanup(func() { _ = regRes.Body.Close() })
+	})
+	test.Nil(t, err)
 
 	// Test: Login with wrong password
 	wrongPassword := "wrongpassword"
-	loginRes := httpPostUsersLogin(t, email, wrongPassword)
-	test.Equal(t, http.StatusUnauthorized, loginRes.StatusCode)
-	t.Cleanup(func() { _ = loginRes.Body.Close() })
+	_, err = newClient().Login(t.Context(), email, wrongPassword)
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestPostUsersLogin_Success(t *testing.T) {
@@ -205,26 +193,24 @@ func TestPostUsersLogin_Success(t *testing.T) {
 	email := fmt.Sprintf("login_test_%s@example.com", unique)
 	password := "testpass123"
 
-	regReq := UserPostRequestBody{
+	regReq := client.NewUser{
 		Username: "login_user_" + unique,
 		Email:    email,
 		Password: password,
 	}
-	regRes := httpPostUsers(t, regReq)
-	test.Equal(t, http.StatusCreated, regRes.StatusCode)
-	t.Cleanup(func() { _ = regRes.Body.Close() })
+	_, err := newClient().Register(t.Context(), regReq)
+	test.Nil(t, err)
 
 	// Test: Login with correct credentials
-	loginRes := httpPostUsersLogin(t, email, password)
-	test.Equal(t, http.StatusOK, loginRes.StatusCode)
-	t.Cleanup(func() { _ = loginRes.Body.Close() })
+	c := newClient()
+	response, err := c.Login(t.Context(), email, password)
+	test.Nil(t, err)
 
 	// Verify response
-	var response UserResponseBody
-	test.Nil(t, json.NewDecoder(loginRes.Body).Decode(&response))
 	test.Equal(t, email, response.Email)
 	test.Equal(t, regReq.Username, response.Username)
 	test.NotEqual(t, "", response.Token)
+	test.Equal(t, response.Token, c.Token())
 }
 
 func TestPostUsersLogin_ReturnsValidJWT(t *testing.T) {
@@ -235,22 +221,17 @@ func TestPostUsersLogin_ReturnsValidJWT(t *testing.T) {
 	email := fmt.Sprintf("jwt_login_test_%s@example.com", unique)
 	password := "testpass123"
 
-	regReq := UserPostRequestBody{
+	regReq := client.NewUser{
 		Username: "jwt_login_user_" + unique,
 		Email:    email,
 		Password: password,
 	}
-	regRes := httpPostUsers(t, regReq)
-	test.Equal(t, http.StatusCreated, regRes.StatusCode)
-	t.Cleanup(func() { _ = regRes.Body.Close() })
+	_, err := newClient().Register(t.Context(), regReq)
+	test.Nil(t, err)
 
 	// Test: Login and verify JWT
-	loginRes := httpPostUsersLogin(t, email, password)
-	test.Equal(t, http.StatusOK, loginRes.StatusCode)
-	t.Cleanup(func() { _ = loginRes.Body.Close() })
-
-	var response UserResponseBody
-	test.Nil(t, json.NewDecoder(loginRes.Body).Decode(&response))
+	response, err := newClient().Login(t.Context(), email, password)
+	test.Nil(t, err)
 
 	// Verify JWT token is valid and contains correct data
 	claims, err := auth.ParseToken(response.Token, "test-secret")
@@ -259,27 +240,6 @@ func TestPostUsersLogin_ReturnsValidJWT(t *testing.T) {
 	test.True(t, claims.UserID > 0)
 }
 
-func httpPostUsersLogin(t *testing.T, email, password string) *http.Response {
-	t.Helper()
-
-	requestBody := struct {
-		User struct {
-			Email    string `json:"email"`
-			Password string `json:"password"`
-		} `json:"user"`
-	}{}
-	requestBody.User.Email = email
-	requestBody.User.Password = password
-
-	body, err := json.Marshal(requestBody)
-	test.Nil(t, err)
-
-	res, err := http.Post(endpoint+"/api/users/login", "application/json", bytes.NewBuffer(body))
-	test.Nil(t, err)
-
-	return res
-}
-
 func TestGetUser_Success(t *testing.T) {
 	t.Parallel()
 
@@ -289,28 +249,20 @@ func TestGetUser_Success(t *testing.T) {
 	password := "testpass123"
 	username := "getuser_user_" + unique
 
-	regReq := UserPostRequestBody{
+	c := newClient()
+	regResponse, err := c.Register(t.Context(), client.NewUser{
 		Username: username,
 		Email:    email,
 		Password: password,
-	}
-	regRes := httpPostUsers(t, regReq)
-	test.Equal(t, http.StatusCreated, regRes.StatusCode)
-	t.Cleanup(func() { _ = regRes.Body.Close() })
-
-	// Get token from registration response
-	var regResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(regRes.Body).Decode(&regResponse))
+	})
+	test.Nil(t, err)
 	token := regResponse.Token
 
 	// Test: GET /api/user with valid token
-	getUserRes := httpGetUser(t, token)
-	test.Equal(t, http.StatusOK, getUserRes.StatusCode)
-	t.Cleanup(func() { _ = getUserRes.Body.Close() })
+	getUserResponse, err := c.GetCurrentUser(t.Context())
+	test.Nil(t, err)
 
 	// Verify response contains correct user data
-	var getUserResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(getUserRes.Body).Decode(&getUserResponse))
 	test.Equal(t, email, getUserResponse.Email)
 	test.Equal(t, username, getUserResponse.Username)
 	test.Equal(t, token, getUserResponse.Token)
@@ -318,41 +270,21 @@ func TestGetUser_Success(t *testing.T) {
 	test.Equal(t, "", getUserResponse.Image)
 }
 
-func httpGetUser(t *testing.T, token string) *http.Response {
-	t.Helper()
-
-	req, err := http.NewRequest(http.MethodGet, endpoint+"/api/user", nil)
-	test.Nil(t, err)
-
-	req.Header.Set("Authorization", "Token "+token)
-
-	res, err := http.DefaultClient.Do(req)
-	test.Nil(t, err)
-
-	return res
-}
-
 func TestGetUser_InvalidToken(t *testing.T) {
 	t.Parallel()
 
 	// Test with malformed/invalid JWT token
 	invalidToken := "invalid.jwt.token"
-	res := httpGetUser(t, invalidToken)
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient(client.WithToken(invalidToken)).GetCurrentUser(t.Context())
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestGetUser_MissingToken(t *testing.T) {
 	t.Parallel()
 
 	// Test without Authorization header
-	req, err := http.NewRequest(http.MethodGet, endpoint+"/api/user", nil)
-	test.Nil(t, err)
-
-	res, err := http.DefaultClient.Do(req)
-	test.Nil(t, err)
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	_, err := newClient().GetCurrentUser(t.Context())
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestPutUser_Success(t *testing.T) {
@@ -364,33 +296,23 @@ func TestPutUser_Success(t *testing.T) {
 	password := "testpass123"
 	username := "putuser_user_" + unique
 
-	regReq := UserPostRequestBody{
+	c := newClient()
+	_, err := c.Register(t.Context(), client.NewUser{
 		Username: username,
 		Email:    email,
 		Password: password,
-	}
-	regRes := httpPostUsers(t, regReq)
-	test.Equal(t, http.StatusCreated, regRes.StatusCode)
-	t.Cleanup(func() { _ = regRes.Body.Close() })
-
-	// Get token from registration response
-	var regResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(regRes.Body).Decode(&regResponse))
-	token := regResponse.Token
+	})
+	test.Nil(t, err)
 
 	// Test: Update user profile with bio and image
-	updateReq := UserPutRequestBody{
+	putResponse, err := c.UpdateUser(t.Context(), client.UpdateUser{
 		Email: email,
 		Bio:   "I like to skateboard",
 		Image: "https://i.stack.imgur.com/xHWG8.jpg",
-	}
-	putRes := httpPutUser(t, token, &updateReq)
-	test.Equal(t, http.StatusOK, putRes.StatusCode)
-	t.Cleanup(func() { _ = putRes.Body.Close() })
+	})
+	test.Nil(t, err)
 
 	// Verify response contains updated user data
-	var putResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(putRes.Body).Decode(&putResponse))
 	test.Equal(t, email, putResponse.Email)
 	test.Equal(t, username, putResponse.Username)
 	test.Equal(t, "I like to skateboard", putResponse.Bio)
@@ -398,50 +320,36 @@ func TestPutUser_Success(t *testing.T) {
 	test.NotEqual(t, "", putResponse.Token)
 }
 
-func httpPutUser(t *testing.T, token string, updateReq *UserPutRequestBody) *http.Response {
-	t.Helper()
-
-	body, err := json.Marshal(UserWrapper[UserPutRequestBody]{User: *updateReq})
-	test.Nil(t, err)
-
-	req, err := http.NewRequest(http.MethodPut, endpoint+"/api/user", bytes.NewBuffer(body))
-	test.Nil(t, err)
+func TestPutUser_Validation(t *testing.T) {
+	t.Parallel()
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Token "+token)
+	// Setup: Create user via registration
+	c, _ := registerUser(t, "put_validation_user")
 
-	res, err := http.DefaultClient.Do(req)
-	test.Nil(t, err)
+	testcases := map[string]client.UpdateUser{
+		"email invalid":      {Email: "not-an-email"},
+		"password too short": {Password: "short"},
+		"image not a url":    {Image: "not a url"},
+	}
 
-	return res
-}
+	for name, tc := range testcases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
 
-type UserPutRequestBody struct {
-	Email    string `json:"email,omitempty"`
-	Username string `json:"username,omitempty"`
-	Password string `json:"password,omitempty"`
-	Bio      string `json:"bio,omitempty"`
-	Image    string `json:"image,omitempty"`
+			_, err := c.UpdateUser(t.Context(), tc)
+			test.Equal(t, http.StatusUnprocessableEntity, client.StatusCode(err))
+		})
+	}
 }
 
 func TestPutUser_Unauthorized(t *testing.T) {
 	t.Parallel()
 
 	// Test without token
-	updateReq := UserPutRequestBody{
+	_, err := newClient().UpdateUser(t.Context(), client.UpdateUser{
 		Bio: "I like to skateboard",
-	}
-	body, err := json.Marshal(UserWrapper[UserPutRequestBody]{User: updateReq})
-	test.Nil(t, err)
-
-	req, err := http.NewRequest(http.MethodPut, endpoint+"/api/user", bytes.NewBuffer(body))
-	test.Nil(t, err)
-	req.Header.Set("Content-Type", "application/json")
-
-	res, err := http.DefaultClient.Do(req)
-	test.Nil(t, err)
-	test.Equal(t, http.StatusUnauthorized, res.StatusCode)
-	t.Cleanup(func() { _ = res.Body.Close() })
+	})
+	test.Equal(t, http.StatusUnauthorized, client.StatusCode(err))
 }
 
 func TestPutUser_PartialUpdate(t *testing.T) {
@@ -453,31 +361,21 @@ func TestPutUser_PartialUpdate(t *testing.T) {
 	password := "testpass123"
 	username := "partial_user_" + unique
 
-	regReq := UserPostRequestBody{
+	c := newClient()
+	_, err := c.Register(t.Context(), client.NewUser{
 		Username: username,
 		Email:    email,
 		Password: password,
-	}
-	regRes := httpPostUsers(t, regReq)
-	test.Equal(t, http.StatusCreated, regRes.StatusCode)
-	t.Cleanup(func() { _ = regRes.Body.Close() })
-
-	// Get token from registration response
-	var regResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(regRes.Body).Decode(&regResponse))
-	token := regResponse.Token
+	})
+	test.Nil(t, err)
 
 	// Test: Update only bio (partial update)
-	updateReq := UserPutRequestBody{
+	putResponse, err := c.UpdateUser(t.Context(), client.UpdateUser{
 		Bio: "Only updating bio field",
-	}
-	putRes := httpPutUser(t, token, &updateReq)
-	test.Equal(t, http.StatusOK, putRes.StatusCode)
-	t.Cleanup(func() { _ = putRes.Body.Close() })
+	})
+	test.Nil(t, err)
 
 	// Verify response - email and username should remain unchanged
-	var putResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(putRes.Body).Decode(&putResponse))
 	test.Equal(t, email, putResponse.Email)
 	test.Equal(t, username, putResponse.Username)
 	test.Equal(t, "Only updating bio field", putResponse.Bio)
@@ -493,29 +391,19 @@ func TestPutUser_EmptyBody(t *testing.T) {
 	password := "testpass123"
 	username := "empty_user_" + unique
 
-	regReq := UserPostRequestBody{
+	c := newClient()
+	_, err := c.Register(t.Context(), client.NewUser{
 		Username: username,
 		Email:    email,
 		Password: password,
-	}
-	regRes := httpPostUsers(t, regReq)
-	test.Equal(t, http.StatusCreated, regRes.StatusCode)
-	t.Cleanup(func() { _ = regRes.Body.Close() })
-
-	// Get token from registration response
-	var regResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(regRes.Body).Decode(&regResponse))
-	token := regResponse.Token
+	})
+	test.Nil(t, err)
 
 	// Test: Update with empty body (no changes)
-	updateReq := UserPutRequestBody{}
-	putRes := httpPutUser(t, token, &updateReq)
-	test.Equal(t, http.StatusOK, putRes.StatusCode)
-	t.Cleanup(func() { _ = putRes.Body.Close() })
+	putResponse, err := c.UpdateUser(t.Context(), client.UpdateUser{})
+	test.Nil(t, err)
 
 	// Verify response - all fields should remain unchanged
-	var putResponse UserResponseBody
-	test.Nil(t, json.NewDecoder(putRes.Body).Decode(&putResponse))
 	test.Equal(t, email, putResponse.Email)
 	test.Equal(t, username, putResponse.Username)
 	test.Equal(t, "", putResponse.Bio)